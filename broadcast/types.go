@@ -0,0 +1,60 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package broadcast contains the types shared by the
+// indexer and the servicers to track the transactions
+// submitted through the Construction API.
+package broadcast
+
+import (
+	"errors"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// Pending transactions have been submitted and are
+	// rebroadcast while they are missing from the mempool.
+	Pending = "pending"
+
+	// Confirmed transactions are included in a block.
+	Confirmed = "confirmed"
+
+	// Conflicted transactions had an input spent by
+	// another transaction included in a block, so they
+	// can never be confirmed.
+	Conflicted = "conflicted"
+)
+
+// ErrNotFound is returned when a transaction
+// was not submitted through the Construction API.
+var ErrNotFound = errors.New("transaction not tracked")
+
+// Transaction is a transaction submitted through
+// /construction/submit and its current status.
+//
+// BlockIdentifier is the block that confirmed the
+// transaction or, if it is conflicted, the block
+// that contains ConflictingTransaction. Error is the
+// last rejection of a pending transaction by zend.
+type Transaction struct {
+	TransactionIdentifier  *types.TransactionIdentifier `json:"transaction_identifier"`
+	NetworkTransaction     string                       `json:"network_transaction"`
+	Inputs                 []string                     `json:"inputs"`
+	Status                 string                       `json:"status"`
+	Broadcasts             int64                        `json:"broadcasts"`
+	BlockIdentifier        *types.BlockIdentifier       `json:"block_identifier,omitempty"`
+	ConflictingTransaction *types.TransactionIdentifier `json:"conflicting_transaction,omitempty"`
+	Error                  string                       `json:"error,omitempty"`
+}
@@ -15,8 +15,13 @@
 package configuration
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"strconv"
@@ -75,7 +80,8 @@ const (
 
 	// min prune depth is 288:
 	// https://github.com/bitcoin/bitcoin/blob/ad2952d17a2af419a04256b10b53c7377f826a27/src/validation.h#L84
-	pruneDepth = int64(10000) //nolint
+	pruneDepth    = int64(10000) //nolint
+	minPruneDepth = int64(288)   //nolint
 
 	// min prune height (on mainnet):
 	// https://github.com/bitcoin/bitcoin/blob/62d137ac3b701aae36c1aa3aa93a83fd6357fde6/src/chainparams.cpp#L102
@@ -88,6 +94,30 @@ const (
 	// persistent data.
 	DataDirectory = "/data"
 
+	// readTimeout is the maximum duration for reading the entire
+	// request, including the body.
+	readTimeout = 5 * time.Second
+
+	// writeTimeout is the maximum duration before timing out
+	// writes of the response. It is reset whenever a new
+	// request's header is read.
+	writeTimeout = 15 * time.Second
+
+	// idleTimeout is the maximum amount of time to wait for the
+	// next request when keep-alives are enabled.
+	idleTimeout = 30 * time.Second
+
+	// inlineFetchLimit is the maximum number
+	// of transactions to fetch inline.
+	inlineFetchLimit = 100
+
+	// maxPort is the largest TCP port.
+	maxPort = 65535
+
+	// redacted replaces secrets when printing
+	// the configuration.
+	redacted = "[REDACTED]"
+
 	zendPath = ".zen"
 	indexerPath  = "indexer"
 
@@ -107,6 +137,135 @@ const (
 	// read to determine the port for the Rosetta
 	// implementation.
 	PortEnv = "PORT"
+
+	// ZendRPCURLEnv is the environment variable
+	// read to determine the URL of an external zend
+	// RPC server. When populated, we do not start
+	// the embedded zend.
+	ZendRPCURLEnv = "ZEND_RPC_URL"
+
+	// ZendRPCUserEnv is the environment variable
+	// read to determine the username used to
+	// authenticate with an external zend.
+	ZendRPCUserEnv = "ZEND_RPC_USER"
+
+	// ZendRPCPasswordEnv is the environment variable
+	// read to determine the password used to
+	// authenticate with an external zend.
+	ZendRPCPasswordEnv = "ZEND_RPC_PASSWORD" // #nosec G101
+
+	// ZendRPCCookieFileEnv is the environment variable
+	// read to determine the cookie file used to
+	// authenticate with an external zend.
+	ZendRPCCookieFileEnv = "ZEND_RPC_COOKIE_FILE"
+
+	// ZendRPCTLSCAFileEnv is the environment variable
+	// read to determine the CA certificate used to
+	// verify an external zend.
+	ZendRPCTLSCAFileEnv = "ZEND_RPC_TLS_CA_FILE"
+
+	// ZendRPCTLSCertFileEnv is the environment variable
+	// read to determine the client certificate presented
+	// to an external zend.
+	ZendRPCTLSCertFileEnv = "ZEND_RPC_TLS_CERT_FILE"
+
+	// ZendRPCTLSKeyFileEnv is the environment variable
+	// read to determine the key of the client certificate
+	// presented to an external zend.
+	ZendRPCTLSKeyFileEnv = "ZEND_RPC_TLS_KEY_FILE"
+
+	// MaxSyncLagEnv is the environment variable read
+	// to determine how many blocks the indexer can be
+	// behind zend's headers while still being ready.
+	MaxSyncLagEnv = "MAX_SYNC_LAG"
+
+	// defaultMaxSyncLag is the lag used when
+	// MAX_SYNC_LAG is not populated.
+	defaultMaxSyncLag = int64(3)
+
+	// ZendRPCTLSSkipVerifyEnv is the environment variable
+	// read to determine if we should skip verifying the
+	// certificate of an external zend.
+	ZendRPCTLSSkipVerifyEnv = "ZEND_RPC_TLS_SKIP_VERIFY"
+
+	// ConfigFileEnv is the environment variable read
+	// to determine the path of the optional configuration
+	// file (.json, .yaml or .yml). Environment variables
+	// override the settings in the file.
+	ConfigFileEnv = "CONFIG_FILE"
+
+	// DataDirectoryEnv is the environment variable read
+	// to determine the location of all persistent data.
+	DataDirectoryEnv = "DATA_DIRECTORY"
+
+	// ZendConfigPathEnv is the environment variable read
+	// to determine the configuration file of the embedded
+	// zend.
+	ZendConfigPathEnv = "ZEND_CONFIG_PATH"
+
+	// ZendRPCPortEnv is the environment variable read
+	// to determine the RPC port of the embedded zend.
+	ZendRPCPortEnv = "ZEND_RPC_PORT"
+
+	// PruneDepthEnv is the environment variable read
+	// to determine how many blocks are kept when pruning.
+	PruneDepthEnv = "PRUNE_DEPTH"
+
+	// PruneMinHeightEnv is the environment variable read
+	// to determine the height below which we never prune.
+	PruneMinHeightEnv = "PRUNE_MIN_HEIGHT"
+
+	// PruneFrequencyEnv is the environment variable read
+	// to determine how often we attempt to prune.
+	PruneFrequencyEnv = "PRUNE_FREQUENCY"
+
+	// TransactionDictionaryEnv is the environment variable
+	// read to determine the Zstandard dictionary used to
+	// compress transactions.
+	TransactionDictionaryEnv = "TRANSACTION_DICTIONARY"
+
+	// HTTPReadTimeoutEnv is the environment variable read
+	// to determine the maximum duration for reading a request.
+	HTTPReadTimeoutEnv = "HTTP_READ_TIMEOUT"
+
+	// HTTPWriteTimeoutEnv is the environment variable read
+	// to determine the maximum duration for writing a response.
+	HTTPWriteTimeoutEnv = "HTTP_WRITE_TIMEOUT"
+
+	// HTTPIdleTimeoutEnv is the environment variable read
+	// to determine how long idle keep-alive connections
+	// are kept open.
+	HTTPIdleTimeoutEnv = "HTTP_IDLE_TIMEOUT"
+
+	// InlineFetchLimitEnv is the environment variable read
+	// to determine the maximum number of transactions
+	// returned inline by /block.
+	InlineFetchLimitEnv = "INLINE_FETCH_LIMIT"
+
+	// ZendRPCMaxAttemptsEnv is the environment variable read
+	// to determine how many times an idempotent request to
+	// zend is sent before giving up.
+	ZendRPCMaxAttemptsEnv = "ZEND_RPC_MAX_ATTEMPTS"
+
+	// ZendRPCInitialBackoffEnv is the environment variable
+	// read to determine the delay before the first retry of
+	// a request to zend.
+	ZendRPCInitialBackoffEnv = "ZEND_RPC_INITIAL_BACKOFF"
+
+	// ZendRPCMaxBackoffEnv is the environment variable read
+	// to determine the maximum delay between retries of a
+	// request to zend.
+	ZendRPCMaxBackoffEnv = "ZEND_RPC_MAX_BACKOFF"
+
+	// ZendRPCBreakerThresholdEnv is the environment variable
+	// read to determine how many consecutive requests must
+	// find zend unavailable to open the circuit breaker.
+	ZendRPCBreakerThresholdEnv = "ZEND_RPC_BREAKER_THRESHOLD"
+
+	// ZendRPCBreakerCooldownEnv is the environment variable
+	// read to determine how long the circuit breaker stays
+	// open before zend is probed again.
+	ZendRPCBreakerCooldownEnv = "ZEND_RPC_BREAKER_COOLDOWN"
 )
 
 // PruningConfiguration is the configuration to
@@ -117,6 +276,33 @@ type PruningConfiguration struct {
 	MinHeight int64
 }
 
+// HTTPConfiguration is the configuration of
+// the Rosetta API server.
+type HTTPConfiguration struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+// TLSConfiguration is the configuration to use
+// when connecting to an external zend over TLS.
+type TLSConfiguration struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// ExternalZendConfiguration is the configuration to
+// use when connecting to a zend we do not manage.
+type ExternalZendConfiguration struct {
+	URL        string
+	Username   string
+	Password   string `json:"-"`
+	CookieFile string
+	TLS        *TLSConfiguration
+}
+
 // Configuration determines how
 type Configuration struct {
 	Mode                   Mode
@@ -131,19 +317,73 @@ type Configuration struct {
 	IndexerPath            string
 	ZendPath               string
 	Compressors            []*storage.CompressorEntry
+
+	// MaxSyncLag is the number of blocks the indexer
+	// can be behind zend's headers while it is
+	// considered synced.
+	MaxSyncLag int64
+
+	// ConfigFile is the configuration file the
+	// settings were loaded from (if any).
+	ConfigFile string
+
+	HTTP *HTTPConfiguration
+
+	// RetryPolicy and CircuitBreaker determine how
+	// requests to zend are retried while it is
+	// unavailable.
+	RetryPolicy    *zen.RetryPolicy
+	CircuitBreaker *zen.CircuitBreakerPolicy
+
+	// InlineFetchLimit is the maximum number of
+	// transactions returned inline by /block. Larger
+	// blocks list them in other_transactions.
+	InlineFetchLimit int
+
+	// ExternalZend is only populated when we connect
+	// to an external zend instead of starting
+	// the embedded one.
+	ExternalZend *ExternalZendConfiguration
 }
 
 // LoadConfiguration attempts to create a new Configuration
-// using the ENVs in the environment.
+// using the ENVs in the environment and the optional
+// configuration file at CONFIG_FILE. baseDirectory is
+// used unless DATA_DIRECTORY is populated.
 func LoadConfiguration(baseDirectory string) (*Configuration, error) {
-	config := &Configuration{}
-	config.Pruning = &PruningConfiguration{
-		Frequency: pruneFrequency,
-		Depth:     pruneDepth,
-		MinHeight: minPruneHeight,
+	s, err := loadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Configuration{
+		ConfigFile: os.Getenv(ConfigFileEnv),
+	}
+	config.Pruning, err = loadPruningConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+
+	config.HTTP, err = loadHTTPConfiguration(s)
+	if err != nil {
+		return nil, err
 	}
 
-	modeValue := Mode(os.Getenv(ModeEnv))
+	config.RetryPolicy, err = loadRetryPolicy(s)
+	if err != nil {
+		return nil, err
+	}
+
+	config.CircuitBreaker, err = loadCircuitBreakerPolicy(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if dataDirectory := s.get(DataDirectoryEnv); len(dataDirectory) > 0 {
+		baseDirectory = dataDirectory
+	}
+
+	modeValue := Mode(s.get(ModeEnv))
 	switch modeValue {
 	case Online:
 		config.Mode = Online
@@ -152,9 +392,19 @@ func LoadConfiguration(baseDirectory string) (*Configuration, error) {
 			return nil, fmt.Errorf("%w: unable to create indexer path", err)
 		}
 
-		config.ZendPath = path.Join(baseDirectory, zendPath)
-		if err := ensurePathExists(config.ZendPath); err != nil {
-			return nil, fmt.Errorf("%w: unable to create zen data directory path", err)
+		externalZend, err := loadExternalZendConfiguration(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load external zend configuration", err)
+		}
+		config.ExternalZend = externalZend
+
+		// We don't store any zend data when
+		// connecting to an external node.
+		if config.ExternalZend == nil {
+			config.ZendPath = path.Join(baseDirectory, zendPath)
+			if err := ensurePathExists(config.ZendPath); err != nil {
+				return nil, fmt.Errorf("%w: unable to create zen data directory path", err)
+			}
 		}
 	case Offline:
 		config.Mode = Offline
@@ -164,7 +414,7 @@ func LoadConfiguration(baseDirectory string) (*Configuration, error) {
 		return nil, fmt.Errorf("%s is not a valid mode", modeValue)
 	}
 
-	networkValue := os.Getenv(NetworkEnv)
+	networkValue := s.get(NetworkEnv)
 	switch networkValue {
 	case Mainnet:
 		config.Network = &types.NetworkIdentifier{
@@ -220,20 +470,325 @@ func LoadConfiguration(baseDirectory string) (*Configuration, error) {
 		return nil, fmt.Errorf("%s is not a valid network", networkValue)
 	}
 
-	portValue := os.Getenv(PortEnv)
+	if configPath := s.get(ZendConfigPathEnv); len(configPath) > 0 {
+		config.ConfigPath = configPath
+	}
+
+	if dictionary := s.get(TransactionDictionaryEnv); len(dictionary) > 0 {
+		config.Compressors[0].DictionaryPath = dictionary
+	}
+
+	rpcPort, err := s.getInt64(ZendRPCPortEnv, int64(config.RPCPort), 1)
+	if err != nil {
+		return nil, err
+	}
+	if rpcPort > maxPort {
+		return nil, fmt.Errorf("unable to parse %s %d: must be at most %d", ZendRPCPortEnv, rpcPort, maxPort)
+	}
+	config.RPCPort = int(rpcPort)
+
+	portValue := s.get(PortEnv)
 	if len(portValue) == 0 {
 		return nil, errors.New("PORT must be populated")
 	}
 
 	port, err := strconv.Atoi(portValue)
-	if err != nil || len(portValue) == 0 || port <= 0 {
+	if err != nil || len(portValue) == 0 || port <= 0 || port > maxPort {
 		return nil, fmt.Errorf("%w: unable to parse port %s", err, portValue)
 	}
 	config.Port = port
 
+	config.MaxSyncLag, err = s.getInt64(MaxSyncLagEnv, defaultMaxSyncLag, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := s.getInt64(InlineFetchLimitEnv, inlineFetchLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	config.InlineFetchLimit = int(limit)
+
+	return config, nil
+}
+
+// loadPruningConfiguration loads the pruning
+// configuration from the settings.
+func loadPruningConfiguration(s settings) (*PruningConfiguration, error) {
+	frequency, err := s.getDuration(PruneFrequencyEnv, pruneFrequency)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := s.getInt64(PruneDepthEnv, pruneDepth, minPruneDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	minHeight, err := s.getInt64(PruneMinHeightEnv, minPruneHeight, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PruningConfiguration{
+		Frequency: frequency,
+		Depth:     depth,
+		MinHeight: minHeight,
+	}, nil
+}
+
+// loadHTTPConfiguration loads the configuration of
+// the Rosetta API server from the settings.
+func loadHTTPConfiguration(s settings) (*HTTPConfiguration, error) {
+	read, err := s.getDuration(HTTPReadTimeoutEnv, readTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	write, err := s.getDuration(HTTPWriteTimeoutEnv, writeTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	idle, err := s.getDuration(HTTPIdleTimeoutEnv, idleTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPConfiguration{
+		ReadTimeout:  read,
+		WriteTimeout: write,
+		IdleTimeout:  idle,
+	}, nil
+}
+
+// loadRetryPolicy loads the policy used to retry
+// requests to zend from the settings. Unset values
+// default to zen.DefaultRetryPolicy.
+func loadRetryPolicy(s settings) (*zen.RetryPolicy, error) {
+	policy := zen.DefaultRetryPolicy
+
+	attempts, err := s.getInt64(ZendRPCMaxAttemptsEnv, int64(policy.MaxAttempts), 1)
+	if err != nil {
+		return nil, err
+	}
+	policy.MaxAttempts = int(attempts)
+
+	policy.InitialBackoff, err = s.getDuration(ZendRPCInitialBackoffEnv, policy.InitialBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.MaxBackoff, err = s.getDuration(ZendRPCMaxBackoffEnv, policy.MaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.MaxBackoff < policy.InitialBackoff {
+		return nil, fmt.Errorf(
+			"%s %s must be at least %s %s",
+			ZendRPCMaxBackoffEnv,
+			policy.MaxBackoff,
+			ZendRPCInitialBackoffEnv,
+			policy.InitialBackoff,
+		)
+	}
+
+	return &policy, nil
+}
+
+// loadCircuitBreakerPolicy loads the policy of the
+// circuit breaker in front of zend from the settings.
+// Unset values default to zen.DefaultCircuitBreakerPolicy.
+func loadCircuitBreakerPolicy(s settings) (*zen.CircuitBreakerPolicy, error) {
+	policy := zen.DefaultCircuitBreakerPolicy
+
+	threshold, err := s.getInt64(
+		ZendRPCBreakerThresholdEnv,
+		int64(policy.FailureThreshold),
+		1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	policy.FailureThreshold = int(threshold)
+
+	policy.Cooldown, err = s.getDuration(ZendRPCBreakerCooldownEnv, policy.Cooldown)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// loadExternalZendConfiguration loads the configuration
+// of an external zend from the settings. If ZEND_RPC_URL
+// is not populated, nil is returned and the embedded zend
+// is used.
+func loadExternalZendConfiguration(s settings) (*ExternalZendConfiguration, error) {
+	rpcURL := s.get(ZendRPCURLEnv)
+	if len(rpcURL) == 0 {
+		return nil, nil
+	}
+
+	parsedURL, err := url.Parse(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse %s", err, ZendRPCURLEnv)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("%s must use http or https", ZendRPCURLEnv)
+	}
+
+	config := &ExternalZendConfiguration{
+		URL:        rpcURL,
+		Username:   s.get(ZendRPCUserEnv),
+		Password:   s.get(ZendRPCPasswordEnv),
+		CookieFile: s.get(ZendRPCCookieFileEnv),
+	}
+
+	hasCredentials := len(config.Username) > 0 || len(config.Password) > 0
+	switch {
+	case hasCredentials && len(config.CookieFile) > 0:
+		return nil, fmt.Errorf(
+			"only one of %s/%s and %s can be populated",
+			ZendRPCUserEnv,
+			ZendRPCPasswordEnv,
+			ZendRPCCookieFileEnv,
+		)
+	case hasCredentials && (len(config.Username) == 0 || len(config.Password) == 0):
+		return nil, fmt.Errorf(
+			"%s and %s must be populated together",
+			ZendRPCUserEnv,
+			ZendRPCPasswordEnv,
+		)
+	case !hasCredentials && len(config.CookieFile) == 0:
+		return nil, fmt.Errorf(
+			"%s/%s or %s must be populated",
+			ZendRPCUserEnv,
+			ZendRPCPasswordEnv,
+			ZendRPCCookieFileEnv,
+		)
+	}
+
+	tlsConfig, err := loadTLSConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("%s must use https when TLS is configured", ZendRPCURLEnv)
+	}
+	config.TLS = tlsConfig
+
+	// Ensure the TLS files can be loaded before
+	// starting any dependency.
+	if _, err := config.TLSConfig(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// loadTLSConfiguration loads the TLS configuration
+// of an external zend from the settings. If no
+// TLS setting is populated, nil is returned.
+func loadTLSConfiguration(s settings) (*TLSConfiguration, error) {
+	config := &TLSConfiguration{
+		CAFile:   s.get(ZendRPCTLSCAFileEnv),
+		CertFile: s.get(ZendRPCTLSCertFileEnv),
+		KeyFile:  s.get(ZendRPCTLSKeyFileEnv),
+	}
+
+	skipVerifyValue := s.get(ZendRPCTLSSkipVerifyEnv)
+	if len(skipVerifyValue) > 0 {
+		skipVerify, err := strconv.ParseBool(skipVerifyValue)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse %s %s",
+				err,
+				ZendRPCTLSSkipVerifyEnv,
+				skipVerifyValue,
+			)
+		}
+		config.InsecureSkipVerify = skipVerify
+	}
+
+	if (len(config.CertFile) == 0) != (len(config.KeyFile) == 0) {
+		return nil, fmt.Errorf(
+			"%s and %s must be populated together",
+			ZendRPCTLSCertFileEnv,
+			ZendRPCTLSKeyFileEnv,
+		)
+	}
+
+	if len(config.CAFile) == 0 && len(config.CertFile) == 0 && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+
 	return config, nil
 }
 
+// MarshalJSON redacts the password and any credentials
+// in the URL so the configuration can be logged.
+func (c ExternalZendConfiguration) MarshalJSON() ([]byte, error) {
+	type external ExternalZendConfiguration
+	printed := struct {
+		external
+		Password string `json:"Password,omitempty"`
+	}{
+		external: external(c),
+	}
+
+	if len(c.Password) > 0 {
+		printed.Password = redacted
+	}
+
+	if parsedURL, err := url.Parse(c.URL); err == nil {
+		printed.URL = parsedURL.Redacted()
+	}
+
+	return json.Marshal(printed)
+}
+
+// TLSConfig returns the *tls.Config to use when
+// connecting to the external zend. If TLS is not
+// configured, nil is returned and the system defaults
+// are used.
+func (c *ExternalZendConfiguration) TLSConfig() (*tls.Config, error) {
+	if c.TLS == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify, // #nosec G402
+	}
+
+	if len(c.TLS.CAFile) > 0 {
+		caCert, err := ioutil.ReadFile(c.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read CA file %s", err, c.TLS.CAFile)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(c.TLS.CertFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load client certificate", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 // ensurePathsExist directories along
 // a path if they do not exist.
 func ensurePathExists(path string) error {
@@ -0,0 +1,180 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// settingEnvs are the environment variables that can
+// also be set in the configuration file. In the file,
+// each setting is keyed by the lowercase name of its
+// environment variable (for example, "zend_rpc_url").
+var settingEnvs = []string{
+	ModeEnv,
+	NetworkEnv,
+	PortEnv,
+	DataDirectoryEnv,
+	ZendConfigPathEnv,
+	ZendRPCPortEnv,
+	ZendRPCURLEnv,
+	ZendRPCUserEnv,
+	ZendRPCPasswordEnv,
+	ZendRPCCookieFileEnv,
+	ZendRPCTLSCAFileEnv,
+	ZendRPCTLSCertFileEnv,
+	ZendRPCTLSKeyFileEnv,
+	ZendRPCTLSSkipVerifyEnv,
+	MaxSyncLagEnv,
+	PruneDepthEnv,
+	PruneMinHeightEnv,
+	PruneFrequencyEnv,
+	TransactionDictionaryEnv,
+	HTTPReadTimeoutEnv,
+	HTTPWriteTimeoutEnv,
+	HTTPIdleTimeoutEnv,
+	InlineFetchLimitEnv,
+	ZendRPCMaxAttemptsEnv,
+	ZendRPCInitialBackoffEnv,
+	ZendRPCMaxBackoffEnv,
+	ZendRPCBreakerThresholdEnv,
+	ZendRPCBreakerCooldownEnv,
+}
+
+// settings are the values loaded from the configuration
+// file, keyed by their environment variable.
+type settings map[string]string
+
+// loadSettings loads the configuration file at CONFIG_FILE.
+// If CONFIG_FILE is not populated, only the environment
+// is used.
+func loadSettings() (settings, error) {
+	configFile := os.Getenv(ConfigFileEnv)
+	if len(configFile) == 0 {
+		return settings{}, nil
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Clean(configFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read configuration file %s", err, configFile)
+	}
+
+	values := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(configFile)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contents, &values)
+	default:
+		return nil, fmt.Errorf(
+			"configuration file %s must be .json, .yaml or .yml",
+			configFile,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse configuration file %s", err, configFile)
+	}
+
+	known := map[string]bool{}
+	for _, env := range settingEnvs {
+		known[env] = true
+	}
+
+	s := settings{}
+	for key, value := range values {
+		env := strings.ToUpper(key)
+		if !known[env] {
+			return nil, fmt.Errorf("unknown setting %s in configuration file %s", key, configFile)
+		}
+
+		switch v := value.(type) {
+		case nil:
+		case string, bool, int, float64, json.Number:
+			s[env] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf(
+				"setting %s in configuration file %s must be a string, number or boolean",
+				key,
+				configFile,
+			)
+		}
+	}
+
+	return s, nil
+}
+
+// get returns the value of a setting. Environment
+// variables override the configuration file.
+func (s settings) get(env string) string {
+	if value := os.Getenv(env); len(value) > 0 {
+		return value
+	}
+
+	return s[env]
+}
+
+// getInt64 returns the value of an integer setting
+// that must be at least min, or defaultValue if it
+// is not populated.
+func (s settings) getInt64(env string, defaultValue int64, min int64) (int64, error) {
+	value := s.get(env)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to parse %s %s", err, env, value)
+	}
+
+	if parsed < min {
+		return 0, fmt.Errorf("unable to parse %s %s: must be at least %d", env, value, min)
+	}
+
+	return parsed, nil
+}
+
+// getDuration returns the value of a positive duration
+// setting (for example, "30s"), or defaultValue if it
+// is not populated.
+func (s settings) getDuration(env string, defaultValue time.Duration) (time.Duration, error) {
+	value := s.get(env)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to parse %s %s", err, env, value)
+	}
+
+	if parsed <= 0 {
+		return 0, fmt.Errorf("unable to parse %s %s: must be positive", env, value)
+	}
+
+	return parsed, nil
+}
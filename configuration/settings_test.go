@@ -0,0 +1,198 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfiguration_File(t *testing.T) {
+	tests := map[string]struct {
+		file     string
+		contents string
+		envs     map[string]string
+
+		cfg func(dataDirectory string) *Configuration
+		err error
+	}{
+		"yaml": {
+			file: "rosetta.yaml",
+			contents: `
+mode: ONLINE
+network: TESTNET
+port: 8080
+data_directory: {{DATA}}
+zend_rpc_port: 18232
+prune_frequency: 10m
+http_read_timeout: 1s
+inline_fetch_limit: 10
+`,
+			cfg: func(dataDirectory string) *Configuration {
+				return &Configuration{
+					Mode:             Online,
+					Port:             8080,
+					RPCPort:          18232,
+					IndexerPath:      path.Join(dataDirectory, indexerPath),
+					ZendPath:         path.Join(dataDirectory, zendPath),
+					InlineFetchLimit: 10,
+					Pruning: &PruningConfiguration{
+						Frequency: 10 * time.Minute,
+						Depth:     pruneDepth,
+						MinHeight: minPruneHeight,
+					},
+					HTTP: &HTTPConfiguration{
+						ReadTimeout:  time.Second,
+						WriteTimeout: writeTimeout,
+						IdleTimeout:  idleTimeout,
+					},
+				}
+			},
+		},
+		"json with env overrides": {
+			file: "rosetta.json",
+			contents: `{
+  "mode": "ONLINE",
+  "network": "TESTNET",
+  "port": 8080,
+  "data_directory": "{{DATA}}",
+  "zend_rpc_url": "https://zend:18231",
+  "zend_rpc_user": "user",
+  "zend_rpc_password": "password",
+  "zend_rpc_tls_skip_verify": true,
+  "prune_min_height": 1000000
+}`,
+			envs: map[string]string{
+				PortEnv:            "9090",
+				ZendRPCUserEnv:     "other",
+				HTTPIdleTimeoutEnv: "1m",
+			},
+			cfg: func(dataDirectory string) *Configuration {
+				return &Configuration{
+					Mode:             Online,
+					Port:             9090,
+					RPCPort:          testnetRPCPort,
+					IndexerPath:      path.Join(dataDirectory, indexerPath),
+					InlineFetchLimit: inlineFetchLimit,
+					Pruning: &PruningConfiguration{
+						Frequency: pruneFrequency,
+						Depth:     pruneDepth,
+						MinHeight: 1000000,
+					},
+					HTTP: &HTTPConfiguration{
+						ReadTimeout:  readTimeout,
+						WriteTimeout: writeTimeout,
+						IdleTimeout:  time.Minute,
+					},
+					ExternalZend: &ExternalZendConfiguration{
+						URL:      "https://zend:18231",
+						Username: "other",
+						Password: "password",
+						TLS: &TLSConfiguration{
+							InsecureSkipVerify: true,
+						},
+					},
+				}
+			},
+		},
+		"invalid setting in file": {
+			file:     "rosetta.yml",
+			contents: "mode: ONLINE\nnetwork: TESTNET\nport: 8080\nprune_depth: 10\n",
+			err:      errors.New("unable to parse PRUNE_DEPTH 10: must be at least 288"),
+		},
+		"unknown setting": {
+			file:     "rosetta.yaml",
+			contents: "mode: ONLINE\nprot: 8080\n",
+			err:      errors.New("unknown setting prot in configuration file"),
+		},
+		"nested setting": {
+			file:     "rosetta.yaml",
+			contents: "mode:\n  online: true\n",
+			err:      errors.New("setting mode in configuration file"),
+		},
+		"invalid yaml": {
+			file:     "rosetta.yaml",
+			contents: "mode: [ONLINE\n",
+			err:      errors.New("unable to parse configuration file"),
+		},
+		"invalid json": {
+			file:     "rosetta.json",
+			contents: "{\"mode\": ",
+			err:      errors.New("unable to parse configuration file"),
+		},
+		"unsupported extension": {
+			file:     "rosetta.toml",
+			contents: "mode = \"ONLINE\"\n",
+			err:      errors.New("must be .json, .yaml or .yml"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			newDir, err := utils.CreateTempDir()
+			assert.NoError(t, err)
+			defer utils.RemoveTempDir(newDir)
+
+			dataDirectory := path.Join(newDir, "data")
+			configFile := path.Join(newDir, test.file)
+			contents := strings.ReplaceAll(test.contents, "{{DATA}}", dataDirectory)
+			assert.NoError(t, ioutil.WriteFile(configFile, []byte(contents), 0600))
+
+			for _, env := range settingEnvs {
+				os.Setenv(env, test.envs[env])
+			}
+			os.Setenv(ConfigFileEnv, configFile)
+			defer os.Setenv(ConfigFileEnv, "")
+
+			cfg, err := LoadConfiguration(newDir)
+			if test.err != nil {
+				assert.Nil(t, cfg)
+				assert.Contains(t, err.Error(), test.err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			expected := test.cfg(dataDirectory)
+			assert.Equal(t, configFile, cfg.ConfigFile)
+			assert.Equal(t, expected.Mode, cfg.Mode)
+			assert.Equal(t, expected.Port, cfg.Port)
+			assert.Equal(t, expected.RPCPort, cfg.RPCPort)
+			assert.Equal(t, expected.IndexerPath, cfg.IndexerPath)
+			assert.Equal(t, expected.ZendPath, cfg.ZendPath)
+			assert.Equal(t, expected.InlineFetchLimit, cfg.InlineFetchLimit)
+			assert.Equal(t, expected.Pruning, cfg.Pruning)
+			assert.Equal(t, expected.HTTP, cfg.HTTP)
+			assert.Equal(t, expected.ExternalZend, cfg.ExternalZend)
+			assert.DirExists(t, expected.IndexerPath)
+		})
+	}
+}
+
+func TestLoadConfiguration_MissingFile(t *testing.T) {
+	os.Setenv(ConfigFileEnv, "/missing/rosetta.yaml")
+	defer os.Setenv(ConfigFileEnv, "")
+
+	cfg, err := LoadConfiguration("/data")
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), "unable to read configuration file /missing/rosetta.yaml")
+}
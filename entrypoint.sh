@@ -7,7 +7,8 @@ if [ "$(stat -c '%u:%g' /data)" != "65534:65534" ]; then
   exit 1
 fi
 
-if [ "${MODE:-x}" = "ONLINE" ]; then
+# zk-SNARK params are only needed by the embedded zend
+if [ "${MODE:-x}" = "ONLINE" ] && [ -z "${ZEND_RPC_URL:-}" ]; then
   /app/fetch-params.sh
 fi
 
@@ -0,0 +1,445 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
+
+	"github.com/coinbase/rosetta-sdk-go/storage"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// broadcastNamespace is prepended to the key
+	// of each tracked transaction.
+	broadcastNamespace = "broadcast"
+
+	// broadcastInputNamespace is prepended to the key
+	// of each coin spent by a tracked transaction. Its
+	// value is the hash of the tracked transaction.
+	broadcastInputNamespace = "broadcast-input"
+)
+
+var _ storage.BlockWorker = (*BroadcastStorage)(nil)
+
+// BroadcastStorage implements storage.BlockWorker
+// to track the status of the transactions submitted
+// through the Construction API.
+type BroadcastStorage struct {
+	db storage.Database
+}
+
+// NewBroadcastStorage returns a new *BroadcastStorage.
+func NewBroadcastStorage(db storage.Database) *BroadcastStorage {
+	return &BroadcastStorage{
+		db: db,
+	}
+}
+
+func getBroadcastKey(hash string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", broadcastNamespace, hash))
+}
+
+func getBroadcastInputKey(coinIdentifier string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", broadcastInputNamespace, coinIdentifier))
+}
+
+func (b *BroadcastStorage) get(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	hash string,
+) (*broadcast.Transaction, error) {
+	exists, value, err := dbTx.Get(ctx, getBroadcastKey(hash))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get broadcast %s", err, hash)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", broadcast.ErrNotFound, hash)
+	}
+
+	var transaction broadcast.Transaction
+	if err := b.db.Encoder().Decode(broadcastNamespace, value, &transaction, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode broadcast %s", err, hash)
+	}
+
+	return &transaction, nil
+}
+
+func (b *BroadcastStorage) set(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	transaction *broadcast.Transaction,
+) error {
+	encoded, err := b.db.Encoder().Encode(broadcastNamespace, transaction)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode broadcast", err)
+	}
+
+	hash := transaction.TransactionIdentifier.Hash
+	if err := dbTx.Set(ctx, getBroadcastKey(hash), encoded, true); err != nil {
+		return fmt.Errorf("%w: unable to store broadcast %s", err, hash)
+	}
+
+	return nil
+}
+
+// Track stores a transaction that was submitted to zend
+// as pending. Submitting a tracked transaction again
+// resets its status.
+func (b *BroadcastStorage) Track(
+	ctx context.Context,
+	transaction *broadcast.Transaction,
+) error {
+	dbTx := b.db.NewDatabaseTransaction(ctx, true)
+	defer dbTx.Discard(ctx)
+
+	hash := transaction.TransactionIdentifier.Hash
+	tracked := *transaction
+	tracked.Status = broadcast.Pending
+	tracked.Broadcasts = 1
+	existing, err := b.get(ctx, dbTx, hash)
+	switch {
+	case err == nil:
+		tracked.Broadcasts = existing.Broadcasts + 1
+	case !errors.Is(err, broadcast.ErrNotFound):
+		return err
+	}
+
+	if err := b.set(ctx, dbTx, &tracked); err != nil {
+		return err
+	}
+
+	if err := b.updateInputs(ctx, dbTx, &tracked, false); err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit broadcast %s", err, hash)
+	}
+
+	return nil
+}
+
+// Get returns a tracked transaction.
+func (b *BroadcastStorage) Get(
+	ctx context.Context,
+	hash string,
+) (*broadcast.Transaction, error) {
+	dbTx := b.db.NewDatabaseTransaction(ctx, false)
+	defer dbTx.Discard(ctx)
+
+	return b.get(ctx, dbTx, hash)
+}
+
+// scan returns all tracked transactions
+// with a status.
+func (b *BroadcastStorage) scan(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	status string,
+) ([]*broadcast.Transaction, error) {
+	prefix := []byte(broadcastNamespace + "/")
+	transactions := []*broadcast.Transaction{}
+	_, err := dbTx.Scan(
+		ctx,
+		prefix,
+		prefix,
+		func(k []byte, v []byte) error {
+			var transaction broadcast.Transaction
+			if err := b.db.Encoder().Decode(broadcastNamespace, v, &transaction, false); err != nil {
+				return fmt.Errorf("%w: unable to decode broadcast %s", err, string(k))
+			}
+
+			if transaction.Status == status {
+				transactions = append(transactions, &transaction)
+			}
+
+			return nil
+		},
+		false,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to scan broadcasts", err)
+	}
+
+	return transactions, nil
+}
+
+// Pending returns all tracked transactions
+// that are not yet in a block.
+func (b *BroadcastStorage) Pending(ctx context.Context) ([]*broadcast.Transaction, error) {
+	dbTx := b.db.NewDatabaseTransaction(ctx, false)
+	defer dbTx.Discard(ctx)
+
+	return b.scan(ctx, dbTx, broadcast.Pending)
+}
+
+// Rebroadcast records the result of submitting a pending
+// transaction again. A transaction rejected by zend
+// (broadcastErr is not nil) stays pending: zend also
+// rejects transactions that are already in a block or
+// whose inputs are not yet known (while the indexer lags
+// behind it). It is only conflicted once a block spends
+// one of its inputs.
+func (b *BroadcastStorage) Rebroadcast(
+	ctx context.Context,
+	hash string,
+	broadcastErr error,
+) error {
+	dbTx := b.db.NewDatabaseTransaction(ctx, true)
+	defer dbTx.Discard(ctx)
+
+	transaction, err := b.get(ctx, dbTx, hash)
+	if err != nil {
+		return err
+	}
+
+	// The transaction may have been confirmed
+	// while we were submitting it.
+	if transaction.Status != broadcast.Pending {
+		return nil
+	}
+
+	transaction.Broadcasts++
+	transaction.Error = ""
+	if broadcastErr != nil {
+		transaction.Error = broadcastErr.Error()
+	}
+
+	if err := b.set(ctx, dbTx, transaction); err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit broadcast %s", err, hash)
+	}
+
+	return nil
+}
+
+// updateStatuses confirms the tracked transactions in
+// block and marks the tracked transactions whose inputs
+// are spent by other transactions in block as conflicted.
+// When block is removed, these transactions are pending
+// again.
+func (b *BroadcastStorage) updateStatuses(
+	ctx context.Context,
+	block *types.Block,
+	dbTx storage.DatabaseTransaction,
+	remove bool,
+) error {
+	if remove {
+		return b.revertStatuses(ctx, block, dbTx)
+	}
+
+	for _, transaction := range block.Transactions {
+		hash := transaction.TransactionIdentifier.Hash
+		if err := b.updateStatus(ctx, dbTx, block, hash, nil, false); err != nil {
+			return err
+		}
+
+		for _, op := range transaction.Operations {
+			if op.CoinChange == nil || op.CoinChange.CoinAction != types.CoinSpent {
+				continue
+			}
+
+			exists, spender, err := dbTx.Get(
+				ctx,
+				getBroadcastInputKey(op.CoinChange.CoinIdentifier.Identifier),
+			)
+			if err != nil {
+				return fmt.Errorf("%w: unable to get broadcast input", err)
+			}
+
+			if !exists || string(spender) == hash {
+				continue
+			}
+
+			if err := b.updateStatus(
+				ctx,
+				dbTx,
+				block,
+				string(spender),
+				transaction.TransactionIdentifier,
+				false,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// revertStatuses sets the tracked transactions confirmed
+// or conflicted in a removed block as pending again. The
+// inputs of conflicted transactions are no longer tracked,
+// so they are found by scanning all conflicted transactions
+// (reorgs are rare).
+func (b *BroadcastStorage) revertStatuses(
+	ctx context.Context,
+	block *types.Block,
+	dbTx storage.DatabaseTransaction,
+) error {
+	hashes := []string{}
+	for _, transaction := range block.Transactions {
+		hashes = append(hashes, transaction.TransactionIdentifier.Hash)
+	}
+
+	conflicted, err := b.scan(ctx, dbTx, broadcast.Conflicted)
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range conflicted {
+		hashes = append(hashes, transaction.TransactionIdentifier.Hash)
+	}
+
+	for _, hash := range hashes {
+		if err := b.updateStatus(ctx, dbTx, block, hash, nil, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateInputs stores the keys of the inputs of a pending
+// tracked transaction or, if remove is true, deletes the
+// keys that still refer to it (an input may have been
+// tracked again by another transaction).
+func (b *BroadcastStorage) updateInputs(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	tracked *broadcast.Transaction,
+	remove bool,
+) error {
+	hash := tracked.TransactionIdentifier.Hash
+	for _, coin := range tracked.Inputs {
+		key := getBroadcastInputKey(coin)
+		if !remove {
+			if err := dbTx.Set(ctx, key, []byte(hash), true); err != nil {
+				return fmt.Errorf("%w: unable to store input %s of broadcast %s", err, coin, hash)
+			}
+
+			continue
+		}
+
+		exists, spender, err := dbTx.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("%w: unable to get broadcast input", err)
+		}
+
+		if !exists || string(spender) != hash {
+			continue
+		}
+
+		if err := dbTx.Delete(ctx, key); err != nil {
+			return fmt.Errorf("%w: unable to delete input %s of broadcast %s", err, coin, hash)
+		}
+	}
+
+	return nil
+}
+
+// updateStatus confirms the tracked transaction hash in
+// block or, if conflicting is provided, marks it as
+// conflicted with conflicting. The keys of its inputs
+// are deleted as it can no longer conflict. If remove
+// is true, the status is reverted to pending (and the
+// keys of its inputs are stored again) instead.
+func (b *BroadcastStorage) updateStatus(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	block *types.Block,
+	hash string,
+	conflicting *types.TransactionIdentifier,
+	remove bool,
+) error {
+	tracked, err := b.get(ctx, dbTx, hash)
+	if errors.Is(err, broadcast.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if remove {
+		if tracked.BlockIdentifier == nil ||
+			types.Hash(tracked.BlockIdentifier) != types.Hash(block.BlockIdentifier) {
+			return nil
+		}
+
+		tracked.Status = broadcast.Pending
+		tracked.BlockIdentifier = nil
+		tracked.ConflictingTransaction = nil
+		if err := b.updateInputs(ctx, dbTx, tracked, false); err != nil {
+			return err
+		}
+
+		return b.set(ctx, dbTx, tracked)
+	}
+
+	// A confirmed transaction cannot conflict
+	// with a transaction in a later block.
+	if conflicting != nil && tracked.Status == broadcast.Confirmed {
+		return nil
+	}
+
+	tracked.Status = broadcast.Confirmed
+	if conflicting != nil {
+		tracked.Status = broadcast.Conflicted
+	}
+	tracked.BlockIdentifier = block.BlockIdentifier
+	tracked.ConflictingTransaction = conflicting
+	tracked.Error = ""
+	if err := b.updateInputs(ctx, dbTx, tracked, true); err != nil {
+		return err
+	}
+
+	return b.set(ctx, dbTx, tracked)
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (b *BroadcastStorage) AddingBlock(
+	ctx context.Context,
+	block *types.Block,
+	transaction storage.DatabaseTransaction,
+) (storage.CommitWorker, error) {
+	if err := b.updateStatuses(ctx, block, transaction, false); err != nil {
+		return nil, fmt.Errorf("%w: unable to update broadcasts", err)
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (b *BroadcastStorage) RemovingBlock(
+	ctx context.Context,
+	block *types.Block,
+	transaction storage.DatabaseTransaction,
+) (storage.CommitWorker, error) {
+	if err := b.updateStatuses(ctx, block, transaction, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to revert broadcasts", err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
+
+	"github.com/coinbase/rosetta-sdk-go/storage"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// feeEstimateBlocks is the number of most recently
+	// indexed blocks considered by the local fee estimator.
+	feeEstimateBlocks = 50
+
+	// bytesInKb is the number of bytes in a KB. In Horizen, this is
+	// considered to be 1000.
+	bytesInKb = float64(1000)
+
+	// maxFeePercentile is the percentile of recent fee rates
+	// used for a confirmation target of 1 block.
+	maxFeePercentile = 0.9
+
+	// minFeePercentile is the lowest percentile of recent
+	// fee rates we ever use, regardless of the confirmation
+	// target.
+	minFeePercentile = 0.5
+
+	// feePercentileStep is how much the percentile decreases
+	// for each additional block in the confirmation target.
+	feePercentileStep = 0.1
+)
+
+// EstimateFeeRate estimates the fee rate (in ZEN per kB) needed for a
+// transaction to be included within confTarget blocks, using the fee
+// rates paid by transactions in the last feeEstimateBlocks indexed blocks.
+// If no transaction paying a fee is found, ErrInsufficientFeeData is returned.
+func (i *Indexer) EstimateFeeRate(
+	ctx context.Context,
+	confTarget int64,
+) (float64, error) {
+	rates, err := i.recentFeeRates(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(rates) == 0 {
+		return -1, zen.ErrInsufficientFeeData
+	}
+
+	return rates[feeRateIndex(len(rates), confTarget)], nil
+}
+
+// recentFeeRates returns the sorted fee rates paid in the last
+// feeEstimateBlocks indexed blocks. They are cached until the
+// head block changes.
+func (i *Indexer) recentFeeRates(ctx context.Context) ([]float64, error) {
+	head, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	i.feeRatesMutex.Lock()
+	defer i.feeRatesMutex.Unlock()
+
+	if i.feeRatesTip != nil && types.Hash(i.feeRatesTip) == types.Hash(head) {
+		return i.feeRates, nil
+	}
+
+	rates := []float64{}
+	for index := head.Index; index > head.Index-feeEstimateBlocks && index >= 0; index-- {
+		blockIndex := index
+		block, err := i.blockStorage.GetBlock(
+			ctx,
+			&types.PartialBlockIdentifier{Index: &blockIndex},
+		)
+		if errors.Is(err, storage.ErrBlockNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get block %d", err, blockIndex)
+		}
+
+		for _, transaction := range block.Transactions {
+			rate, ok := transactionFeeRate(transaction)
+			if !ok {
+				continue
+			}
+
+			rates = append(rates, rate)
+		}
+	}
+
+	sort.Float64s(rates)
+
+	i.feeRatesTip = head
+	i.feeRates = rates
+
+	return rates, nil
+}
+
+// feeRateIndex returns the index of the fee rate to use
+// in a sorted list of n fee rates. The lower the confirmation
+// target, the higher the percentile we pick.
+func feeRateIndex(n int, confTarget int64) int {
+	percentile := maxFeePercentile - float64(confTarget-1)*feePercentileStep
+	if percentile < minFeePercentile {
+		percentile = minFeePercentile
+	}
+
+	index := int(math.Ceil(percentile*float64(n))) - 1
+	if index < 0 {
+		return 0
+	}
+
+	return index
+}
+
+// transactionFeeRate returns the fee rate (in ZEN per kB) paid by
+// a transaction. Coinbase transactions, certificates and
+// transactions interacting with the shielded pool are skipped
+// because their fee cannot be computed from transparent amounts.
+func transactionFeeRate(transaction *types.Transaction) (float64, bool) {
+	var metadata zen.TransactionMetadata
+	if err := types.UnmarshalMap(transaction.Metadata, &metadata); err != nil {
+		return -1, false
+	}
+
+	if metadata.Size == 0 || len(metadata.Joinsplit) > 0 {
+		return -1, false
+	}
+
+	// Forward transfers and sidechain creations are not
+	// parsed as outputs, so their amounts would be counted
+	// as fee.
+	if metadata.Version == wire.SidechainTxVersion {
+		return -1, false
+	}
+
+	fee := new(big.Int)
+	hasInput := false
+	for _, op := range transaction.Operations {
+		switch op.Type {
+		case zen.InputOpType:
+			hasInput = true
+		case zen.OutputOpType:
+		default:
+			return -1, false
+		}
+
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return -1, false
+		}
+
+		// Inputs are negative, so the fee is the
+		// negated sum of all amounts.
+		fee.Sub(fee, value)
+	}
+
+	if !hasInput || fee.Sign() < 0 {
+		return -1, false
+	}
+
+	feeRate := float64(fee.Int64()) / float64(metadata.Size) * bytesInKb / zen.SatoshisInBitcoin
+
+	return feeRate, true
+}
@@ -18,10 +18,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/HorizenOfficial/rosetta-zen/zen"
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
+	"github.com/HorizenOfficial/rosetta-zen/search"
 	"github.com/HorizenOfficial/rosetta-zen/services"
 	"github.com/HorizenOfficial/rosetta-zen/utils"
 
@@ -42,9 +48,6 @@ const (
 	// a particular height).
 	indexPlaceholder = -1
 
-	retryDelay = 10 * time.Second
-	retryLimit = 5
-
 	nodeWaitSleep           = 3 * time.Second
 	missingTransactionDelay = 200 * time.Millisecond
 
@@ -56,10 +59,23 @@ const (
 
 	// zeroValue is 0 as a string
 	zeroValue = "0"
+
+	// rebroadcastFrequency is how often pending
+	// transactions missing from the mempool are
+	// submitted to zend again.
+	rebroadcastFrequency = 1 * time.Minute
+
+	// databaseSizeFrequency is how often the size
+	// of the database is reported.
+	databaseSizeFrequency = 30 * time.Second
 )
 
 var (
 	errMissingTransaction = errors.New("missing transaction")
+
+	// errNodeNotReady is returned while the zend daemon
+	// started by rosetta-zen is (re)starting.
+	errNodeNotReady = errors.New("zend is not ready")
 )
 
 // Client is used by the indexer to sync blocks.
@@ -72,6 +88,8 @@ type Client interface {
 		*zen.Block,
 		map[string]*storage.AccountCoin,
 	) (*types.Block, error)
+	RawMempool(context.Context) ([]string, error)
+	SendRawTransaction(context.Context, string) (string, error)
 }
 
 var _ syncer.Handler = (*Indexer)(nil)
@@ -84,17 +102,51 @@ type Indexer struct {
 
 	network       *types.NetworkIdentifier
 	pruningConfig *configuration.PruningConfiguration
+	path          string
 
 	client Client
 
+	// node is the zend daemon started by rosetta-zen
+	// (nil when connected to an external zend).
+	node services.NodeProcess
+
 	asserter       *asserter.Asserter
 	database       storage.Database
 	blockStorage   *storage.BlockStorage
 	balanceStorage *storage.BalanceStorage
 	coinStorage    *storage.CoinStorage
+	txStorage        *TransactionStorage
+	broadcastStorage *BroadcastStorage
 	workers        []storage.BlockWorker
 
 	waiter *waitTable
+
+	// nodeWait is how long to wait before sending
+	// a request again while zend is unavailable.
+	nodeWait time.Duration
+
+	// reorgDepth is the number of blocks removed
+	// since the last block was added.
+	reorgDepth int64
+
+	// feeRates are the sorted fee rates paid in the
+	// feeEstimateBlocks blocks up to feeRatesTip. They
+	// are only read again once the tip changes.
+	feeRatesMutex sync.Mutex
+	feeRatesTip   *types.BlockIdentifier
+	feeRates      []float64
+}
+
+// Option is used to configure an Indexer.
+type Option func(*Indexer)
+
+// WithNode makes the Indexer wait while the zend daemon
+// started by rosetta-zen is stopped or (re)starting, instead
+// of failing once the client gives up on a request.
+func WithNode(node services.NodeProcess) Option {
+	return func(i *Indexer) {
+		i.node = node
+	}
 }
 
 // CloseDatabase closes a storage.Database. This should be called
@@ -163,6 +215,7 @@ func Initialize(
 	cancel context.CancelFunc,
 	config *configuration.Configuration,
 	client Client,
+	options ...Option,
 ) (*Indexer, error) {
 	localStore, err := storage.NewBadgerStorage(
 		ctx,
@@ -193,13 +246,19 @@ func Initialize(
 		cancel:        cancel,
 		network:       config.Network,
 		pruningConfig: config.Pruning,
+		path:          config.IndexerPath,
 		client:        client,
 		database:      localStore,
 		blockStorage:  blockStorage,
 		waiter:        newWaitTable(),
+		nodeWait:      nodeWaitSleep,
 		asserter:      asserter,
 	}
 
+	for _, opt := range options {
+		opt(i)
+	}
+
 	coinStorage := storage.NewCoinStorage(
 		localStore,
 		&CoinStorageHelper{blockStorage},
@@ -214,7 +273,18 @@ func Initialize(
 	)
 	i.balanceStorage = balanceStorage
 
-	i.workers = []storage.BlockWorker{coinStorage, balanceStorage}
+	txStorage := NewTransactionStorage(localStore, blockStorage, asserter)
+	i.txStorage = txStorage
+
+	broadcastStorage := NewBroadcastStorage(localStore)
+	i.broadcastStorage = broadcastStorage
+
+	i.workers = []storage.BlockWorker{
+		coinStorage,
+		balanceStorage,
+		txStorage,
+		broadcastStorage,
+	}
 
 	return i, nil
 }
@@ -231,7 +301,47 @@ func (i *Indexer) waitForNode(ctx context.Context) error {
 		}
 
 		logger.Infow("waiting for zend...")
-		if err := sdkUtils.ContextSleep(ctx, nodeWaitSleep); err != nil {
+		if err := sdkUtils.ContextSleep(ctx, i.nodeWait); err != nil {
+			return err
+		}
+	}
+}
+
+// nodeReady returns errNodeNotReady while the zend
+// daemon started by rosetta-zen is stopped or starting
+// (it is restarted by its Supervisor).
+func (i *Indexer) nodeReady() error {
+	if i.node == nil {
+		return nil
+	}
+
+	switch state := i.node.State(); state {
+	case zen.NodeStopped, zen.NodeStarting, zen.NodeLoadingBlockIndex, zen.NodeRescanning:
+		return fmt.Errorf("%w: %s", errNodeNotReady, state)
+	default:
+		return nil
+	}
+}
+
+// waitForZend calls f until it succeeds, fails with an
+// error other than zend being unavailable or ctx is done.
+// The client gives up on a request once its RetryPolicy
+// is exhausted, which is much shorter than a zend restart,
+// and any error returned to the syncer stops rosetta-zen.
+// f is not called while zend is restarting.
+func (i *Indexer) waitForZend(ctx context.Context, f func() error) error {
+	logger := utils.ExtractLogger(ctx, "indexer")
+	for {
+		err := i.nodeReady()
+		if err == nil {
+			err = f()
+			if err == nil || (!zen.IsUnavailable(err) && i.nodeReady() == nil) {
+				return err
+			}
+		}
+
+		logger.Warnw("zend unavailable, waiting...", "error", err)
+		if err := sdkUtils.ContextSleep(ctx, i.nodeWait); err != nil {
 			return err
 		}
 	}
@@ -315,6 +425,134 @@ func (i *Indexer) Prune(ctx context.Context) error {
 	}
 }
 
+// Rebroadcast periodically submits the pending tracked
+// transactions that are missing from the mempool to zend
+// again.
+func (i *Indexer) Rebroadcast(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "rebroadcaster")
+
+	tc := time.NewTicker(rebroadcastFrequency)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Warnw("exiting rebroadcaster")
+			return ctx.Err()
+		case <-tc.C:
+			if err := i.rebroadcastPending(ctx); err != nil {
+				logger.Warnw("unable to rebroadcast transactions", "error", err)
+			}
+		}
+	}
+}
+
+// rebroadcastPending submits the pending tracked transactions
+// that are missing from the mempool. Transactions rejected by
+// zend stay pending with the rejection as their error.
+func (i *Indexer) rebroadcastPending(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "rebroadcaster")
+
+	pending, err := i.broadcastStorage.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	mempool, err := i.client.RawMempool(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get mempool", err)
+	}
+
+	inMempool := make(map[string]struct{}, len(mempool))
+	for _, hash := range mempool {
+		inMempool[hash] = struct{}{}
+	}
+
+	for _, transaction := range pending {
+		hash := transaction.TransactionIdentifier.Hash
+		if _, ok := inMempool[hash]; ok {
+			continue
+		}
+
+		_, broadcastErr := i.client.SendRawTransaction(ctx, transaction.NetworkTransaction)
+		if broadcastErr != nil && !errors.Is(broadcastErr, zen.ErrJSONRPCError) {
+			// The transaction did not reach zend, so we
+			// try again on the next tick without skipping
+			// the other pending transactions.
+			logger.Warnw("unable to rebroadcast transaction", "hash", hash, "error", broadcastErr)
+			continue
+		}
+
+		if err := i.broadcastStorage.Rebroadcast(ctx, hash, broadcastErr); err != nil {
+			return err
+		}
+
+		if broadcastErr != nil {
+			logger.Warnw("transaction rejected", "hash", hash, "error", broadcastErr)
+			continue
+		}
+
+		logger.Infow("rebroadcast transaction", "hash", hash)
+	}
+
+	return nil
+}
+
+// MonitorDatabaseSize periodically reports the size of the
+// LSM tree and of the value log of the database.
+func (i *Indexer) MonitorDatabaseSize(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "database")
+
+	tc := time.NewTicker(databaseSizeFrequency)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+			lsm, vlog, err := databaseSize(i.path)
+			if err != nil {
+				logger.Warnw("unable to get database size", "error", err)
+				continue
+			}
+
+			metrics.DatabaseSize.WithLabelValues("lsm").Set(float64(lsm))
+			metrics.DatabaseSize.WithLabelValues("vlog").Set(float64(vlog))
+		}
+	}
+}
+
+// databaseSize returns the size of the LSM tree and
+// of the value log of the Badger database in dir, the
+// same way badger.DB.Size does.
+func databaseSize(dir string) (int64, int64, error) {
+	var lsm, vlog int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch filepath.Ext(path) {
+		case ".sst":
+			lsm += info.Size()
+		case ".vlog":
+			vlog += info.Size()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return -1, -1, fmt.Errorf("%w: unable to walk %s", err, dir)
+	}
+
+	return lsm, vlog, nil
+}
+
 // BlockAdded is called by the syncer when a block is added.
 func (i *Indexer) BlockAdded(ctx context.Context, block *types.Block) error {
 	logger := utils.ExtractLogger(ctx, "indexer")
@@ -329,6 +567,14 @@ func (i *Indexer) BlockAdded(ctx context.Context, block *types.Block) error {
 		)
 	}
 
+	metrics.IndexerHeadHeight.Set(float64(block.BlockIdentifier.Index))
+	metrics.BlocksAdded.Inc()
+	if i.reorgDepth > 0 {
+		metrics.Reorgs.Inc()
+		metrics.ReorgDepth.Observe(float64(i.reorgDepth))
+		i.reorgDepth = 0
+	}
+
 	ops := 0
 
 	// Close channels of all blocks waiting.
@@ -406,6 +652,10 @@ func (i *Indexer) BlockRemoved(
 		)
 	}
 
+	metrics.IndexerHeadHeight.Set(float64(blockIdentifier.Index - 1))
+	metrics.BlocksRemoved.Inc()
+	i.reorgDepth++
+
 	return nil
 }
 
@@ -415,7 +665,19 @@ func (i *Indexer) NetworkStatus(
 	ctx context.Context,
 	network *types.NetworkIdentifier,
 ) (*types.NetworkStatusResponse, error) {
-	return i.client.NetworkStatus(ctx)
+	var status *types.NetworkStatusResponse
+	err := i.waitForZend(ctx, func() error {
+		var err error
+		status, err = i.client.NetworkStatus(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.NodeTipHeight.Set(float64(status.CurrentBlockIdentifier.Index))
+
+	return status, nil
 }
 
 func (i *Indexer) findCoin(
@@ -628,23 +890,13 @@ func (i *Indexer) Block(
 	// get raw block
 	var btcBlock *zen.Block
 	var coins []string
-	var err error
-
-	retries := 0
-	for ctx.Err() == nil {
+	err := i.waitForZend(ctx, func() error {
+		var err error
 		btcBlock, coins, err = i.client.GetRawBlock(ctx, blockIdentifier)
-		if err == nil {
-			break
-		}
-
-		retries++
-		if retries > retryLimit {
-			return nil, fmt.Errorf("%w: unable to get raw block %+v", err, blockIdentifier)
-		}
-
-		if err := sdkUtils.ContextSleep(ctx, retryDelay); err != nil {
-			return nil, err
-		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get raw block %+v", err, blockIdentifier)
 	}
 
 	// determine which coins must be fetched and get from coin storage
@@ -700,7 +952,9 @@ func (i *Indexer) GetScriptPubKeys(
 		}
 
 		for _, op := range transaction.Operations {
-			if op.Type != zen.OutputOpType {
+			// Matured backward transfers create coins
+			// like any other output.
+			if op.Type != zen.OutputOpType && op.Type != zen.BackwardTransferOpType {
 				continue
 			}
 
@@ -780,6 +1034,53 @@ func (i *Indexer) GetCoins(
 	return i.coinStorage.GetCoins(ctx, accountIdentifier)
 }
 
+// GetCoin returns an unspent coin and its owner by *types.CoinIdentifier.
+func (i *Indexer) GetCoin(
+	ctx context.Context,
+	coinIdentifier *types.CoinIdentifier,
+) (*types.Coin, *types.AccountIdentifier, error) {
+	return i.coinStorage.GetCoin(ctx, coinIdentifier)
+}
+
+// SearchTransactions returns the transactions matching
+// a *search.TransactionQuery and the total number
+// of matches.
+func (i *Indexer) SearchTransactions(
+	ctx context.Context,
+	query *search.TransactionQuery,
+) ([]*search.BlockTransaction, int64, error) {
+	return i.txStorage.Search(ctx, query)
+}
+
+// TrackTransaction stores a transaction submitted
+// to zend so that it is rebroadcast until it is
+// included in a block.
+func (i *Indexer) TrackTransaction(
+	ctx context.Context,
+	transaction *broadcast.Transaction,
+) error {
+	return i.broadcastStorage.Track(ctx, transaction)
+}
+
+// GetBroadcast returns a tracked transaction.
+func (i *Indexer) GetBroadcast(
+	ctx context.Context,
+	hash string,
+) (*broadcast.Transaction, error) {
+	return i.broadcastStorage.Get(ctx, hash)
+}
+
+// CheckDatabase returns an error if
+// the database cannot be read.
+func (i *Indexer) CheckDatabase(ctx context.Context) error {
+	_, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil && !errors.Is(err, storage.ErrHeadBlockNotFound) {
+		return fmt.Errorf("%w: unable to read database", err)
+	}
+
+	return nil
+}
+
 // GetBalance returns the balance of an account
 // at a particular *types.PartialBlockIdentifier.
 func (i *Indexer) GetBalance(
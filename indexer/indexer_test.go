@@ -19,17 +19,26 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/HorizenOfficial/rosetta-zen/zen"
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
 	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/indexer"
+	"github.com/HorizenOfficial/rosetta-zen/search"
+	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
 
 	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -213,6 +222,297 @@ func TestIndexer_Pruning(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestIndexer_WaitsForZend(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.nodeWait = 10 * time.Millisecond
+
+	// zend stays unavailable for longer than the RetryPolicy
+	// of the client, which returns an error after each
+	// exhausted request (and then opens its breaker).
+	unavailable := fmt.Errorf("%w: unable to get raw block", zen.ErrCircuitOpen)
+	status := &types.NetworkStatusResponse{
+		CurrentBlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(1),
+			Index: 1,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+	}
+	mockClient.On("NetworkStatus", ctx).Return(nil, unavailable).Times(3)
+	mockClient.On("NetworkStatus", ctx).Return(status, nil).Once()
+
+	returnedStatus, err := i.NetworkStatus(ctx, cfg.Network)
+	assert.NoError(t, err)
+	assert.Equal(t, status, returnedStatus)
+
+	index := int64(1)
+	blockIdentifier := &types.PartialBlockIdentifier{Index: &index}
+	rawBlock := &zen.Block{
+		Hash:              getBlockHash(1),
+		Height:            1,
+		PreviousBlockHash: getBlockHash(0),
+	}
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(1),
+			Index: 1,
+		},
+		ParentBlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(0),
+			Index: 0,
+		},
+		Timestamp: 1599002115110,
+	}
+	mockClient.On(
+		"GetRawBlock",
+		mock.Anything,
+		blockIdentifier,
+	).Return(
+		nil,
+		nil,
+		unavailable,
+	).Times(5)
+	mockClient.On(
+		"GetRawBlock",
+		mock.Anything,
+		blockIdentifier,
+	).Return(
+		rawBlock,
+		[]string{},
+		nil,
+	).Once()
+	mockClient.On(
+		"ParseBlock",
+		mock.Anything,
+		rawBlock,
+		map[string]*storage.AccountCoin{},
+	).Return(
+		block,
+		nil,
+	).Once()
+
+	returnedBlock, err := i.Block(ctx, cfg.Network, blockIdentifier)
+	assert.NoError(t, err)
+	assert.Equal(t, block, returnedBlock)
+
+	// Other errors are returned immediately
+	notFound := errors.New("block not found")
+	mockClient.On(
+		"GetRawBlock",
+		mock.Anything,
+		blockIdentifier,
+	).Return(
+		nil,
+		nil,
+		notFound,
+	).Once()
+
+	_, err = i.Block(ctx, cfg.Network, blockIdentifier)
+	assert.True(t, errors.Is(err, notFound))
+
+	// Waiting stops once ctx is done
+	mockClient.On("NetworkStatus", ctx).Return(nil, unavailable)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err = i.NetworkStatus(ctx, cfg.Network)
+	assert.True(t, errors.Is(err, context.Canceled))
+	mockClient.AssertExpectations(t)
+}
+
+// testNode is a services.NodeProcess whose
+// state is changed by the test.
+type testNode struct {
+	mutex sync.Mutex
+	state zen.NodeState
+}
+
+func (n *testNode) Alive() bool {
+	state := n.State()
+	return state != zen.NodeStopped && state != zen.NodeFailed
+}
+
+func (n *testNode) State() zen.NodeState {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.state
+}
+
+func (n *testNode) LastError() string {
+	return ""
+}
+
+func (n *testNode) setState(state zen.NodeState) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.state = state
+}
+
+func TestIndexer_SyncResumesAfterZendRestart(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	node := &testNode{state: zen.NodeReady}
+	i, err := Initialize(ctx, cancel, cfg, mockClient, WithNode(node))
+	assert.NoError(t, err)
+	i.nodeWait = 10 * time.Millisecond
+
+	tip := int64(5)
+	mockClient.On("NetworkStatus", ctx).Return(&types.NetworkStatusResponse{
+		CurrentBlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(tip),
+			Index: tip,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+	}, nil)
+
+	// zend exits while block 3 is fetched. Restarting it
+	// (and loading its block index) takes longer than the
+	// RetryPolicy of the client.
+	restarted := make(chan struct{})
+	restart := func(args mock.Arguments) {
+		node.setState(zen.NodeStopped)
+		go func() {
+			for _, state := range []zen.NodeState{
+				zen.NodeStarting,
+				zen.NodeLoadingBlockIndex,
+				zen.NodeReady,
+			} {
+				time.Sleep(50 * time.Millisecond)
+				node.setState(state)
+			}
+			close(restarted)
+		}()
+	}
+
+	for index := int64(0); index <= tip; index++ {
+		identifier := &types.BlockIdentifier{
+			Hash:  getBlockHash(index),
+			Index: index,
+		}
+		parentIdentifier := &types.BlockIdentifier{
+			Hash:  getBlockHash(index - 1),
+			Index: index - 1,
+		}
+		if parentIdentifier.Index < 0 {
+			parentIdentifier.Index = 0
+			parentIdentifier.Hash = getBlockHash(0)
+		}
+
+		block := &zen.Block{
+			Hash:              identifier.Hash,
+			Height:            identifier.Index,
+			PreviousBlockHash: parentIdentifier.Hash,
+		}
+		partialIdentifier := &types.PartialBlockIdentifier{Index: &identifier.Index}
+		if index == 3 {
+			mockClient.On(
+				"GetRawBlock",
+				mock.Anything,
+				partialIdentifier,
+			).Return(
+				nil,
+				nil,
+				errors.New("unexpected EOF"),
+			).Run(restart).Once()
+			mockClient.On(
+				"GetRawBlock",
+				mock.Anything,
+				partialIdentifier,
+			).Return(
+				block,
+				[]string{},
+				nil,
+			).Run(func(args mock.Arguments) {
+				assert.Equal(t, zen.NodeReady, node.State())
+			}).Once()
+		} else {
+			mockClient.On(
+				"GetRawBlock",
+				mock.Anything,
+				partialIdentifier,
+			).Return(
+				block,
+				[]string{},
+				nil,
+			).Once()
+		}
+
+		mockClient.On(
+			"ParseBlock",
+			mock.Anything,
+			block,
+			map[string]*storage.AccountCoin{},
+		).Return(
+			&types.Block{
+				BlockIdentifier:       identifier,
+				ParentBlockIdentifier: parentIdentifier,
+				Timestamp:             1599002115110,
+			},
+			nil,
+		).Once()
+	}
+
+	syncErr := make(chan error, 1)
+	go func() {
+		syncErr <- i.Sync(ctx)
+	}()
+
+	for {
+		head, err := i.blockStorage.GetHeadBlockIdentifier(ctx)
+		if err == nil && head.Index == tip {
+			break
+		}
+
+		select {
+		case err := <-syncErr:
+			assert.FailNow(t, "sync stopped", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	<-restarted
+	cancel()
+	mockClient.AssertExpectations(t)
+}
+
 func TestIndexer_Transactions(t *testing.T) {
 	// Create Indexer
 	ctx := context.Background()
@@ -452,6 +752,7 @@ func TestIndexer_Reorg(t *testing.T) {
 
 	i, err := Initialize(ctx, cancel, cfg, mockClient)
 	assert.NoError(t, err)
+	reorgs := testutil.ToFloat64(metrics.Reorgs)
 
 	// Sync to 1000
 	mockClient.On("NetworkStatus", ctx).Return(&types.NetworkStatusResponse{
@@ -670,6 +971,10 @@ func TestIndexer_Reorg(t *testing.T) {
 
 	<-waitForFinish
 	assert.Len(t, i.waiter.table, 0)
+	assert.Greater(t, testutil.ToFloat64(metrics.Reorgs), reorgs)
+	assert.Equal(t, float64(1000), testutil.ToFloat64(metrics.IndexerHeadHeight))
+	assert.Equal(t, float64(1000), testutil.ToFloat64(metrics.NodeTipHeight))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.WaitTableSize))
 	mockClient.AssertExpectations(t)
 }
 
@@ -837,3 +1142,744 @@ func TestIndexer_HeaderReorg(t *testing.T) {
 	assert.Len(t, i.waiter.table, 0)
 	mockClient.AssertExpectations(t)
 }
+
+func feeTransaction(
+	t *testing.T,
+	hash string,
+	size int64,
+	inputs []string,
+	outputs []string,
+) *types.Transaction {
+	ops := []*types.Operation{}
+	for _, value := range inputs {
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+			Type:                zen.InputOpType,
+			Amount:              &types.Amount{Value: value, Currency: zen.MainnetCurrency},
+		})
+	}
+	for _, value := range outputs {
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(len(ops))},
+			Type:                zen.OutputOpType,
+			Amount:              &types.Amount{Value: value, Currency: zen.MainnetCurrency},
+		})
+	}
+
+	metadata, err := types.MarshalMap(&zen.TransactionMetadata{Size: size})
+	assert.NoError(t, err)
+
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+		Operations:            ops,
+		Metadata:              metadata,
+	}
+}
+
+// sidechainFeeTransaction turns a transaction returned by
+// feeTransaction into a sidechain transaction whose forward
+// transfer (not parsed as an output) is its missing amount.
+func sidechainFeeTransaction(t *testing.T, transaction *types.Transaction) *types.Transaction {
+	var metadata zen.TransactionMetadata
+	assert.NoError(t, types.UnmarshalMap(transaction.Metadata, &metadata))
+
+	metadata.Version = wire.SidechainTxVersion
+	updated, err := types.MarshalMap(&metadata)
+	assert.NoError(t, err)
+
+	transaction.Metadata = updated
+	return transaction
+}
+
+func TestIndexer_EstimateFeeRate(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	// No blocks indexed yet
+	_, err = i.EstimateFeeRate(ctx, 1)
+	assert.Error(t, err)
+
+	// Genesis only contains a coinbase
+	genesis := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(0),
+			Index: 0,
+		},
+		ParentBlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(0),
+			Index: 0,
+		},
+		Transactions: []*types.Transaction{
+			feeTransaction(t, "coinbase 0", 100, nil, []string{"1250000000"}),
+		},
+	}
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, genesis))
+
+	rate, err := i.EstimateFeeRate(ctx, 1)
+	assert.True(t, errors.Is(err, zen.ErrInsufficientFeeData))
+	assert.Equal(t, float64(-1), rate)
+
+	// Add blocks paying 1..10 satoshis per byte
+	for j := int64(1); j <= 10; j++ {
+		fee := j * 200
+		block := &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{
+				Hash:  getBlockHash(j),
+				Index: j,
+			},
+			ParentBlockIdentifier: &types.BlockIdentifier{
+				Hash:  getBlockHash(j - 1),
+				Index: j - 1,
+			},
+			Transactions: []*types.Transaction{
+				feeTransaction(
+					t,
+					fmt.Sprintf("coinbase %d", j),
+					100,
+					nil,
+					[]string{"1250000000"},
+				),
+				feeTransaction(
+					t,
+					fmt.Sprintf("tx %d", j),
+					200,
+					[]string{"-100000"},
+					[]string{"50000", fmt.Sprintf("%d", 50000-fee)},
+				),
+			},
+		}
+		assert.NoError(t, i.blockStorage.AddBlock(ctx, block))
+	}
+
+	tests := map[string]struct {
+		confTarget int64
+		rate       float64
+	}{
+		"next block": {
+			confTarget: 1,
+			rate:       0.00009,
+		},
+		"within 3 blocks": {
+			confTarget: 3,
+			rate:       0.00007,
+		},
+		"within 25 blocks": {
+			confTarget: 25,
+			rate:       0.00005,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rate, err := i.EstimateFeeRate(ctx, test.confTarget)
+			assert.NoError(t, err)
+			assert.InDelta(t, test.rate, rate, 1e-12)
+		})
+	}
+
+	// The cached rates are replaced once a block is added
+	for j := int64(11); j <= 15; j++ {
+		block := &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{
+				Hash:  getBlockHash(j),
+				Index: j,
+			},
+			ParentBlockIdentifier: &types.BlockIdentifier{
+				Hash:  getBlockHash(j - 1),
+				Index: j - 1,
+			},
+			Transactions: []*types.Transaction{
+				feeTransaction(
+					t,
+					fmt.Sprintf("tx %d", j),
+					200,
+					[]string{"-100000"},
+					[]string{"50000", "46000"},
+				),
+			},
+		}
+		assert.NoError(t, i.blockStorage.AddBlock(ctx, block))
+	}
+
+	rate, err = i.EstimateFeeRate(ctx, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.0002, rate, 1e-12)
+}
+
+func TestTransactionFeeRate(t *testing.T) {
+	tests := map[string]struct {
+		transaction *types.Transaction
+		rate        float64
+		ok          bool
+	}{
+		"simple": {
+			transaction: feeTransaction(
+				t,
+				"tx",
+				250,
+				[]string{"-10000", "-5000"},
+				[]string{"12500"},
+			),
+			rate: 0.0001,
+			ok:   true,
+		},
+		"coinbase": {
+			transaction: feeTransaction(t, "tx", 100, nil, []string{"1250000000"}),
+		},
+		"missing size": {
+			transaction: feeTransaction(t, "tx", 0, []string{"-10000"}, []string{"9000"}),
+		},
+		"negative fee": {
+			transaction: feeTransaction(t, "tx", 100, []string{"-10000"}, []string{"11000"}),
+		},
+		"forward transfer": {
+			transaction: sidechainFeeTransaction(
+				t,
+				feeTransaction(
+					t,
+					"tx",
+					250,
+					[]string{"-1000000000"},
+					[]string{"499990000"},
+				),
+			),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rate, ok := transactionFeeRate(test.transaction)
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.InDelta(t, test.rate, rate, 1e-12)
+			}
+		})
+	}
+}
+
+func historyOperation(
+	index int64,
+	opType string,
+	address string,
+	coin string,
+	value string,
+) *types.Operation {
+	action := types.CoinCreated
+	if opType == zen.InputOpType {
+		action = types.CoinSpent
+	}
+
+	return &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{Index: index},
+		Type:                opType,
+		Status:              zen.SuccessStatus,
+		Account:             &types.AccountIdentifier{Address: address},
+		Amount:              &types.Amount{Value: value, Currency: zen.MainnetCurrency},
+		CoinChange: &types.CoinChange{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: coin},
+			CoinAction:     action,
+		},
+	}
+}
+
+func historyBlock(index int64, hash string, transactions ...*types.Transaction) *types.Block {
+	parentIndex := index - 1
+	if parentIndex < 0 {
+		parentIndex = 0
+	}
+
+	return &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Hash:  hash,
+			Index: index,
+		},
+		ParentBlockIdentifier: &types.BlockIdentifier{
+			Hash:  getBlockHash(parentIndex),
+			Index: parentIndex,
+		},
+		Transactions: transactions,
+	}
+}
+
+func TestIndexer_SearchTransactions(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize([]storage.BlockWorker{i.txStorage})
+
+	coinbase0 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx0"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.CoinbaseOpType, "addr1", "tx0:0", "1000"),
+		},
+	}
+	coinbase1 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.CoinbaseOpType, "addr2", "tx1:0", "1000"),
+		},
+	}
+	spend := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx2"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.InputOpType, "addr1", "tx0:0", "-1000"),
+			historyOperation(1, zen.OutputOpType, "addr2", "tx2:0", "600"),
+			historyOperation(2, zen.OutputOpType, "addr1", "tx2:1", "300"),
+		},
+	}
+	reorged := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx3"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.InputOpType, "addr1", "tx2:1", "-300"),
+			historyOperation(1, zen.OutputOpType, "addr3", "tx3:0", "200"),
+		},
+	}
+
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(0, getBlockHash(0), coinbase0)))
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(1, getBlockHash(1), coinbase1)))
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(2, getBlockHash(2), spend)))
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(3, "orphan 3", reorged)))
+
+	hashes := func(transactions []*search.BlockTransaction) []string {
+		result := []string{}
+		for _, transaction := range transactions {
+			result = append(result, transaction.Transaction.TransactionIdentifier.Hash)
+		}
+
+		return result
+	}
+
+	max1 := int64(1)
+	success := true
+	failure := false
+	tests := map[string]struct {
+		query *search.TransactionQuery
+
+		hashes     []string
+		totalCount int64
+	}{
+		"address": {
+			query:      &search.TransactionQuery{Address: "addr1", Limit: 10},
+			hashes:     []string{"tx3", "tx2", "tx0"},
+			totalCount: 3,
+		},
+		"address with pagination": {
+			query:      &search.TransactionQuery{Address: "addr1", Offset: 1, Limit: 1},
+			hashes:     []string{"tx2"},
+			totalCount: 3,
+		},
+		"address with max block": {
+			query:      &search.TransactionQuery{Address: "addr2", MaxBlock: &max1, Limit: 10},
+			hashes:     []string{"tx1"},
+			totalCount: 1,
+		},
+		"address and type": {
+			query: &search.TransactionQuery{
+				Address:       "addr1",
+				OperationType: zen.InputOpType,
+				Limit:         10,
+			},
+			hashes:     []string{"tx3", "tx2"},
+			totalCount: 2,
+		},
+		"address and coin": {
+			query: &search.TransactionQuery{
+				Address:        "addr2",
+				CoinIdentifier: "tx0:0",
+				Limit:          10,
+			},
+			hashes:     []string{},
+			totalCount: 0,
+		},
+		"coin": {
+			query:      &search.TransactionQuery{CoinIdentifier: "tx0:0", Limit: 10},
+			hashes:     []string{"tx2", "tx0"},
+			totalCount: 2,
+		},
+		"transaction hash": {
+			query:      &search.TransactionQuery{TransactionHash: "tx2", Success: &success, Limit: 10},
+			hashes:     []string{"tx2"},
+			totalCount: 1,
+		},
+		"transaction hash with max block": {
+			query:      &search.TransactionQuery{TransactionHash: "tx2", MaxBlock: &max1, Limit: 10},
+			hashes:     []string{},
+			totalCount: 0,
+		},
+		"unsuccessful": {
+			query:      &search.TransactionQuery{Address: "addr1", Success: &failure, Limit: 10},
+			hashes:     []string{},
+			totalCount: 0,
+		},
+		"unknown transaction": {
+			query:      &search.TransactionQuery{TransactionHash: "missing", Limit: 10},
+			hashes:     []string{},
+			totalCount: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			transactions, totalCount, err := i.SearchTransactions(ctx, test.query)
+			assert.NoError(t, err)
+			assert.Equal(t, test.hashes, hashes(transactions))
+			assert.Equal(t, test.totalCount, totalCount)
+		})
+	}
+
+	// Removing a block must remove all of its entries
+	assert.NoError(t, i.blockStorage.RemoveBlock(ctx, &types.BlockIdentifier{
+		Hash:  "orphan 3",
+		Index: 3,
+	}))
+
+	transactions, totalCount, err := i.SearchTransactions(ctx, &search.TransactionQuery{
+		Address: "addr1",
+		Limit:   10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx2", "tx0"}, hashes(transactions))
+	assert.Equal(t, int64(2), totalCount)
+	assert.Equal(t, getBlockHash(2), transactions[0].BlockIdentifier.Hash)
+	assert.Equal(t, spend, transactions[0].Transaction)
+
+	transactions, totalCount, err = i.SearchTransactions(ctx, &search.TransactionQuery{
+		Address: "addr3",
+		Limit:   10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 0)
+	assert.Equal(t, int64(0), totalCount)
+
+	_, _, err = i.SearchTransactions(ctx, &search.TransactionQuery{Limit: 10})
+	assert.True(t, errors.Is(err, ErrMissingSearchAnchor))
+}
+
+func TestIndexer_SearchTransactionsIncompleteIndex(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+
+	// Blocks synced before the index existed
+	// have no entries in it.
+	coinbase0 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx0"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.CoinbaseOpType, "addr1", "tx0:0", "1000"),
+		},
+	}
+	i.blockStorage.Initialize([]storage.BlockWorker{})
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(0, getBlockHash(0), coinbase0)))
+
+	coinbase1 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.CoinbaseOpType, "addr1", "tx1:0", "1000"),
+		},
+	}
+	i.blockStorage.Initialize([]storage.BlockWorker{i.txStorage})
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(1, getBlockHash(1), coinbase1)))
+
+	_, _, err = i.SearchTransactions(ctx, &search.TransactionQuery{
+		Address: "addr1",
+		Limit:   10,
+	})
+	assert.True(t, errors.Is(err, ErrTransactionIndexIncomplete))
+
+	_, _, err = i.SearchTransactions(ctx, &search.TransactionQuery{
+		CoinIdentifier: "tx1:0",
+		Limit:          10,
+	})
+	assert.True(t, errors.Is(err, ErrTransactionIndexIncomplete))
+
+	// Searching by hash does not use the index
+	transactions, totalCount, err := i.SearchTransactions(ctx, &search.TransactionQuery{
+		TransactionHash: "tx0",
+		Limit:           10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, int64(1), totalCount)
+}
+
+func TestIndexer_Broadcasts(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize([]storage.BlockWorker{i.broadcastStorage})
+
+	tracked := func(hash string, input string) *broadcast.Transaction {
+		return &broadcast.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			NetworkTransaction:    "raw " + hash,
+			Inputs:                []string{input},
+		}
+	}
+	for hash, input := range map[string]string{
+		"tx1": "tx0:0",
+		"tx2": "tx0:1",
+		"tx3": "tx0:2",
+		"tx4": "tx0:3",
+	} {
+		assert.NoError(t, i.TrackTransaction(ctx, tracked(hash, input)))
+	}
+
+	status := func(hash string) *broadcast.Transaction {
+		transaction, err := i.GetBroadcast(ctx, hash)
+		assert.NoError(t, err)
+
+		return transaction
+	}
+	assert.Equal(t, broadcast.Pending, status("tx1").Status)
+	assert.Equal(t, int64(1), status("tx1").Broadcasts)
+
+	_, err = i.GetBroadcast(ctx, "missing")
+	assert.True(t, errors.Is(err, broadcast.ErrNotFound))
+
+	// tx2 does not reach zend, so it is not updated
+	// but the other transactions are still rebroadcast.
+	mockClient.On("RawMempool", ctx).Return([]string{"tx1"}, nil).Once()
+	mockClient.On(
+		"SendRawTransaction",
+		ctx,
+		"raw tx2",
+	).Return(
+		"",
+		errors.New("connection refused"),
+	).Once()
+	mockClient.On("SendRawTransaction", ctx, "raw tx3").Return("tx3", nil).Once()
+	mockClient.On("SendRawTransaction", ctx, "raw tx4").Return("tx4", nil).Once()
+	assert.NoError(t, i.rebroadcastPending(ctx))
+	assert.Equal(t, broadcast.Pending, status("tx2").Status)
+	assert.Equal(t, int64(1), status("tx2").Broadcasts)
+	assert.Equal(t, int64(2), status("tx3").Broadcasts)
+	assert.Equal(t, int64(2), status("tx4").Broadcasts)
+
+	// Transactions missing from the mempool are rebroadcast.
+	// Those rejected stay pending: zend also rejects
+	// transactions it already included in a block.
+	mockClient.On("RawMempool", ctx).Return([]string{"tx1"}, nil).Once()
+	mockClient.On("SendRawTransaction", ctx, "raw tx2").Return("tx2", nil).Once()
+	mockClient.On(
+		"SendRawTransaction",
+		ctx,
+		"raw tx3",
+	).Return(
+		"",
+		fmt.Errorf("%w: -27 transaction already in block chain", zen.ErrJSONRPCError),
+	).Once()
+	mockClient.On("SendRawTransaction", ctx, "raw tx4").Return("tx4", nil).Once()
+	assert.NoError(t, i.rebroadcastPending(ctx))
+	assert.Equal(t, int64(1), status("tx1").Broadcasts)
+	assert.Equal(t, broadcast.Pending, status("tx2").Status)
+	assert.Equal(t, int64(2), status("tx2").Broadcasts)
+	assert.Equal(t, broadcast.Pending, status("tx3").Status)
+	assert.Equal(t, int64(3), status("tx3").Broadcasts)
+	assert.Contains(t, status("tx3").Error, "already in block chain")
+
+	inputTracked := func(coin string) bool {
+		dbTx := i.database.NewDatabaseTransaction(ctx, false)
+		defer dbTx.Discard(ctx)
+
+		exists, _, err := dbTx.Get(ctx, getBroadcastInputKey(coin))
+		assert.NoError(t, err)
+
+		return exists
+	}
+	for _, coin := range []string{"tx0:0", "tx0:1", "tx0:2", "tx0:3"} {
+		assert.True(t, inputTracked(coin))
+	}
+
+	// tx1 is confirmed and tx2 conflicts with tx5
+	tx1 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.InputOpType, "addr1", "tx0:0", "-1000"),
+		},
+	}
+	tx5 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx5"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.InputOpType, "addr1", "tx0:1", "-1000"),
+		},
+	}
+	tx3 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx3"},
+		Operations: []*types.Operation{
+			historyOperation(0, zen.InputOpType, "addr1", "tx0:2", "-1000"),
+		},
+	}
+	block1 := historyBlock(1, getBlockHash(1), tx1, tx5)
+	block2 := historyBlock(2, getBlockHash(2), tx3)
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(0, getBlockHash(0))))
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, block1))
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, block2))
+
+	assert.Equal(t, broadcast.Confirmed, status("tx1").Status)
+	assert.Equal(t, block1.BlockIdentifier, status("tx1").BlockIdentifier)
+	assert.Equal(t, broadcast.Conflicted, status("tx2").Status)
+	assert.Equal(t, tx5.TransactionIdentifier, status("tx2").ConflictingTransaction)
+	assert.Equal(t, block1.BlockIdentifier, status("tx2").BlockIdentifier)
+
+	// A rejected transaction can still be confirmed
+	assert.Equal(t, broadcast.Confirmed, status("tx3").Status)
+	assert.Empty(t, status("tx3").Error)
+
+	// The inputs of confirmed and conflicted
+	// transactions are no longer tracked.
+	assert.False(t, inputTracked("tx0:0"))
+	assert.False(t, inputTracked("tx0:1"))
+	assert.False(t, inputTracked("tx0:2"))
+	assert.True(t, inputTracked("tx0:3"))
+
+	// Only tx4 is still pending
+	pending, err := i.broadcastStorage.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "tx4", pending[0].TransactionIdentifier.Hash)
+
+	// Removing a block reverts its transactions to pending
+	assert.NoError(t, i.blockStorage.RemoveBlock(ctx, block2.BlockIdentifier))
+	assert.Equal(t, broadcast.Pending, status("tx3").Status)
+	assert.Nil(t, status("tx3").BlockIdentifier)
+	assert.True(t, inputTracked("tx0:2"))
+	assert.Equal(t, broadcast.Confirmed, status("tx1").Status)
+
+	assert.NoError(t, i.blockStorage.RemoveBlock(ctx, block1.BlockIdentifier))
+	assert.Equal(t, broadcast.Pending, status("tx1").Status)
+	assert.Equal(t, broadcast.Pending, status("tx2").Status)
+	assert.Nil(t, status("tx2").ConflictingTransaction)
+	assert.True(t, inputTracked("tx0:0"))
+	assert.True(t, inputTracked("tx0:1"))
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestDatabaseSize(t *testing.T) {
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	files := map[string]int{
+		"000001.sst":   10,
+		"000002.sst":   20,
+		"000001.vlog":  5,
+		"MANIFEST":     100,
+		"KEYREGISTRY":  100,
+		"000003.other": 100,
+	}
+	for name, size := range files {
+		assert.NoError(t, ioutil.WriteFile(
+			filepath.Join(newDir, name),
+			make([]byte, size),
+			os.FileMode(utils.DefaultFilePermissions),
+		))
+	}
+
+	lsm, vlog, err := databaseSize(newDir)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), lsm)
+	assert.Equal(t, int64(5), vlog)
+
+	_, _, err = databaseSize(filepath.Join(newDir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestIndexer_CheckDatabase(t *testing.T) {
+	// Create Indexer
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	newDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(newDir)
+
+	mockClient := &mocks.Client{}
+	cfg := &configuration.Configuration{
+		Network: &types.NetworkIdentifier{
+			Network:    zen.MainnetNetwork,
+			Blockchain: zen.Blockchain,
+		},
+		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		IndexerPath:            newDir,
+	}
+
+	i, err := Initialize(ctx, cancel, cfg, mockClient)
+	assert.NoError(t, err)
+	i.blockStorage.Initialize([]storage.BlockWorker{})
+
+	// An empty database is healthy
+	assert.NoError(t, i.CheckDatabase(ctx))
+
+	assert.NoError(t, i.blockStorage.AddBlock(ctx, historyBlock(0, getBlockHash(0))))
+	assert.NoError(t, i.CheckDatabase(ctx))
+}
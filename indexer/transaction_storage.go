@@ -0,0 +1,552 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/HorizenOfficial/rosetta-zen/search"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// transactionAccountNamespace is prepended to
+	// all keys of the address transaction index.
+	transactionAccountNamespace = "tx-account"
+
+	// transactionCoinNamespace is prepended to
+	// all keys of the coin transaction index.
+	transactionCoinNamespace = "tx-coin"
+
+	// transactionEntryNamespace is the namespace
+	// used to encode index entries.
+	transactionEntryNamespace = "tx-entry"
+
+	// transactionIndexCompleteKey records if the address
+	// and coin index was built from the genesis block. It
+	// is written when the first block is indexed.
+	transactionIndexCompleteKey = "tx-index/complete"
+
+	// seekEnd is greater than any character that
+	// can appear in an index key. It is used to start
+	// reverse scans from the end of a prefix.
+	seekEnd = "~"
+)
+
+var (
+	// ErrMissingSearchAnchor is returned when a transaction
+	// search does not provide a transaction hash, an
+	// address or a coin identifier.
+	ErrMissingSearchAnchor = errors.New(
+		"transaction hash, address or coin identifier must be provided",
+	)
+
+	// ErrTransactionIndexIncomplete is returned when an address
+	// or coin search is made against an index that was not
+	// built from the genesis block (i.e. blocks were synced by
+	// a version of rosetta-zen without the index). The indexer
+	// data must be deleted to rebuild it.
+	ErrTransactionIndexIncomplete = errors.New(
+		"transaction index is incomplete, delete the indexer data to reindex",
+	)
+)
+
+var _ storage.BlockWorker = (*TransactionStorage)(nil)
+
+// transactionEntry is stored for each operation
+// in the transaction index. It contains everything
+// needed to filter a search without loading
+// the transaction.
+type transactionEntry struct {
+	BlockHash  string `json:"block_hash"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	Successful bool   `json:"successful"`
+	Address    string `json:"address,omitempty"`
+	Coin       string `json:"coin,omitempty"`
+}
+
+// transactionKey identifies an operation in
+// the transaction index.
+type transactionKey struct {
+	blockIndex      int64
+	transactionHash string
+	operationIndex  int64
+}
+
+// TransactionStorage implements storage.BlockWorker
+// to maintain an index of the transactions that
+// touched each address and coin.
+type TransactionStorage struct {
+	db           storage.Database
+	blockStorage *storage.BlockStorage
+	asserter     *asserter.Asserter
+}
+
+// NewTransactionStorage returns a new *TransactionStorage.
+func NewTransactionStorage(
+	db storage.Database,
+	blockStorage *storage.BlockStorage,
+	asserter *asserter.Asserter,
+) *TransactionStorage {
+	return &TransactionStorage{
+		db:           db,
+		blockStorage: blockStorage,
+		asserter:     asserter,
+	}
+}
+
+func getTransactionAccountPrefix(address string) string {
+	return fmt.Sprintf("%s/%s/", transactionAccountNamespace, address)
+}
+
+func getTransactionCoinPrefix(coinIdentifier string) string {
+	return fmt.Sprintf("%s/%s/", transactionCoinNamespace, coinIdentifier)
+}
+
+// getTransactionKey pads the block and operation indexes
+// so that lexicographic order matches numeric order.
+func getTransactionKey(prefix string, key *transactionKey) []byte {
+	return []byte(fmt.Sprintf(
+		"%s%020d/%s/%010d",
+		prefix,
+		key.blockIndex,
+		key.transactionHash,
+		key.operationIndex,
+	))
+}
+
+// getTransactionSeekStart returns the key to start a
+// reverse scan from to only return entries in blocks
+// with an index less than or equal to maxBlock.
+func getTransactionSeekStart(prefix string, maxBlock *int64) []byte {
+	if maxBlock == nil {
+		return []byte(prefix + seekEnd)
+	}
+
+	return []byte(fmt.Sprintf("%s%020d/%s", prefix, *maxBlock, seekEnd))
+}
+
+func parseTransactionKey(prefix string, key []byte) (*transactionKey, error) {
+	parts := strings.Split(strings.TrimPrefix(string(key), prefix), "/")
+	if len(parts) != 3 { // nolint:gomnd
+		return nil, fmt.Errorf("invalid transaction index key %s", string(key))
+	}
+
+	blockIndex, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse block index of key %s", err, string(key))
+	}
+
+	operationIndex, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse operation index of key %s", err, string(key))
+	}
+
+	return &transactionKey{
+		blockIndex:      blockIndex,
+		transactionHash: parts[1],
+		operationIndex:  operationIndex,
+	}, nil
+}
+
+// operationEntry returns the *transactionEntry to store
+// for an operation.
+func (t *TransactionStorage) operationEntry(
+	blockIdentifier *types.BlockIdentifier,
+	op *types.Operation,
+) (*transactionEntry, error) {
+	successful, err := t.asserter.OperationSuccessful(op)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to determine if operation is successful", err)
+	}
+
+	entry := &transactionEntry{
+		BlockHash:  blockIdentifier.Hash,
+		Type:       op.Type,
+		Status:     op.Status,
+		Successful: successful,
+	}
+
+	if op.Account != nil {
+		entry.Address = op.Account.Address
+	}
+
+	if op.CoinChange != nil {
+		entry.Coin = op.CoinChange.CoinIdentifier.Identifier
+	}
+
+	return entry, nil
+}
+
+// updateIndex adds (or removes) all index
+// entries of a block.
+func (t *TransactionStorage) updateIndex(
+	ctx context.Context,
+	block *types.Block,
+	dbTx storage.DatabaseTransaction,
+	remove bool,
+) error {
+	for _, transaction := range block.Transactions {
+		for _, op := range transaction.Operations {
+			entry, err := t.operationEntry(block.BlockIdentifier, op)
+			if err != nil {
+				return err
+			}
+
+			key := &transactionKey{
+				blockIndex:      block.BlockIdentifier.Index,
+				transactionHash: transaction.TransactionIdentifier.Hash,
+				operationIndex:  op.OperationIdentifier.Index,
+			}
+
+			prefixes := []string{}
+			if len(entry.Address) > 0 {
+				prefixes = append(prefixes, getTransactionAccountPrefix(entry.Address))
+			}
+
+			if len(entry.Coin) > 0 {
+				prefixes = append(prefixes, getTransactionCoinPrefix(entry.Coin))
+			}
+
+			for _, prefix := range prefixes {
+				if err := t.updateEntry(ctx, dbTx, getTransactionKey(prefix, key), entry, remove); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *TransactionStorage) updateEntry(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	key []byte,
+	entry *transactionEntry,
+	remove bool,
+) error {
+	if remove {
+		if err := dbTx.Delete(ctx, key); err != nil {
+			return fmt.Errorf("%w: unable to delete transaction index entry %s", err, string(key))
+		}
+
+		return nil
+	}
+
+	encoded, err := t.db.Encoder().Encode(transactionEntryNamespace, entry)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode transaction index entry", err)
+	}
+
+	if err := dbTx.Set(ctx, key, encoded, true); err != nil {
+		return fmt.Errorf("%w: unable to store transaction index entry %s", err, string(key))
+	}
+
+	return nil
+}
+
+// markIndexStart records if the index is complete when
+// the first block is indexed. Only an index that starts
+// at the genesis block (which is its own parent) contains
+// the full history of each address and coin.
+func (t *TransactionStorage) markIndexStart(
+	ctx context.Context,
+	block *types.Block,
+	dbTx storage.DatabaseTransaction,
+) error {
+	exists, _, err := dbTx.Get(ctx, []byte(transactionIndexCompleteKey))
+	if err != nil {
+		return fmt.Errorf("%w: unable to get transaction index state", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	complete := types.Hash(block.BlockIdentifier) == types.Hash(block.ParentBlockIdentifier)
+	if err := dbTx.Set(
+		ctx,
+		[]byte(transactionIndexCompleteKey),
+		[]byte(strconv.FormatBool(complete)),
+		true,
+	); err != nil {
+		return fmt.Errorf("%w: unable to store transaction index state", err)
+	}
+
+	return nil
+}
+
+// indexComplete returns a boolean indicating if the index
+// contains the full history of each address and coin. An
+// empty index is complete.
+func (t *TransactionStorage) indexComplete(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+) (bool, error) {
+	exists, value, err := dbTx.Get(ctx, []byte(transactionIndexCompleteKey))
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to get transaction index state", err)
+	}
+
+	if !exists {
+		return true, nil
+	}
+
+	complete, err := strconv.ParseBool(string(value))
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to parse transaction index state", err)
+	}
+
+	return complete, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (t *TransactionStorage) AddingBlock(
+	ctx context.Context,
+	block *types.Block,
+	transaction storage.DatabaseTransaction,
+) (storage.CommitWorker, error) {
+	if err := t.markIndexStart(ctx, block, transaction); err != nil {
+		return nil, err
+	}
+
+	if err := t.updateIndex(ctx, block, transaction, false); err != nil {
+		return nil, fmt.Errorf("%w: unable to index transactions", err)
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (t *TransactionStorage) RemovingBlock(
+	ctx context.Context,
+	block *types.Block,
+	transaction storage.DatabaseTransaction,
+) (storage.CommitWorker, error) {
+	if err := t.updateIndex(ctx, block, transaction, true); err != nil {
+		return nil, fmt.Errorf("%w: unable to remove indexed transactions", err)
+	}
+
+	return nil, nil
+}
+
+// entryMatches returns a boolean indicating if an
+// indexed operation satisfies all query conditions.
+func entryMatches(entry *transactionEntry, query *search.TransactionQuery) bool {
+	if len(query.Address) > 0 && entry.Address != query.Address {
+		return false
+	}
+
+	if len(query.CoinIdentifier) > 0 && entry.Coin != query.CoinIdentifier {
+		return false
+	}
+
+	if len(query.OperationType) > 0 && entry.Type != query.OperationType {
+		return false
+	}
+
+	if len(query.Status) > 0 && entry.Status != query.Status {
+		return false
+	}
+
+	if query.Success != nil && entry.Successful != *query.Success {
+		return false
+	}
+
+	return true
+}
+
+// searchResults accumulates the results of
+// a search while respecting pagination.
+type searchResults struct {
+	query        *search.TransactionQuery
+	totalCount   int64
+	transactions []*types.BlockIdentifier
+	hashes       []string
+}
+
+func (s *searchResults) add(blockIdentifier *types.BlockIdentifier, hash string) {
+	if s.totalCount >= s.query.Offset && int64(len(s.hashes)) < s.query.Limit {
+		s.transactions = append(s.transactions, blockIdentifier)
+		s.hashes = append(s.hashes, hash)
+	}
+
+	s.totalCount++
+}
+
+// Search returns the transactions matching a query
+// (newest first) and the total number of matches.
+func (t *TransactionStorage) Search(
+	ctx context.Context,
+	query *search.TransactionQuery,
+) ([]*search.BlockTransaction, int64, error) {
+	dbTx := t.db.NewDatabaseTransaction(ctx, false)
+	defer dbTx.Discard(ctx)
+
+	if len(query.TransactionHash) == 0 {
+		complete, err := t.indexComplete(ctx, dbTx)
+		if err != nil {
+			return nil, -1, err
+		}
+
+		if !complete {
+			return nil, -1, ErrTransactionIndexIncomplete
+		}
+	}
+
+	results := &searchResults{query: query}
+	var err error
+	switch {
+	case len(query.TransactionHash) > 0:
+		err = t.searchTransaction(ctx, dbTx, results)
+	case len(query.CoinIdentifier) > 0:
+		err = t.scanIndex(ctx, dbTx, getTransactionCoinPrefix(query.CoinIdentifier), results)
+	case len(query.Address) > 0:
+		err = t.scanIndex(ctx, dbTx, getTransactionAccountPrefix(query.Address), results)
+	default:
+		err = ErrMissingSearchAnchor
+	}
+	if err != nil {
+		return nil, -1, err
+	}
+
+	transactions := make([]*search.BlockTransaction, len(results.hashes))
+	for j, hash := range results.hashes {
+		transaction, err := t.blockStorage.GetBlockTransaction(
+			ctx,
+			results.transactions[j],
+			&types.TransactionIdentifier{Hash: hash},
+		)
+		if err != nil {
+			return nil, -1, fmt.Errorf("%w: unable to get transaction %s", err, hash)
+		}
+
+		transactions[j] = &search.BlockTransaction{
+			BlockIdentifier: results.transactions[j],
+			Transaction:     transaction,
+		}
+	}
+
+	return transactions, results.totalCount, nil
+}
+
+// searchTransaction looks up a single transaction
+// by hash and checks it against the query.
+func (t *TransactionStorage) searchTransaction(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	results *searchResults,
+) error {
+	blockIdentifier, transaction, err := t.blockStorage.FindTransaction(
+		ctx,
+		&types.TransactionIdentifier{Hash: results.query.TransactionHash},
+		dbTx,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to find transaction %s", err, results.query.TransactionHash)
+	}
+
+	if transaction == nil {
+		return nil
+	}
+
+	if results.query.MaxBlock != nil && blockIdentifier.Index > *results.query.MaxBlock {
+		return nil
+	}
+
+	for _, op := range transaction.Operations {
+		entry, err := t.operationEntry(blockIdentifier, op)
+		if err != nil {
+			return err
+		}
+
+		if entryMatches(entry, results.query) {
+			results.add(blockIdentifier, transaction.TransactionIdentifier.Hash)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// scanIndex scans all index entries under a prefix from
+// the newest to the oldest block and adds each transaction
+// with at least one matching operation to the results.
+func (t *TransactionStorage) scanIndex(
+	ctx context.Context,
+	dbTx storage.DatabaseTransaction,
+	prefix string,
+	results *searchResults,
+) error {
+	// All entries of a transaction are adjacent, so
+	// we only need to remember the last one to avoid
+	// returning a transaction more than once.
+	var lastBlock *types.BlockIdentifier
+	var lastHash string
+	lastMatched := false
+
+	_, err := dbTx.Scan(
+		ctx,
+		[]byte(prefix),
+		getTransactionSeekStart(prefix, results.query.MaxBlock),
+		func(k []byte, v []byte) error {
+			key, err := parseTransactionKey(prefix, k)
+			if err != nil {
+				return err
+			}
+
+			var entry transactionEntry
+			if err := t.db.Encoder().Decode(transactionEntryNamespace, v, &entry, true); err != nil {
+				return fmt.Errorf("%w: unable to decode transaction index entry", err)
+			}
+
+			if lastBlock == nil ||
+				lastBlock.Index != key.blockIndex ||
+				lastHash != key.transactionHash {
+				lastBlock = &types.BlockIdentifier{
+					Hash:  entry.BlockHash,
+					Index: key.blockIndex,
+				}
+				lastHash = key.transactionHash
+				lastMatched = false
+			}
+
+			if lastMatched || !entryMatches(&entry, results.query) {
+				return nil
+			}
+
+			lastMatched = true
+			results.add(lastBlock, lastHash)
+
+			return nil
+		},
+		false,
+		true,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to scan transaction index", err)
+	}
+
+	return nil
+}
@@ -16,6 +16,8 @@ package indexer
 
 import (
 	"sync"
+
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
 )
 
 type waitTable struct {
@@ -60,6 +62,7 @@ func (t *waitTable) Set(key string, value *waitTableEntry, safe bool) {
 		defer t.lock.Unlock()
 	}
 	t.table[key] = value
+	metrics.WaitTableSize.Set(float64(len(t.table)))
 }
 
 func (t *waitTable) Delete(key string, safe bool) {
@@ -69,6 +72,7 @@ func (t *waitTable) Delete(key string, safe bool) {
 	}
 
 	delete(t.table, key)
+	metrics.WaitTableSize.Set(float64(len(t.table)))
 }
 
 type waitTableEntry struct {
@@ -22,10 +22,10 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
 	"github.com/HorizenOfficial/rosetta-zen/indexer"
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
 	"github.com/HorizenOfficial/rosetta-zen/services"
 	"github.com/HorizenOfficial/rosetta-zen/utils"
 	"github.com/HorizenOfficial/rosetta-zen/zen"
@@ -38,21 +38,6 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-const (
-	// readTimeout is the maximum duration for reading the entire
-	// request, including the body.
-	readTimeout = 5 * time.Second
-
-	// writeTimeout is the maximum duration before timing out
-	// writes of the response. It is reset whenever a new
-	// request's header is read.
-	writeTimeout = 15 * time.Second
-
-	// idleTimeout is the maximum amount of time to wait for the
-	// next request when keep-alives are enabled.
-	idleTimeout = 30 * time.Second
-)
-
 var (
 	signalReceived = false
 )
@@ -79,37 +64,96 @@ func startOnlineDependencies(
 	cancel context.CancelFunc,
 	cfg *configuration.Configuration,
 	g *errgroup.Group,
-) (*zen.Client, *indexer.Indexer, error) {
-	client := zen.NewClient(
-		zen.LocalhostURL(cfg.RPCPort),
-		cfg.GenesisBlockIdentifier,
-		cfg.Currency,
-	)
+) (*zen.Client, *indexer.Indexer, services.NodeProcess, error) {
+	var client *zen.Client
+	var node services.NodeProcess
+	if cfg.ExternalZend != nil {
+		var err error
+		client, err = newExternalClient(cfg)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: unable to create zend client", err)
+		}
 
-	g.Go(func() error {
-		return zen.StartZEND(ctx, cfg.ConfigPath, g)
-	})
+		// Ensure we never index a node running
+		// on a different network.
+		if err := client.CheckGenesis(ctx); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: unable to verify external zend", err)
+		}
+	} else {
+		client = zen.NewClient(
+			zen.LocalhostURL(cfg.RPCPort),
+			cfg.GenesisBlockIdentifier,
+			cfg.Currency,
+			zen.WithRetryPolicy(*cfg.RetryPolicy),
+			zen.WithCircuitBreaker(*cfg.CircuitBreaker),
+		)
+
+		supervisor := zen.NewSupervisor(cfg.ConfigPath, zen.DefaultSupervisorPolicy)
+		node = supervisor
+		g.Go(func() error {
+			return supervisor.Run(ctx)
+		})
+	}
 
 	i, err := indexer.Initialize(
 		ctx,
 		cancel,
 		cfg,
 		client,
+		indexer.WithNode(node),
 	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: unable to initialize indexer", err)
+		return nil, nil, nil, fmt.Errorf("%w: unable to initialize indexer", err)
 	}
 
 	g.Go(func() error {
 		return i.Sync(ctx)
 	})
 
+	g.Go(func() error {
+		return i.Rebroadcast(ctx)
+	})
+
+	g.Go(func() error {
+		return i.MonitorDatabaseSize(ctx)
+	})
+
 	//zend does not support manual pruning
 	//g.Go(func() error {
 	//	return i.Prune(ctx)
 	//})
 
-	return client, i, nil
+	return client, i, node, nil
+}
+
+// newExternalClient returns a *zen.Client connected
+// to the external zend in the configuration.
+func newExternalClient(cfg *configuration.Configuration) (*zen.Client, error) {
+	tlsConfig, err := cfg.ExternalZend.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []zen.ClientOption{
+		zen.WithTLSConfig(tlsConfig),
+		zen.WithRetryPolicy(*cfg.RetryPolicy),
+		zen.WithCircuitBreaker(*cfg.CircuitBreaker),
+	}
+	if len(cfg.ExternalZend.CookieFile) > 0 {
+		options = append(options, zen.WithCookieFile(cfg.ExternalZend.CookieFile))
+	} else {
+		options = append(
+			options,
+			zen.WithBasicAuth(cfg.ExternalZend.Username, cfg.ExternalZend.Password),
+		)
+	}
+
+	return zen.NewClient(
+		cfg.ExternalZend.URL,
+		cfg.GenesisBlockIdentifier,
+		cfg.Currency,
+		options...,
+	), nil
 }
 
 func main() {
@@ -134,6 +178,8 @@ func main() {
 		logger.Fatalw("unable to load configuration", "error", err)
 	}
 
+	// Secrets are redacted when the
+	// configuration is printed.
 	logger.Infow("loaded configuration", "configuration", types.PrintStruct(cfg))
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -144,8 +190,9 @@ func main() {
 
 	var i *indexer.Indexer
 	var client *zen.Client
+	var node services.NodeProcess
 	if cfg.Mode == configuration.Online {
-		client, i, err = startOnlineDependencies(ctx, cancel, cfg, g)
+		client, i, node, err = startOnlineDependencies(ctx, cancel, cfg, g)
 		if err != nil {
 			logger.Fatalw("unable to start online dependencies", "error", err)
 		}
@@ -163,15 +210,21 @@ func main() {
 		logger.Fatalw("unable to create new server asserter", "error", err)
 	}
 
-	router := services.NewBlockchainRouter(cfg, client, i, asserter)
+	router := services.NewBlockchainRouter(cfg, client, i, node, asserter)
 	loggedRouter := services.LoggerMiddleware(loggerRaw, router)
 	corsRouter := server.CorsMiddleware(loggedRouter)
+
+	// The metrics are served next to the Rosetta API
+	// but are not included in the API metrics.
+	mux := http.NewServeMux()
+	mux.Handle(metrics.MetricsPath, metrics.Handler())
+	mux.Handle("/", services.MetricsMiddleware(corsRouter))
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      corsRouter,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+		Handler:      mux,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
 
 	g.Go(func() error {
@@ -0,0 +1,162 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics contains the Prometheus metrics exported
+// by rosetta-zen on /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// namespace is prepended to the name of all metrics.
+	namespace = "rosetta_zen"
+
+	// MetricsPath is the path the metrics are served on.
+	MetricsPath = "/metrics"
+)
+
+var (
+	// IndexerHeadHeight is the index of the last block
+	// added to the indexer.
+	IndexerHeadHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "head_height",
+		Help:      "Index of the last block added to the indexer.",
+	})
+
+	// NodeTipHeight is the index of the current block
+	// of zend, as last seen by the indexer.
+	NodeTipHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "node",
+		Name:      "tip_height",
+		Help:      "Index of the current block of zend.",
+	})
+
+	// BlocksAdded counts the blocks added to the indexer.
+	// Its rate is the sync speed in blocks per second.
+	BlocksAdded = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "blocks_added_total",
+		Help:      "Number of blocks added to the indexer.",
+	})
+
+	// BlocksRemoved counts the blocks removed from the
+	// indexer during reorgs.
+	BlocksRemoved = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "blocks_removed_total",
+		Help:      "Number of blocks removed from the indexer.",
+	})
+
+	// Reorgs counts the reorgs handled by the indexer.
+	Reorgs = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "reorgs_total",
+		Help:      "Number of reorgs handled by the indexer.",
+	})
+
+	// ReorgDepth is the number of blocks removed
+	// by each reorg.
+	ReorgDepth = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "reorg_depth",
+		Help:      "Number of blocks removed by each reorg.",
+		Buckets:   []float64{1, 2, 3, 5, 10, 20, 50, 100},
+	})
+
+	// WaitTableSize is the number of transactions the
+	// indexer is waiting for to populate the inputs
+	// of a block.
+	WaitTableSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "wait_table_size",
+		Help:      "Number of transactions waited for by the indexer.",
+	})
+
+	// DatabaseSize is the size in bytes of the
+	// LSM tree ("lsm") and of the value log ("vlog")
+	// of the indexer database.
+	DatabaseSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "indexer",
+		Name:      "database_size_bytes",
+		Help:      "Size of the indexer database by type (lsm or vlog).",
+	}, []string{"type"})
+
+	// NodeRestarts counts the restarts of the
+	// zend daemon started by rosetta-zen.
+	NodeRestarts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "node",
+		Name:      "restarts_total",
+		Help:      "Number of restarts of the embedded zend.",
+	})
+
+	// RPCDuration is the latency of the JSON-RPC
+	// calls to zend, including retries.
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the JSON-RPC requests to zend by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// RPCErrors counts the JSON-RPC calls to zend
+	// that returned an error.
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Number of failed JSON-RPC requests to zend by method.",
+	}, []string{"method"})
+
+	// APIDuration is the latency of the Rosetta
+	// API requests.
+	APIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the Rosetta API requests by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	// APIErrors counts the Rosetta API requests
+	// that returned an error, by Rosetta error code.
+	APIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "errors_total",
+		Help:      "Number of failed Rosetta API requests by endpoint and error code.",
+	}, []string{"endpoint", "code"})
+)
+
+// Handler returns the http.Handler serving
+// the metrics in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -5,8 +5,12 @@ package services
 import (
 	context "context"
 
+	bitcoin "github.com/HorizenOfficial/rosetta-zen/zen"
+
 	mock "github.com/stretchr/testify/mock"
 
+	storage "github.com/coinbase/rosetta-sdk-go/storage"
+
 	types "github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -15,6 +19,29 @@ type Client struct {
 	mock.Mock
 }
 
+// BlockchainInfo provides a mock function with given fields: _a0
+func (_m *Client) BlockchainInfo(_a0 context.Context) (*bitcoin.BlockchainInfo, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *bitcoin.BlockchainInfo
+	if rf, ok := ret.Get(0).(func(context.Context) *bitcoin.BlockchainInfo); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.BlockchainInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPeers provides a mock function with given fields: _a0
 func (_m *Client) GetPeers(_a0 context.Context) ([]*types.Peer, error) {
 	ret := _m.Called(_a0)
@@ -38,6 +65,29 @@ func (_m *Client) GetPeers(_a0 context.Context) ([]*types.Peer, error) {
 	return r0, r1
 }
 
+// MempoolEntry provides a mock function with given fields: _a0, _a1
+func (_m *Client) MempoolEntry(_a0 context.Context, _a1 string) (*bitcoin.MempoolEntry, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *bitcoin.MempoolEntry
+	if rf, ok := ret.Get(0).(func(context.Context, string) *bitcoin.MempoolEntry); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.MempoolEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RawMempool provides a mock function with given fields: _a0
 func (_m *Client) RawMempool(_a0 context.Context) ([]string, error) {
 	ret := _m.Called(_a0)
@@ -146,3 +196,58 @@ func (_m *Client) GetHashFromIndex(_a0 context.Context, _a1 int64) (string, erro
 
 	return r0, r1
 }
+
+// GetRawTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Client) GetRawTransaction(_a0 context.Context, _a1 string) (*bitcoin.Transaction, []string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *bitcoin.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) *bitcoin.Transaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bitcoin.Transaction)
+		}
+	}
+
+	var r1 []string
+	if rf, ok := ret.Get(1).(func(context.Context, string) []string); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ParseTransaction provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Client) ParseTransaction(_a0 context.Context, _a1 *bitcoin.Transaction, _a2 map[string]*storage.AccountCoin) (*types.Transaction, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, *bitcoin.Transaction, map[string]*storage.AccountCoin) *types.Transaction); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *bitcoin.Transaction, map[string]*storage.AccountCoin) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -7,6 +7,10 @@ import (
 
 	bitcoin "github.com/HorizenOfficial/rosetta-zen/zen"
 
+	broadcast "github.com/HorizenOfficial/rosetta-zen/broadcast"
+
+	search "github.com/HorizenOfficial/rosetta-zen/search"
+
 	mock "github.com/stretchr/testify/mock"
 
 	types "github.com/coinbase/rosetta-sdk-go/types"
@@ -17,6 +21,41 @@ type Indexer struct {
 	mock.Mock
 }
 
+// CheckDatabase provides a mock function with given fields: _a0
+func (_m *Indexer) CheckDatabase(_a0 context.Context) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EstimateFeeRate provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) EstimateFeeRate(_a0 context.Context, _a1 int64) (float64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(context.Context, int64) float64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBalance provides a mock function with given fields: _a0, _a1, _a2, _a3
 func (_m *Indexer) GetBalance(_a0 context.Context, _a1 *types.AccountIdentifier, _a2 *types.Currency, _a3 *types.PartialBlockIdentifier) (*types.Amount, *types.BlockIdentifier, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3)
@@ -95,6 +134,61 @@ func (_m *Indexer) GetBlockTransaction(_a0 context.Context, _a1 *types.BlockIden
 	return r0, r1
 }
 
+// GetBroadcast provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetBroadcast(_a0 context.Context, _a1 string) (*broadcast.Transaction, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *broadcast.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) *broadcast.Transaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*broadcast.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoin provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) GetCoin(_a0 context.Context, _a1 *types.CoinIdentifier) (*types.Coin, *types.AccountIdentifier, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.Coin
+	if rf, ok := ret.Get(0).(func(context.Context, *types.CoinIdentifier) *types.Coin); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Coin)
+		}
+	}
+
+	var r1 *types.AccountIdentifier
+	if rf, ok := ret.Get(1).(func(context.Context, *types.CoinIdentifier) *types.AccountIdentifier); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*types.AccountIdentifier)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *types.CoinIdentifier) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetCoins provides a mock function with given fields: _a0, _a1
 func (_m *Indexer) GetCoins(_a0 context.Context, _a1 *types.AccountIdentifier) ([]*types.Coin, *types.BlockIdentifier, error) {
 	ret := _m.Called(_a0, _a1)
@@ -149,3 +243,47 @@ func (_m *Indexer) GetScriptPubKeys(_a0 context.Context, _a1 []*types.Coin) ([]*
 
 	return r0, r1
 }
+
+// SearchTransactions provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) SearchTransactions(_a0 context.Context, _a1 *search.TransactionQuery) ([]*search.BlockTransaction, int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*search.BlockTransaction
+	if rf, ok := ret.Get(0).(func(context.Context, *search.TransactionQuery) []*search.BlockTransaction); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*search.BlockTransaction)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, *search.TransactionQuery) int64); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *search.TransactionQuery) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// TrackTransaction provides a mock function with given fields: _a0, _a1
+func (_m *Indexer) TrackTransaction(_a0 context.Context, _a1 *broadcast.Transaction) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *broadcast.Transaction) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package search contains the types shared by the
+// indexer and the servicers to search the
+// transaction history.
+package search
+
+import (
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// TransactionQuery is used to search the
+// indexed transaction history. A transaction
+// matches if at least one of its operations
+// satisfies all populated conditions.
+type TransactionQuery struct {
+	TransactionHash string
+	Address         string
+	CoinIdentifier  string
+	OperationType   string
+	Status          string
+	Success         *bool
+	MaxBlock        *int64
+	Offset          int64
+	Limit           int64
+}
+
+// BlockTransaction contains a populated Transaction
+// and the BlockIdentifier that contains it.
+type BlockTransaction struct {
+	BlockIdentifier *types.BlockIdentifier `json:"block_identifier"`
+	Transaction     *types.Transaction     `json:"transaction"`
+}
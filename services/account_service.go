@@ -16,27 +16,48 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
 
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
 
+	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
-// AccountAPIService implements the server.AccountAPIServicer interface.
+// AccountAPIServicer extends server.AccountAPIServicer with
+// /account/coins, which the version of rosetta-sdk-go we use
+// does not provide.
+type AccountAPIServicer interface {
+	server.AccountAPIServicer
+	AccountCoins(
+		context.Context,
+		*AccountCoinsRequest,
+	) (*AccountCoinsResponse, *types.Error)
+}
+
+// AccountAPIService implements the AccountAPIServicer interface.
 type AccountAPIService struct {
-	config *configuration.Configuration
-	i      Indexer
+	config  *configuration.Configuration
+	client  Client
+	i       Indexer
+	mempool *MempoolCoins
 }
 
 // NewAccountAPIService returns a new *AccountAPIService.
 func NewAccountAPIService(
 	config *configuration.Configuration,
+	client Client,
 	i Indexer,
-) server.AccountAPIServicer {
+	mempool *MempoolCoins,
+) AccountAPIServicer {
 	return &AccountAPIService{
-		config: config,
-		i:      i,
+		config:  config,
+		client:  client,
+		i:       i,
+		mempool: mempool,
 	}
 }
 
@@ -97,3 +118,140 @@ func (s *AccountAPIService) AccountBalance(
 		},
 	}, nil
 }
+
+// AccountCoins implements /account/coins.
+func (s *AccountAPIService) AccountCoins(
+	ctx context.Context,
+	request *AccountCoinsRequest,
+) (*AccountCoinsResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	coins, block, err := s.i.GetCoins(ctx, request.AccountIdentifier)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	// We only index a single currency, so
+	// no coins can match any other currency.
+	if len(request.Currencies) > 0 && !s.supportsCurrency(request.Currencies) {
+		return &AccountCoinsResponse{
+			BlockIdentifier: block,
+			Coins:           []*types.Coin{},
+		}, nil
+	}
+
+	if request.IncludeMempool {
+		var rErr *types.Error
+		coins, rErr = s.mempoolCoins(ctx, request.AccountIdentifier, coins)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	return &AccountCoinsResponse{
+		BlockIdentifier: block,
+		Coins:           coins,
+	}, nil
+}
+
+// supportsCurrency returns true if currencies
+// contains the currency we index.
+func (s *AccountAPIService) supportsCurrency(currencies []*types.Currency) bool {
+	for _, currency := range currencies {
+		if types.Hash(currency) == types.Hash(s.config.Currency) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mempoolCoins applies the transactions in the mempool
+// to the confirmed coins of account. Coins spent by a mempool
+// transaction are removed and coins created for account
+// by a mempool transaction are added, so callers never select
+// a coin that is already used by a pending transaction.
+func (s *AccountAPIService) mempoolCoins(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	confirmed []*types.Coin,
+) ([]*types.Coin, *types.Error) {
+	spent, created, rErr := s.mempool.Changes(ctx, account)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return unspentCoins(append(confirmed, created...), spent), nil
+}
+
+// AccountCoinsAPIController binds http requests to an AccountAPIServicer
+// and writes the service results to the http response.
+type AccountCoinsAPIController struct {
+	service  AccountAPIServicer
+	asserter *asserter.Asserter
+}
+
+// NewAccountCoinsAPIController creates a default api controller.
+func NewAccountCoinsAPIController(
+	s AccountAPIServicer,
+	asserter *asserter.Asserter,
+) server.Router {
+	return &AccountCoinsAPIController{
+		service:  s,
+		asserter: asserter,
+	}
+}
+
+// Routes returns all of the api route for the AccountCoinsAPIController.
+func (c *AccountCoinsAPIController) Routes() server.Routes {
+	return server.Routes{
+		{
+			Name:        "AccountCoins",
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/account/coins",
+			HandlerFunc: c.AccountCoins,
+		},
+	}
+}
+
+// AccountCoins - Get an Account's Unspent Coins
+func (c *AccountCoinsAPIController) AccountCoins(w http.ResponseWriter, r *http.Request) {
+	accountCoinsRequest := &AccountCoinsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&accountCoinsRequest); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	// Assert that the request is for a supported network
+	if err := c.asserter.ValidSupportedNetwork(
+		accountCoinsRequest.NetworkIdentifier,
+	); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	if err := asserter.AccountIdentifier(accountCoinsRequest.AccountIdentifier); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	result, serviceErr := c.service.AccountCoins(r.Context(), accountCoinsRequest)
+	if serviceErr != nil {
+		server.EncodeJSONResponse(serviceErr, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	server.EncodeJSONResponse(result, http.StatusOK, w)
+}
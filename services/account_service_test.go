@@ -16,12 +16,14 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
 	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
 	"github.com/HorizenOfficial/rosetta-zen/zen"
 
+	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,8 +32,9 @@ func TestAccountBalance_Offline(t *testing.T) {
 	cfg := &configuration.Configuration{
 		Mode: configuration.Offline,
 	}
+	mockClient := &mocks.Client{}
 	mockIndexer := &mocks.Indexer{}
-	servicer := NewAccountAPIService(cfg, mockIndexer)
+	servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
 	ctx := context.Background()
 
 	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{})
@@ -46,8 +49,9 @@ func TestAccountBalance_Online_Current(t *testing.T) {
 		Mode:     configuration.Online,
 		Currency: zen.MainnetCurrency,
 	}
+	mockClient := &mocks.Client{}
 	mockIndexer := &mocks.Indexer{}
-	servicer := NewAccountAPIService(cfg, mockIndexer)
+	servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
 	ctx := context.Background()
 
 	account := &types.AccountIdentifier{
@@ -122,8 +126,9 @@ func TestAccountBalance_Online_Historical(t *testing.T) {
 		Mode:     configuration.Online,
 		Currency: zen.MainnetCurrency,
 	}
+	mockClient := &mocks.Client{}
 	mockIndexer := &mocks.Indexer{}
-	servicer := NewAccountAPIService(cfg, mockIndexer)
+	servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
 	ctx := context.Background()
 	account := &types.AccountIdentifier{
 		Address: "hello",
@@ -161,3 +166,248 @@ func TestAccountBalance_Online_Historical(t *testing.T) {
 
 	mockIndexer.AssertExpectations(t)
 }
+
+func TestAccountCoins_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+
+	coins, err := servicer.AccountCoins(ctx, &AccountCoinsRequest{})
+	assert.Nil(t, coins)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestAccountCoins_Online(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: zen.MainnetCurrency,
+	}
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+	other := &types.AccountIdentifier{
+		Address: "other",
+	}
+	block := &types.BlockIdentifier{
+		Index: 1000,
+		Hash:  "block 1000",
+	}
+	confirmed := []*types.Coin{
+		{
+			Amount: &types.Amount{
+				Value:    "10",
+				Currency: zen.MainnetCurrency,
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "tx1:0",
+			},
+		},
+		{
+			Amount: &types.Amount{
+				Value:    "15",
+				Currency: zen.MainnetCurrency,
+			},
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "tx1:1",
+			},
+		},
+	}
+
+	// tx2 spends tx1:0 and creates tx2:0 (ours) and
+	// tx2:1 (someone else's). tx3 spends tx2:0 and
+	// creates tx3:0 (ours).
+	rawTx2 := &zen.Transaction{
+		Hash: "tx2",
+		Outputs: []*zen.Output{
+			{Value: 0.00000005, Index: 0},
+			{Value: 0.00000004, Index: 1},
+		},
+	}
+	rawTx3 := &zen.Transaction{
+		Hash: "tx3",
+		Outputs: []*zen.Output{
+			{Value: 0.00000003, Index: 0},
+		},
+	}
+	createdOperation := func(coin string, owner *types.AccountIdentifier, value string) *types.Operation {
+		return &types.Operation{
+			Type:    zen.OutputOpType,
+			Account: owner,
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: zen.MainnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: coin},
+				CoinAction:     types.CoinCreated,
+			},
+		}
+	}
+	parsedTx2 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx2"},
+		Operations: []*types.Operation{
+			createdOperation("tx2:0", account, "5"),
+			createdOperation("tx2:1", other, "4"),
+		},
+	}
+	parsedTx3 := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx3"},
+		Operations: []*types.Operation{
+			createdOperation("tx3:0", account, "3"),
+		},
+	}
+
+	mockParseOutputs := func(
+		mockClient *mocks.Client,
+		ctx context.Context,
+		raw *zen.Transaction,
+		parsed *types.Transaction,
+	) {
+		mockClient.On(
+			"ParseTransaction",
+			ctx,
+			&zen.Transaction{
+				Hash:    raw.Hash,
+				Outputs: raw.Outputs,
+			},
+			map[string]*storage.AccountCoin{},
+		).Return(
+			parsed,
+			nil,
+		).Once()
+	}
+
+	t.Run("confirmed only", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+		ctx := context.Background()
+
+		mockIndexer.On("GetCoins", ctx, account).Return(confirmed, block, nil).Once()
+
+		coins, err := servicer.AccountCoins(ctx, &AccountCoinsRequest{
+			AccountIdentifier: account,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &AccountCoinsResponse{
+			BlockIdentifier: block,
+			Coins:           confirmed,
+		}, coins)
+
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+
+	t.Run("include mempool", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+		ctx := context.Background()
+
+		mockIndexer.On("GetCoins", ctx, account).Return(confirmed, block, nil).Once()
+		mockClient.On("RawMempool", ctx).Return([]string{"tx2", "gone", "tx3"}, nil).Once()
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			"tx2",
+		).Return(
+			rawTx2,
+			[]string{"tx1:0"},
+			nil,
+		).Once()
+		mockParseOutputs(mockClient, ctx, rawTx2, parsedTx2)
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			"gone",
+		).Return(
+			nil,
+			nil,
+			fmt.Errorf("%w: error fetching transaction", zen.ErrTransactionNotFound),
+		).Once()
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			"tx3",
+		).Return(
+			rawTx3,
+			[]string{"tx2:0"},
+			nil,
+		).Once()
+		mockParseOutputs(mockClient, ctx, rawTx3, parsedTx3)
+
+		coins, err := servicer.AccountCoins(ctx, &AccountCoinsRequest{
+			AccountIdentifier: account,
+			IncludeMempool:    true,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &AccountCoinsResponse{
+			BlockIdentifier: block,
+			Coins: []*types.Coin{
+				confirmed[1],
+				{
+					CoinIdentifier: &types.CoinIdentifier{Identifier: "tx3:0"},
+					Amount: &types.Amount{
+						Value:    "3",
+						Currency: zen.MainnetCurrency,
+					},
+				},
+			},
+		}, coins)
+
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+
+	t.Run("mempool unavailable", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+		ctx := context.Background()
+
+		mockIndexer.On("GetCoins", ctx, account).Return(confirmed, block, nil).Once()
+		mockClient.On("RawMempool", ctx).Return(nil, zen.ErrJSONRPCError).Once()
+
+		coins, err := servicer.AccountCoins(ctx, &AccountCoinsRequest{
+			AccountIdentifier: account,
+			IncludeMempool:    true,
+		})
+		assert.Nil(t, coins)
+		assert.Equal(t, ErrBitcoind.Code, err.Code)
+
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewAccountAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+		ctx := context.Background()
+
+		mockIndexer.On("GetCoins", ctx, account).Return(confirmed, block, nil).Once()
+
+		coins, err := servicer.AccountCoins(ctx, &AccountCoinsRequest{
+			AccountIdentifier: account,
+			IncludeMempool:    true,
+			Currencies: []*types.Currency{
+				{Symbol: "BTC", Decimals: 8},
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &AccountCoinsResponse{
+			BlockIdentifier: block,
+			Coins:           []*types.Coin{},
+		}, coins)
+
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+}
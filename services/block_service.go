@@ -55,8 +55,8 @@ func (s *BlockAPIService) Block(
 	}
 
 	// Direct client to fetch transactions individually if
-	// more than inlineFetchLimit.
-	if len(blockResponse.OtherTransactions) > inlineFetchLimit {
+	// more than the InlineFetchLimit.
+	if len(blockResponse.OtherTransactions) > s.config.InlineFetchLimit {
 		return blockResponse, nil
 	}
 
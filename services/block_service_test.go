@@ -49,7 +49,8 @@ func TestBlockService_Offline(t *testing.T) {
 
 func TestBlockService_Online_Inline(t *testing.T) {
 	cfg := &configuration.Configuration{
-		Mode: configuration.Online,
+		Mode:             configuration.Online,
+		InlineFetchLimit: 100,
 	}
 	mockIndexer := &mocks.Indexer{}
 	servicer := NewBlockAPIService(cfg, mockIndexer)
@@ -150,7 +151,8 @@ func TestBlockService_Online_Inline(t *testing.T) {
 
 func TestBlockService_Online_External(t *testing.T) {
 	cfg := &configuration.Configuration{
-		Mode: configuration.Online,
+		Mode:             configuration.Online,
+		InlineFetchLimit: 100,
 	}
 	mockIndexer := &mocks.Indexer{}
 	servicer := NewBlockAPIService(cfg, mockIndexer)
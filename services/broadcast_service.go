@@ -0,0 +1,148 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
+	"github.com/HorizenOfficial/rosetta-zen/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// BroadcastAPIServicer defines the api actions for the
+// transactions submitted through the Construction API.
+type BroadcastAPIServicer interface {
+	TransactionStatus(
+		context.Context,
+		*TransactionStatusRequest,
+	) (*TransactionStatusResponse, *types.Error)
+}
+
+// BroadcastAPIService implements the BroadcastAPIServicer interface.
+type BroadcastAPIService struct {
+	config *configuration.Configuration
+	i      Indexer
+}
+
+// NewBroadcastAPIService returns a new *BroadcastAPIService.
+func NewBroadcastAPIService(
+	config *configuration.Configuration,
+	i Indexer,
+) BroadcastAPIServicer {
+	return &BroadcastAPIService{
+		config: config,
+		i:      i,
+	}
+}
+
+// TransactionStatus implements /construction/status.
+func (s *BroadcastAPIService) TransactionStatus(
+	ctx context.Context,
+	request *TransactionStatusRequest,
+) (*TransactionStatusResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	if request.TransactionIdentifier == nil ||
+		len(request.TransactionIdentifier.Hash) == 0 {
+		return nil, wrapErr(
+			ErrTransactionNotTracked,
+			errors.New("transaction identifier must be provided"),
+		)
+	}
+
+	transaction, err := s.i.GetBroadcast(ctx, request.TransactionIdentifier.Hash)
+	if errors.Is(err, broadcast.ErrNotFound) {
+		return nil, wrapErr(ErrTransactionNotTracked, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetBroadcast, err)
+	}
+
+	return &TransactionStatusResponse{
+		Transaction: transaction,
+	}, nil
+}
+
+// BroadcastAPIController binds http requests to a BroadcastAPIServicer
+// and writes the service results to the http response.
+type BroadcastAPIController struct {
+	service  BroadcastAPIServicer
+	asserter *asserter.Asserter
+}
+
+// NewBroadcastAPIController creates a default api controller.
+func NewBroadcastAPIController(
+	s BroadcastAPIServicer,
+	asserter *asserter.Asserter,
+) server.Router {
+	return &BroadcastAPIController{
+		service:  s,
+		asserter: asserter,
+	}
+}
+
+// Routes returns all of the api route for the BroadcastAPIController.
+func (c *BroadcastAPIController) Routes() server.Routes {
+	return server.Routes{
+		{
+			Name:        "TransactionStatus",
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/construction/status",
+			HandlerFunc: c.TransactionStatus,
+		},
+	}
+}
+
+// TransactionStatus - Get the Status of a Submitted Transaction
+func (c *BroadcastAPIController) TransactionStatus(w http.ResponseWriter, r *http.Request) {
+	transactionStatusRequest := &TransactionStatusRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&transactionStatusRequest); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	// Assert that the request is for a supported network
+	if err := c.asserter.ValidSupportedNetwork(
+		transactionStatusRequest.NetworkIdentifier,
+	); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	result, serviceErr := c.service.TransactionStatus(r.Context(), transactionStatusRequest)
+	if serviceErr != nil {
+		server.EncodeJSONResponse(serviceErr, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	server.EncodeJSONResponse(result, http.StatusOK, w)
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
+	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionStatus_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewBroadcastAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	resp, err := servicer.TransactionStatus(ctx, &TransactionStatusRequest{})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestTransactionStatus_Online(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	ctx := context.Background()
+
+	conflicted := &broadcast.Transaction{
+		TransactionIdentifier:  &types.TransactionIdentifier{Hash: "tx 1"},
+		NetworkTransaction:     "010000",
+		Inputs:                 []string{"tx 0:0"},
+		Status:                 broadcast.Conflicted,
+		Broadcasts:             2,
+		BlockIdentifier:        &types.BlockIdentifier{Hash: "block 1", Index: 1},
+		ConflictingTransaction: &types.TransactionIdentifier{Hash: "tx 2"},
+	}
+
+	tests := map[string]struct {
+		hash         string
+		broadcast    *broadcast.Transaction
+		broadcastErr error
+
+		expected    *TransactionStatusResponse
+		expectedErr *types.Error
+	}{
+		"tracked": {
+			hash:      "tx 1",
+			broadcast: conflicted,
+			expected: &TransactionStatusResponse{
+				Transaction: conflicted,
+			},
+		},
+		"not tracked": {
+			hash:         "tx 3",
+			broadcastErr: fmt.Errorf("%w: tx 3", broadcast.ErrNotFound),
+			expectedErr:  ErrTransactionNotTracked,
+		},
+		"storage error": {
+			hash:         "tx 3",
+			broadcastErr: errors.New("database closed"),
+			expectedErr:  ErrUnableToGetBroadcast,
+		},
+		"missing hash": {
+			expectedErr: ErrTransactionNotTracked,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockIndexer := &mocks.Indexer{}
+			servicer := NewBroadcastAPIService(cfg, mockIndexer)
+
+			if len(test.hash) > 0 {
+				mockIndexer.On(
+					"GetBroadcast",
+					ctx,
+					test.hash,
+				).Return(
+					test.broadcast,
+					test.broadcastErr,
+				).Once()
+			}
+
+			resp, err := servicer.TransactionStatus(ctx, &TransactionStatusRequest{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: test.hash},
+			})
+			if test.expectedErr != nil {
+				assert.Nil(t, resp)
+				assert.Equal(t, test.expectedErr.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.expected, resp)
+			}
+
+			mockIndexer.AssertExpectations(t)
+		})
+	}
+}
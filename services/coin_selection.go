@@ -0,0 +1,307 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// coinSelectionLargestFirst spends the largest coins
+	// first. It minimizes the number of inputs and is used
+	// if no strategy is requested.
+	coinSelectionLargestFirst = "largest_first"
+
+	// coinSelectionBranchAndBound searches for a set of coins
+	// that pays the outputs and fee without a change output,
+	// falling back to largest first if there is none.
+	coinSelectionBranchAndBound = "branch_and_bound"
+
+	// coinSelectionPrivacy spends the smallest single coin
+	// that pays the outputs and fee, so coins are not merged
+	// and the balance of the account is not revealed. If no
+	// single coin is large enough, coins are drawn at random.
+	coinSelectionPrivacy = "privacy"
+
+	// maxBranchAndBoundTries is the number of branches
+	// the branch and bound search visits before giving up.
+	maxBranchAndBoundTries = 100000
+)
+
+// validCoinSelection returns true if strategy is a
+// supported coin selection strategy.
+func validCoinSelection(strategy string) bool {
+	switch strategy {
+	case "", coinSelectionLargestFirst, coinSelectionBranchAndBound, coinSelectionPrivacy:
+		return true
+	default:
+		return false
+	}
+}
+
+// coinSelection is a set of coins funding a transaction,
+// the fee it pays and the value of its change output
+// (0 if it has none).
+type coinSelection struct {
+	coins  []*types.Coin
+	fee    int64
+	change int64
+}
+
+// coinCandidate is a coin that can be selected and its
+// value in zatoshis.
+type coinCandidate struct {
+	coin  *types.Coin
+	value int64
+}
+
+// coinSelector selects coins to pay target zatoshis in
+// a transaction of baseSize vBytes before any inputs or
+// change output are added.
+//
+// When the transaction is a child paying for its parent,
+// parentSize and parentFee are those of the parent and the
+// fee brings the parent and child package to satoshisPerB.
+type coinSelector struct {
+	target       int64
+	baseSize     float64
+	satoshisPerB float64
+
+	parentSize float64
+	parentFee  int64
+}
+
+// fee returns the fee of the transaction with inputs inputs
+// and, if change is true, a change output. The fee never falls
+// below the minimum relay fee of the transaction itself.
+func (c *coinSelector) fee(inputs int, change bool) int64 {
+	size := c.baseSize + float64(inputs*zen.InputSize)
+	if change {
+		size += float64(zen.OutputOverhead + zen.P2PKHReplayScriptPubkeySize)
+	}
+
+	fee := int64(c.satoshisPerB*(c.parentSize+size)) - c.parentFee
+	minFee := int64(zen.MinFeeRate * float64(zen.SatoshisInBitcoin) / bytesInKb * size)
+	if fee < minFee {
+		return minFee
+	}
+
+	return fee
+}
+
+// finalize returns the coinSelection spending selected or
+// nil if selected does not pay the target and fee. Change
+// that would be dust is left to the fee.
+func (c *coinSelector) finalize(selected []*coinCandidate) *coinSelection {
+	total := int64(0)
+	coins := make([]*types.Coin, len(selected))
+	for i, candidate := range selected {
+		total += candidate.value
+		coins[i] = candidate.coin
+	}
+
+	if total < c.target+c.fee(len(selected), false) {
+		return nil
+	}
+
+	change := total - c.target - c.fee(len(selected), true)
+	if change >= zen.DustThreshold {
+		return &coinSelection{
+			coins:  coins,
+			fee:    c.fee(len(selected), true),
+			change: change,
+		}
+	}
+
+	return &coinSelection{
+		coins: coins,
+		fee:   total - c.target,
+	}
+}
+
+// accumulate selects candidates in order until
+// they pay the target and fee.
+func (c *coinSelector) accumulate(candidates []*coinCandidate) *coinSelection {
+	for i := range candidates {
+		if selection := c.finalize(candidates[:i+1]); selection != nil {
+			return selection
+		}
+	}
+
+	return nil
+}
+
+// largestFirst selects the largest candidates first.
+func (c *coinSelector) largestFirst(candidates []*coinCandidate) *coinSelection {
+	sortCandidates(candidates, false)
+
+	return c.accumulate(candidates)
+}
+
+// branchAndBound searches for the candidates whose value, net
+// of the fee to spend them, exceeds the target and base fee
+// by the least amount, and by less than the cost of creating
+// and later spending a change output. This is the algorithm
+// used by Bitcoin Core to avoid change outputs.
+func (c *coinSelector) branchAndBound(candidates []*coinCandidate) *coinSelection {
+	inputFee := int64(c.satoshisPerB * float64(zen.InputSize))
+	changeCost := c.fee(0, true) - c.fee(0, false) + inputFee
+	target := c.target + c.fee(0, false)
+
+	sortCandidates(candidates, false)
+	usable := []*coinCandidate{}
+	effective := []int64{}
+	available := int64(0)
+	for _, candidate := range candidates {
+		value := candidate.value - inputFee
+		if value <= 0 {
+			continue
+		}
+
+		usable = append(usable, candidate)
+		effective = append(effective, value)
+		available += value
+	}
+
+	var best []*coinCandidate
+	bestWaste := int64(-1)
+	selected := []*coinCandidate{}
+	tries := 0
+
+	var search func(i int, value int64, remaining int64)
+	search = func(i int, value int64, remaining int64) {
+		tries++
+		if tries > maxBranchAndBoundTries ||
+			value > target+changeCost ||
+			value+remaining < target {
+			return
+		}
+
+		if value >= target {
+			if waste := value - target; bestWaste < 0 || waste < bestWaste {
+				best = append([]*coinCandidate{}, selected...)
+				bestWaste = waste
+			}
+			return
+		}
+
+		if i == len(usable) {
+			return
+		}
+
+		selected = append(selected, usable[i])
+		search(i+1, value+effective[i], remaining-effective[i])
+		selected = selected[:len(selected)-1]
+		search(i+1, value, remaining-effective[i])
+	}
+	search(0, 0, available)
+
+	if best == nil {
+		return nil
+	}
+
+	return c.finalize(best)
+}
+
+// privacy selects the smallest candidate that pays the target
+// and fee on its own or, if there is none, draws candidates at
+// random.
+func (c *coinSelector) privacy(candidates []*coinCandidate) *coinSelection {
+	sortCandidates(candidates, true)
+	for _, candidate := range candidates {
+		if selection := c.finalize([]*coinCandidate{candidate}); selection != nil {
+			return selection
+		}
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) { // nolint:gosec
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	return c.accumulate(candidates)
+}
+
+// sortCandidates sorts candidates by value, breaking ties
+// by coin identifier so selection is deterministic.
+func sortCandidates(candidates []*coinCandidate, ascending bool) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].value == candidates[j].value {
+			return candidates[i].coin.CoinIdentifier.Identifier <
+				candidates[j].coin.CoinIdentifier.Identifier
+		}
+
+		if ascending {
+			return candidates[i].value < candidates[j].value
+		}
+
+		return candidates[i].value > candidates[j].value
+	})
+}
+
+// selectCoins selects coins using strategy to pay the
+// target and fee of selector.
+func selectCoins(
+	strategy string,
+	coins []*types.Coin,
+	selector *coinSelector,
+) (*coinSelection, error) {
+	candidates := []*coinCandidate{}
+	for _, coin := range coins {
+		value, err := strconv.ParseInt(coin.Amount.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse amount of coin %s",
+				err,
+				coin.CoinIdentifier.Identifier,
+			)
+		}
+
+		if value > 0 {
+			candidates = append(candidates, &coinCandidate{coin: coin, value: value})
+		}
+	}
+
+	var selection *coinSelection
+	switch strategy {
+	case "", coinSelectionLargestFirst:
+		selection = selector.largestFirst(candidates)
+	case coinSelectionBranchAndBound:
+		selection = selector.branchAndBound(candidates)
+		if selection == nil {
+			selection = selector.largestFirst(candidates)
+		}
+	case coinSelectionPrivacy:
+		selection = selector.privacy(candidates)
+	default:
+		return nil, fmt.Errorf("%s is not a valid coin selection strategy", strategy)
+	}
+
+	if selection == nil {
+		return nil, fmt.Errorf(
+			"%d coins cannot pay %d zatoshis and the fee",
+			len(candidates),
+			selector.target,
+		)
+	}
+
+	return selection, nil
+}
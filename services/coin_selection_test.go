@@ -0,0 +1,193 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func selectionCoin(identifier string, value int64) *types.Coin {
+	return &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: identifier},
+		Amount: &types.Amount{
+			Value:    fmt.Sprintf("%d", value),
+			Currency: zen.TestnetCurrency,
+		},
+	}
+}
+
+func TestSelectCoins(t *testing.T) {
+	// At 1 zatoshi per byte, the base fee is 82, each input
+	// costs 147 and a change output costs 72.
+	baseSize := float64(zen.TransactionOverhead + zen.OutputOverhead + zen.P2PKHReplayScriptPubkeySize)
+	target := int64(100000)
+	selector := &coinSelector{
+		target:       target,
+		baseSize:     baseSize,
+		satoshisPerB: 1,
+	}
+
+	small := selectionCoin("small", 10000)
+	a := selectionCoin("a", 60147)
+	b := selectionCoin("b", 40247)
+	medium := selectionCoin("medium", 200000)
+	large := selectionCoin("large", 500000)
+	exact := selectionCoin("exact", target+82+147+72+100)
+
+	tests := map[string]struct {
+		strategy string
+		coins    []*types.Coin
+
+		expected *coinSelection
+		err      bool
+	}{
+		"largest first": {
+			strategy: coinSelectionLargestFirst,
+			coins:    []*types.Coin{small, medium, large},
+			expected: &coinSelection{
+				coins:  []*types.Coin{large},
+				fee:    301,
+				change: 399699,
+			},
+		},
+		"largest first by default": {
+			coins: []*types.Coin{small, a, b},
+			expected: &coinSelection{
+				coins:  []*types.Coin{a, b},
+				fee:    394,
+				change: 0,
+			},
+		},
+		"dust change is left to the fee": {
+			strategy: coinSelectionLargestFirst,
+			coins:    []*types.Coin{exact},
+			expected: &coinSelection{
+				coins: []*types.Coin{exact},
+				fee:   82 + 147 + 72 + 100,
+			},
+		},
+		"branch and bound avoids change": {
+			strategy: coinSelectionBranchAndBound,
+			coins:    []*types.Coin{small, a, large, b},
+			expected: &coinSelection{
+				coins: []*types.Coin{a, b},
+				fee:   394,
+			},
+		},
+		"branch and bound falls back to largest first": {
+			strategy: coinSelectionBranchAndBound,
+			coins:    []*types.Coin{small, large},
+			expected: &coinSelection{
+				coins:  []*types.Coin{large},
+				fee:    301,
+				change: 399699,
+			},
+		},
+		"privacy spends the smallest single coin": {
+			strategy: coinSelectionPrivacy,
+			coins:    []*types.Coin{large, small, medium},
+			expected: &coinSelection{
+				coins:  []*types.Coin{medium},
+				fee:    301,
+				change: 99699,
+			},
+		},
+		"insufficient funds": {
+			strategy: coinSelectionLargestFirst,
+			coins:    []*types.Coin{small, b},
+			err:      true,
+		},
+		"no coins": {
+			strategy: coinSelectionPrivacy,
+			err:      true,
+		},
+		"invalid amount": {
+			coins: []*types.Coin{
+				{
+					CoinIdentifier: &types.CoinIdentifier{Identifier: "invalid"},
+					Amount:         &types.Amount{Value: "abc"},
+				},
+			},
+			err: true,
+		},
+		"invalid strategy": {
+			strategy: "smallest_first",
+			coins:    []*types.Coin{large},
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selection, err := selectCoins(test.strategy, test.coins, selector)
+			if test.err {
+				assert.Nil(t, selection)
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, selection)
+		})
+	}
+
+	t.Run("privacy draws coins at random", func(t *testing.T) {
+		coins := []*types.Coin{
+			selectionCoin("1", 60000),
+			selectionCoin("2", 60000),
+			selectionCoin("3", 60000),
+		}
+
+		selection, err := selectCoins(coinSelectionPrivacy, coins, selector)
+		assert.NoError(t, err)
+		assert.Len(t, selection.coins, 2)
+		assert.Equal(t, int64(82+2*147+72), selection.fee)
+		assert.Equal(t, int64(120000)-target-selection.fee, selection.change)
+	})
+
+	t.Run("child pays for parent", func(t *testing.T) {
+		child := &coinSelector{
+			target:       target,
+			baseSize:     baseSize,
+			satoshisPerB: 10,
+			parentSize:   226,
+			parentFee:    226,
+		}
+		assert.Equal(t, int64((226+301)*10-226), child.fee(1, true))
+
+		// A parent paying more than the target rate
+		// leaves the child at the minimum relay fee.
+		child.parentFee = 100000
+		assert.Equal(t, int64(301), child.fee(1, true))
+
+		selection, err := selectCoins(coinSelectionLargestFirst, []*types.Coin{large}, child)
+		assert.NoError(t, err)
+		assert.Equal(t, &coinSelection{
+			coins:  []*types.Coin{large},
+			fee:    301,
+			change: 399699,
+		}, selection)
+	})
+
+	assert.True(t, validCoinSelection(""))
+	assert.True(t, validCoinSelection(coinSelectionBranchAndBound))
+	assert.False(t, validCoinSelection("smallest_first"))
+}
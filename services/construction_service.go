@@ -24,13 +24,17 @@ import (
 	"math/big"
 	"strconv"
 
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
 	"github.com/HorizenOfficial/rosetta-zen/zen"
 
 	"github.com/HorizenOfficial/rosetta-zen/zend/btcec"
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg"
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
 	"github.com/HorizenOfficial/rosetta-zen/zend/txscript"
 	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
 	"github.com/HorizenOfficial/rosetta-zen/zenutil"
+	"github.com/HorizenOfficial/rosetta-zen/zenutil/hdkeychain"
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -44,13 +48,44 @@ const (
 	// defaultConfirmationTarget is the number of blocks we would
 	// like our transaction to be included by.
 	defaultConfirmationTarget = int64(2) // nolint:gomnd
+
+	// maxConfirmationTarget is the largest confirmation target
+	// supported by zend's fee estimator.
+	maxConfirmationTarget = int64(25) // nolint:gomnd
+
+	// feeSourceZend indicates that the fee rate was
+	// estimated by zend.
+	feeSourceZend = "zend"
+
+	// feeSourceIndexer indicates that the fee rate was
+	// estimated from the blocks stored in the indexer.
+	feeSourceIndexer = "indexer"
+
+	// feeSourceMinimum indicates that no estimate could
+	// be made and the minimum fee rate was used.
+	feeSourceMinimum = "minimum"
+
+	// scriptVerifyFlags are the zend standard script verification
+	// flags supported by txscript, used to verify signed transactions
+	// before they are broadcast.
+	scriptVerifyFlags = txscript.ScriptBip16 |
+		txscript.ScriptStrictMultiSig |
+		txscript.ScriptDiscourageUpgradableNops |
+		txscript.ScriptVerifyCheckLockTimeVerify |
+		txscript.ScriptVerifyCleanStack |
+		txscript.ScriptVerifyDERSignatures |
+		txscript.ScriptVerifyLowS |
+		txscript.ScriptVerifyMinimalData |
+		txscript.ScriptVerifyNullFail |
+		txscript.ScriptVerifyStrictEncoding
 )
 
 // ConstructionAPIService implements the server.ConstructionAPIServicer interface.
 type ConstructionAPIService struct {
-	config *configuration.Configuration
-	client Client
-	i      Indexer
+	config  *configuration.Configuration
+	client  Client
+	i       Indexer
+	mempool *MempoolCoins
 }
 
 // NewConstructionAPIService creates a new instance of a ConstructionAPIService.
@@ -58,19 +93,31 @@ func NewConstructionAPIService(
 	config *configuration.Configuration,
 	client Client,
 	i Indexer,
+	mempool *MempoolCoins,
 ) server.ConstructionAPIServicer {
 	return &ConstructionAPIService{
-		config: config,
-		client: client,
-		i:      i,
+		config:  config,
+		client:  client,
+		i:       i,
+		mempool: mempool,
 	}
 }
 
 // ConstructionDerive implements the /construction/derive endpoint.
+// When an xpub and path are provided in the metadata, the address
+// is derived from the child of the extended public key at path.
 func (s *ConstructionAPIService) ConstructionDerive(
 	ctx context.Context,
 	request *types.ConstructionDeriveRequest,
 ) (*types.ConstructionDeriveResponse, *types.Error) {
+	var metadata deriveMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+	if len(metadata.XPub) > 0 {
+		return s.deriveExtendedKey(request.PublicKey, &metadata)
+	}
+
 	addr, err := zenutil.NewAddressPubKeyHash(
 		zenutil.Hash160(request.PublicKey.Bytes),
 		s.config.Params,
@@ -86,40 +133,163 @@ func (s *ConstructionAPIService) ConstructionDerive(
 	}, nil
 }
 
+// deriveExtendedKey derives the pay-to-pubkey-hash address of the
+// child at metadata.Path below the extended public key metadata.XPub.
+// The public key of the request must be the key of the xpub itself,
+// so only non-hardened paths can be derived.
+func (s *ConstructionAPIService) deriveExtendedKey(
+	publicKey *types.PublicKey,
+	metadata *deriveMetadata,
+) (*types.ConstructionDeriveResponse, *types.Error) {
+	xpub, err := hdkeychain.NewKeyFromString(metadata.XPub)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf("%w: invalid xpub", err))
+	}
+	if xpub.IsPrivate() {
+		return nil, wrapErr(ErrUnableToDerive, errors.New("extended private keys are not accepted"))
+	}
+	if !xpub.IsForNet(s.config.Params) {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf("xpub is not for network %s", s.config.Params.Name))
+	}
+
+	xpubKey, err := xpub.ECPubKey()
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+	if !bytes.Equal(xpubKey.SerializeCompressed(), publicKey.Bytes) {
+		return nil, wrapErr(ErrUnableToDerive, errors.New("public key does not match xpub"))
+	}
+
+	path, err := hdkeychain.ParsePath(metadata.Path)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	child, err := xpub.DerivePath(path)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, fmt.Errorf("%w: unable to derive %s", err, metadata.Path))
+	}
+
+	addr, err := child.Address(s.config.Params)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	childKey, err := child.ECPubKey()
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDerive, err)
+	}
+
+	responseMetadata, err := types.MarshalMap(&deriveResponseMetadata{
+		PublicKey: hex.EncodeToString(childKey.SerializeCompressed()),
+		Path:      hdkeychain.FormatPath(path),
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: addr.EncodeAddress(),
+		},
+		Metadata: responseMetadata,
+	}, nil
+}
+
 // estimateSize returns the estimated size of a transaction in vBytes.
 func (s *ConstructionAPIService) estimateSize(operations []*types.Operation) float64 {
 	size := zen.TransactionOverhead
+	sidechain := false
 	for _, operation := range operations {
 		switch operation.Type {
 		case zen.InputOpType:
-			size += zen.InputSize
+			size += s.inputSize(operation)
+		case zen.ForwardTransferOpType:
+			size += zen.ForwardTransferSize
+			sidechain = true
 		case zen.OutputOpType:
-			size += zen.OutputOverhead
-			addr, err := zenutil.DecodeAddress(operation.Account.Address, s.config.Params)
-			if err != nil {
-				size += zen.P2PKHReplayScriptPubkeySize
-				continue
-			}
-			hashReplay, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
-			script, err := txscript.PayToAddrReplayOutScript(addr, hashReplay, 100)
-			if err != nil {
-				size += zen.P2PKHReplayScriptPubkeySize
-				continue
-			}
-
-			size += len(script)
+			size += zen.OutputOverhead + s.outputScriptSize(operation)
 		}
 	}
 
+	if sidechain {
+		size += zen.SidechainOverhead
+	}
+
 	return float64(size)
 }
 
+// outputScriptSize returns the estimated size of the locking script of
+// an output, which depends on the class of script paying to its
+// destination.
+func (s *ConstructionAPIService) outputScriptSize(operation *types.Operation) int {
+	if script, err := nullDataScript(operation); err == nil && script != nil {
+		return len(script)
+	}
+	if operation.Account == nil {
+		return zen.P2PKHReplayScriptPubkeySize
+	}
+
+	addr, err := zenutil.DecodeAddress(operation.Account.Address, s.config.Params)
+	if err != nil {
+		return zen.P2PKHReplayScriptPubkeySize
+	}
+
+	switch addr := addr.(type) {
+	case *zenutil.AddressScriptHash:
+		return zen.P2SHReplayScriptPubkeySize
+	case *zenutil.AddressPubKey:
+		// pubkey push and OP_CHECKSIG
+		return 1 + len(addr.ScriptAddress()) + 1 + zen.ReplayProtectionSize
+	default:
+		return zen.P2PKHReplayScriptPubkeySize
+	}
+}
+
+// inputSize returns the estimated size of an input. Inputs spending
+// pay-to-script-hash multisig coins are sized from the redeem script
+// in their metadata.
+func (s *ConstructionAPIService) inputSize(operation *types.Operation) int {
+	var metadata zen.OperationMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil ||
+		len(metadata.RedeemScript) == 0 {
+		return zen.InputSize
+	}
+
+	redeemScript, err := hex.DecodeString(metadata.RedeemScript)
+	if err != nil {
+		return zen.InputSize
+	}
+
+	_, _, nRequired, err := txscript.ExtractPkScriptAddrs(redeemScript, s.config.Params)
+	if err != nil {
+		return zen.InputSize
+	}
+
+	// OP_0, the signatures and the redeem script push
+	scriptSize := 1 + nRequired*zen.MultiSigSignatureSize
+	pushedRedeemScript, err := txscript.NewScriptBuilder().AddData(redeemScript).Script()
+	if err != nil {
+		return zen.InputSize
+	}
+	scriptSize += len(pushedRedeemScript)
+
+	return zen.InputOverhead + wire.VarIntSerializeSize(uint64(scriptSize)) + scriptSize
+}
+
 // ConstructionPreprocess implements the /construction/preprocess
 // endpoint.
 func (s *ConstructionAPIService) ConstructionPreprocess(
 	ctx context.Context,
 	request *types.ConstructionPreprocessRequest,
 ) (*types.ConstructionPreprocessResponse, *types.Error) {
+	var metadata preprocessMetadata
+	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	// Inputs are selected by ConstructionMetadata
+	// when a funding account is provided.
 	descriptions := &parser.Descriptions{
 		OperationDescriptions: []*parser.OperationDescription{
 			{
@@ -134,6 +304,7 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 				},
 				CoinAction:   types.CoinSpent,
 				AllowRepeats: true,
+				Optional:     metadata.FundingAccount != nil,
 			},
 		},
 	}
@@ -143,23 +314,69 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 		return nil, wrapErr(ErrUnclearIntent, err)
 	}
 
-	coins := make([]*types.Coin, len(matches[0].Operations))
-	for i, input := range matches[0].Operations {
-		if input.CoinChange == nil {
-			return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
+	// When no confirmation target is provided,
+	// ConstructionMetadata uses the default.
+	var confirmationTarget int64
+	if metadata.ConfirmationTarget != nil {
+		confirmationTarget = *metadata.ConfirmationTarget
+		if confirmationTarget < 1 || confirmationTarget > maxConfirmationTarget {
+			return nil, wrapErr(ErrUnclearIntent, fmt.Errorf(
+				"confirmation target %d is not between 1 and %d",
+				confirmationTarget,
+				maxConfirmationTarget,
+			))
+		}
+	}
+
+	switch metadata.FeeEstimationSource {
+	case "", feeSourceZend, feeSourceIndexer:
+	default:
+		return nil, wrapErr(ErrUnclearIntent, fmt.Errorf(
+			"%s is not a valid fee estimation source",
+			metadata.FeeEstimationSource,
+		))
+	}
+
+	if !validCoinSelection(metadata.CoinSelection) {
+		return nil, wrapErr(ErrUnclearIntent, fmt.Errorf(
+			"%s is not a valid coin selection strategy",
+			metadata.CoinSelection,
+		))
+	}
+
+	preprocessOptions := &preprocessOptions{
+		Coins:               []*types.Coin{},
+		EstimatedSize:       s.estimateSize(request.Operations),
+		FeeMultiplier:       request.SuggestedFeeMultiplier,
+		ConfirmationTarget:  confirmationTarget,
+		FeeEstimationSource: metadata.FeeEstimationSource,
+	}
+
+	if metadata.FundingAccount != nil {
+		if rErr := s.fundingOptions(request.Operations, &metadata, preprocessOptions); rErr != nil {
+			return nil, rErr
+		}
+	} else {
+		if len(metadata.CoinSelection) > 0 || metadata.BumpTransaction != nil {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				errors.New("coin selection requires a funding account"),
+			)
 		}
 
-		coins[i] = &types.Coin{
-			CoinIdentifier: input.CoinChange.CoinIdentifier,
-			Amount:         input.Amount,
+		for _, input := range matches[0].Operations {
+			if input.CoinChange == nil {
+				return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
+			}
+
+			preprocessOptions.Coins = append(preprocessOptions.Coins, &types.Coin{
+				CoinIdentifier: input.CoinChange.CoinIdentifier,
+				Amount:         input.Amount,
+			})
 		}
 	}
 
-	options, err := types.MarshalMap(&preprocessOptions{
-		Coins:         coins,
-		EstimatedSize: s.estimateSize(request.Operations),
-		FeeMultiplier: request.SuggestedFeeMultiplier,
-	})
+	options, err := types.MarshalMap(preprocessOptions)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
@@ -169,6 +386,65 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	}, nil
 }
 
+// fundingOptions populates options for a transaction funded
+// with coins selected from metadata.FundingAccount. The funding
+// account must be a pay-to-pubkey-hash address, because we cannot
+// sign for other coins without more information about them.
+func (s *ConstructionAPIService) fundingOptions(
+	operations []*types.Operation,
+	metadata *preprocessMetadata,
+	options *preprocessOptions,
+) *types.Error {
+	addr, err := zenutil.DecodeAddress(metadata.FundingAccount.Address, s.config.Params)
+	if err != nil {
+		return wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
+			"%w unable to decode address %s",
+			err,
+			metadata.FundingAccount.Address,
+		))
+	}
+	if _, ok := addr.(*zenutil.AddressPubKeyHash); !ok {
+		return wrapErr(
+			ErrUnclearIntent,
+			errors.New("funding account must be a pay-to-pubkey-hash address"),
+		)
+	}
+
+	amount := "0"
+	for _, operation := range operations {
+		if operation.Type == zen.InputOpType {
+			return wrapErr(
+				ErrUnclearIntent,
+				errors.New("inputs cannot be provided with a funding account"),
+			)
+		}
+
+		if operation.Amount == nil {
+			continue
+		}
+
+		amount, err = types.AddValues(amount, operation.Amount.Value)
+		if err != nil {
+			return wrapErr(ErrUnclearIntent, err)
+		}
+	}
+
+	if metadata.BumpTransaction != nil && metadata.FeeRate <= 0 {
+		return wrapErr(
+			ErrUnclearIntent,
+			errors.New("a positive fee rate is required to bump a transaction"),
+		)
+	}
+
+	options.FundingAccount = metadata.FundingAccount
+	options.CoinSelection = metadata.CoinSelection
+	options.Amount = amount
+	options.BumpTransaction = metadata.BumpTransaction
+	options.FeeRate = metadata.FeeRate
+
+	return nil
+}
+
 // ConstructionMetadata implements the /construction/metadata endpoint.
 func (s *ConstructionAPIService) ConstructionMetadata(
 	ctx context.Context,
@@ -183,14 +459,28 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	// Determine feePerKB and ensure it is not below the minimum fee
-	// relay rate.
-	feePerKB, err := s.client.SuggestedFeeRate(ctx, defaultConfirmationTarget)
-	if err != nil {
-		return nil, wrapErr(ErrCouldNotGetFeeRate, err)
+	confirmationTarget := options.ConfirmationTarget
+	if confirmationTarget == 0 {
+		confirmationTarget = defaultConfirmationTarget
 	}
-	if options.FeeMultiplier != nil {
-		feePerKB *= *options.FeeMultiplier
+
+	// Determine feePerKB and ensure it is not below the minimum fee
+	// relay rate. When bumping a transaction, the fee rate of the
+	// package is requested explicitly.
+	feePerKB, feeSource := options.FeeRate, ""
+	if options.BumpTransaction == nil {
+		var err error
+		feePerKB, feeSource, err = s.suggestedFeeRate(
+			ctx,
+			confirmationTarget,
+			options.FeeEstimationSource,
+		)
+		if err != nil {
+			return nil, wrapClientErr(ErrCouldNotGetFeeRate, err)
+		}
+		if options.FeeMultiplier != nil {
+			feePerKB *= *options.FeeMultiplier
+		}
 	}
 	if feePerKB < zen.MinFeeRate {
 		feePerKB = zen.MinFeeRate
@@ -204,19 +494,64 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		Currency: s.config.Currency,
 	}
 
-	scripts, err := s.i.GetScriptPubKeys(ctx, options.Coins)
-	if err != nil {
-		return nil, wrapErr(ErrScriptPubKeysMissing, err)
+	coins := options.Coins
+	var scripts []*zen.ScriptPubKey
+	var selected []*types.Coin
+	var change *types.Amount
+	if options.FundingAccount != nil {
+		var selection *coinSelection
+		var rErr *types.Error
+		if options.BumpTransaction != nil {
+			selection, scripts, rErr = s.bumpSelection(ctx, &options, satoshisPerB)
+		} else {
+			selection, rErr = s.selectCoins(ctx, &options, satoshisPerB)
+		}
+		if rErr != nil {
+			return nil, rErr
+		}
+
+		coins = selection.coins
+		selected = selection.coins
+		suggestedFee.Value = fmt.Sprintf("%d", selection.fee)
+		if selection.change > 0 {
+			change = &types.Amount{
+				Value:    fmt.Sprintf("%d", selection.change),
+				Currency: s.config.Currency,
+			}
+		}
+	}
+
+	// The outputs of a transaction being bumped are
+	// not yet known to the indexer.
+	if scripts == nil {
+		var err error
+		scripts, err = s.i.GetScriptPubKeys(ctx, coins)
+		if err != nil {
+			return nil, wrapErr(ErrScriptPubKeysMissing, err)
+		}
 	}
 
 	// Determine the blockhash for the replay protection
 	bestblockHash, err := s.client.GetBestBlock(ctx)
 	if err != nil {
-		return nil, wrapErr(ErrCouldNotGetBestBlock, err)
+		return nil, wrapClientErr(ErrCouldNotGetBestBlock, err)
 	}
 	hashReplay, err := s.client.GetHashFromIndex(ctx, bestblockHash-100)
+	if err != nil {
+		return nil, wrapClientErr(ErrCouldNotGetBestBlock, err)
+	}
 
-	metadata, err := types.MarshalMap(&constructionMetadata{ScriptPubKeys: scripts, ReplayBlockHeight: bestblockHash - 100, ReplayBlockHash: hashReplay})
+	metadata, err := types.MarshalMap(&constructionMetadata{
+		ScriptPubKeys:       scripts,
+		ReplayBlockHeight:   bestblockHash - 100,
+		ReplayBlockHash:     hashReplay,
+		FeeRate:             feePerKB,
+		ConfirmationTarget:  confirmationTarget,
+		FeeEstimationSource: feeSource,
+		Coins:               selected,
+		FundingAccount:      options.FundingAccount,
+		Change:              change,
+	})
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
@@ -227,6 +562,181 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 	}, nil
 }
 
+// bumpSelection selects the outputs of options.BumpTransaction
+// paying options.FundingAccount, so a child spending them pays
+// for its parent and the package reaches satoshisPerB. It also
+// returns the scripts of the selected outputs.
+func (s *ConstructionAPIService) bumpSelection(
+	ctx context.Context,
+	options *preprocessOptions,
+	satoshisPerB float64,
+) (*coinSelection, []*zen.ScriptPubKey, *types.Error) {
+	hash := options.BumpTransaction.Hash
+	entry, err := s.client.MempoolEntry(ctx, hash)
+	if errors.Is(err, zen.ErrTransactionNotFound) {
+		return nil, nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, nil, wrapClientErr(ErrBitcoind, err)
+	}
+
+	parentFee, err := zenutil.NewAmount(entry.Fee)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	target, err := strconv.ParseInt(options.Amount, 10, 64)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	rawParent, _, err := s.client.GetRawTransaction(ctx, hash)
+	if errors.Is(err, zen.ErrTransactionNotFound) {
+		return nil, nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, nil, wrapClientErr(ErrBitcoind, err)
+	}
+
+	parent, err := parseOutputs(ctx, s.client, rawParent)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	spent, _, rErr := s.mempool.Changes(ctx, options.FundingAccount)
+	if rErr != nil {
+		return nil, nil, rErr
+	}
+
+	coins := []*types.Coin{}
+	for _, op := range parent.Operations {
+		if op.CoinChange == nil || op.CoinChange.CoinAction != types.CoinCreated {
+			continue
+		}
+
+		if op.Account == nil || types.Hash(op.Account) != types.Hash(options.FundingAccount) {
+			continue
+		}
+
+		coins = append(coins, &types.Coin{
+			CoinIdentifier: op.CoinChange.CoinIdentifier,
+			Amount:         op.Amount,
+		})
+	}
+
+	coins = unspentCoins(coins, spent)
+	if len(coins) == 0 {
+		return nil, nil, wrapErr(ErrInsufficientFunds, fmt.Errorf(
+			"transaction %s has no unspent outputs paying %s",
+			hash,
+			options.FundingAccount.Address,
+		))
+	}
+
+	selection, err := selectCoins(
+		options.CoinSelection,
+		coins,
+		&coinSelector{
+			target:       target,
+			baseSize:     options.EstimatedSize,
+			satoshisPerB: satoshisPerB,
+			parentSize:   float64(entry.Size),
+			parentFee:    int64(parentFee),
+		},
+	)
+	if err != nil {
+		return nil, nil, wrapErr(ErrInsufficientFunds, err)
+	}
+
+	outputScripts := map[string]*zen.ScriptPubKey{}
+	for _, output := range rawParent.Outputs {
+		outputScripts[zen.CoinIdentifier(hash, output.Index)] = output.ScriptPubKey
+	}
+
+	scripts := make([]*zen.ScriptPubKey, len(selection.coins))
+	for i, coin := range selection.coins {
+		scripts[i] = outputScripts[coin.CoinIdentifier.Identifier]
+	}
+
+	return selection, scripts, nil
+}
+
+// selectCoins selects the coins of options.FundingAccount that
+// pay options.Amount and the fee at satoshisPerB. Coins spent by
+// transactions in the mempool are never selected.
+func (s *ConstructionAPIService) selectCoins(
+	ctx context.Context,
+	options *preprocessOptions,
+	satoshisPerB float64,
+) (*coinSelection, *types.Error) {
+	target, err := strconv.ParseInt(options.Amount, 10, 64)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	coins, _, err := s.i.GetCoins(ctx, options.FundingAccount)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	spent, _, rErr := s.mempool.Changes(ctx, options.FundingAccount)
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	selection, err := selectCoins(
+		options.CoinSelection,
+		unspentCoins(coins, spent),
+		&coinSelector{
+			target:       target,
+			baseSize:     options.EstimatedSize,
+			satoshisPerB: satoshisPerB,
+		},
+	)
+	if err != nil {
+		return nil, wrapErr(ErrInsufficientFunds, err)
+	}
+
+	return selection, nil
+}
+
+// suggestedFeeRate returns the fee rate (in ZEN per kB) needed to
+// confirm a transaction within confTarget blocks and the source of
+// the estimate. If no source is requested, zend's estimate is used
+// and the indexer is only consulted when zend does not have enough
+// data. If no estimate can be made, we fall back to zen.MinFeeRate.
+func (s *ConstructionAPIService) suggestedFeeRate(
+	ctx context.Context,
+	confTarget int64,
+	source string,
+) (float64, string, error) {
+	if source != feeSourceIndexer {
+		feeRate, err := s.client.SuggestedFeeRate(ctx, confTarget)
+		if err == nil {
+			return feeRate, feeSourceZend, nil
+		}
+
+		if !errors.Is(err, zen.ErrInsufficientFeeData) {
+			return -1, "", err
+		}
+
+		if source == feeSourceZend {
+			return zen.MinFeeRate, feeSourceMinimum, nil
+		}
+	}
+
+	feeRate, err := s.i.EstimateFeeRate(ctx, confTarget)
+	if err == nil {
+		return feeRate, feeSourceIndexer, nil
+	}
+
+	if !errors.Is(err, zen.ErrInsufficientFeeData) {
+		return -1, "", err
+	}
+
+	return zen.MinFeeRate, feeSourceMinimum, nil
+}
+
 // ConstructionPayloads implements the /construction/payloads endpoint.
 func (s *ConstructionAPIService) ConstructionPayloads(
 	ctx context.Context,
@@ -248,31 +758,66 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 				CoinAction:   types.CoinSpent,
 			},
 			{
+				// Accounts and amounts of outputs are checked below
+				// because OP_RETURN outputs have neither an account
+				// nor a positive amount.
 				Type: zen.OutputOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     parser.AnyAmountSign,
+					Currency: s.config.Currency,
 				},
+				AllowRepeats: true,
+				Optional:     true,
+			},
+			{
+				// The amount of a forward transfer is credited on the
+				// sidechain, so its account is checked below.
+				Type: zen.ForwardTransferOpType,
 				Amount: &parser.AmountDescription{
 					Exists:   true,
 					Sign:     parser.PositiveAmountSign,
 					Currency: s.config.Currency,
 				},
 				AllowRepeats: true,
+				Optional:     true,
 			},
 		},
 		ErrUnmatched: true,
 	}
 
-	matches, err := parser.MatchOperations(descriptions, request.Operations)
-	if err != nil {
-		return nil, wrapErr(ErrUnclearIntent, err)
-	}
 	var metadata constructionMetadata
 	if err := types.UnmarshalMap(request.Metadata, &metadata); err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	tx := wire.NewMsgTx(wire.TxVersion)
+	operations := request.Operations
+	if metadata.FundingAccount != nil {
+		var rErr *types.Error
+		operations, rErr = fundedOperations(operations, &metadata)
+		if rErr != nil {
+			return nil, rErr
+		}
+	}
+
+	matches, err := parser.MatchOperations(descriptions, operations)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+	if matches[1] == nil && matches[2] == nil {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			errors.New("at least one output or forward transfer is required"),
+		)
+	}
+
+	// Forward transfers can only be carried by sidechain transactions.
+	version := int32(wire.TxVersion)
+	if matches[2] != nil {
+		version = wire.SidechainTxVersion
+	}
+
+	tx := wire.NewMsgTx(version)
 	for _, input := range matches[0].Operations {
 		if input.CoinChange == nil {
 			return nil, wrapErr(ErrUnclearIntent, errors.New("CoinChange cannot be nil"))
@@ -293,7 +838,48 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		})
 	}
 
-	for i, output := range matches[1].Operations {
+	var outputs []*types.Operation
+	if matches[1] != nil {
+		outputs = matches[1].Operations
+	}
+
+	for i, output := range outputs {
+		amount := matches[1].Amounts[i]
+		dataScript, err := nullDataScript(output)
+		if err != nil {
+			return nil, wrapErr(ErrUnclearIntent, err)
+		}
+		if dataScript != nil {
+			if amount.Sign() != 0 {
+				return nil, wrapErr(
+					ErrUnclearIntent,
+					errors.New("outputs with data must have a zero amount"),
+				)
+			}
+
+			// OP_RETURN outputs are unspendable, so an account
+			// would never be credited.
+			if output.Account != nil {
+				return nil, wrapErr(
+					ErrUnclearIntent,
+					errors.New("outputs with data cannot have an account"),
+				)
+			}
+
+			tx.AddTxOut(&wire.TxOut{
+				Value:    0,
+				PkScript: dataScript,
+			})
+			continue
+		}
+
+		if output.Account == nil || amount.Sign() != 1 {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				errors.New("outputs must have an account and a positive amount"),
+			)
+		}
+
 		addr, err := zenutil.DecodeAddress(output.Account.Address, s.config.Params)
 		if err != nil {
 			return nil, wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
@@ -314,80 +900,497 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		}
 
 		tx.AddTxOut(&wire.TxOut{
-			Value:    matches[1].Amounts[i].Int64(),
+			Value:    amount.Int64(),
 			PkScript: pkScript,
 		})
 	}
 
+	if matches[2] != nil {
+		for i, transfer := range matches[2].Operations {
+			ftOut, err := s.forwardTransferOutput(transfer, matches[2].Amounts[i].Int64())
+			if err != nil {
+				return nil, err
+			}
+
+			tx.TxForwardTransferOut = append(tx.TxForwardTransferOut, ftOut)
+		}
+	}
+
 	// Create Signing Payloads (must be done after entire tx is constructed
 	// or hash will not be correct).
 	inputAmounts := make([]string, len(tx.TxIn))
 	inputAddresses := make([]string, len(tx.TxIn))
-	payloads := make([]*types.SigningPayload, len(tx.TxIn))
+	redeemScripts := make([]string, len(tx.TxIn))
+	payloads := []*types.SigningPayload{}
+	p2sh := false
+
+	for i := range tx.TxIn {
+		address := matches[0].Operations[i].Account.Address
+		script, err := hex.DecodeString(metadata.ScriptPubKeys[i].Hex)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
+		}
+		class, addr, err := zen.ParseSingleAddress(s.config.Params, script)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnableToDecodeAddress,
+				fmt.Errorf("%w unable to parse address for utxo %d", err, i),
+			)
+		}
+
+		inputAddresses[i] = address
+		inputAmounts[i] = matches[0].Amounts[i].String()
+
+		switch class {
+		case txscript.PubKeyHashReplayOutTy, txscript.PubKeyHashTy:
+			hash, err := txscript.CalcSignatureHash(
+				script,
+				txscript.SigHashAll,
+				tx,
+				i,
+			)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
+			}
+
+			payloads = append(payloads, &types.SigningPayload{
+				AccountIdentifier: &types.AccountIdentifier{
+					Address: address,
+				},
+				Bytes:         hash,
+				SignatureType: types.Ecdsa,
+			})
+		case txscript.ScriptHashReplayOutTy, txscript.ScriptHashTy:
+			redeemScript, signers, rerr := s.multiSigInput(matches[0].Operations[i], addr)
+			if rerr != nil {
+				return nil, rerr
+			}
+
+			// Pay-to-script-hash inputs sign the redeem script.
+			hash, err := txscript.CalcSignatureHash(
+				redeemScript,
+				txscript.SigHashAll,
+				tx,
+				i,
+			)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
+			}
+
+			for _, signer := range signers {
+				payloads = append(payloads, &types.SigningPayload{
+					AccountIdentifier: &types.AccountIdentifier{
+						Address: signer.AddressPubKeyHash().EncodeAddress(),
+					},
+					Bytes:         hash,
+					SignatureType: types.Ecdsa,
+				})
+			}
+
+			redeemScripts[i] = hex.EncodeToString(redeemScript)
+			p2sh = true
+		default:
+			return nil, wrapErr(
+				ErrUnsupportedScriptType,
+				fmt.Errorf("unupported script type: %s", class),
+			)
+		}
+	}
+
+	if !p2sh {
+		redeemScripts = nil
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	rawTx, err := json.Marshal(&unsignedTransaction{
+		Transaction:    hex.EncodeToString(buf.Bytes()),
+		ScriptPubKeys:  metadata.ScriptPubKeys,
+		InputAmounts:   inputAmounts,
+		InputAddresses: inputAddresses,
+		RedeemScripts:  redeemScripts,
+	})
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+	return &types.ConstructionPayloadsResponse{
+		UnsignedTransaction: hex.EncodeToString(rawTx),
+		Payloads:            payloads,
+	}, nil
+}
+
+// fundedOperations returns operations with INPUT operations
+// spending the coins selected by ConstructionMetadata and, if
+// there is change, an OUTPUT operation returning it to the
+// funding account.
+func fundedOperations(
+	operations []*types.Operation,
+	metadata *constructionMetadata,
+) ([]*types.Operation, *types.Error) {
+	funded := []*types.Operation{}
+	addOperation := func(operation *types.Operation) {
+		operation.OperationIdentifier = &types.OperationIdentifier{
+			Index: int64(len(funded)),
+		}
+		funded = append(funded, operation)
+	}
+
+	for _, coin := range metadata.Coins {
+		value, err := types.NegateValue(coin.Amount.Value)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		addOperation(&types.Operation{
+			Type:    zen.InputOpType,
+			Account: metadata.FundingAccount,
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: coin.Amount.Currency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		})
+	}
+
+	for _, operation := range operations {
+		if operation.Type == zen.InputOpType {
+			return nil, wrapErr(
+				ErrUnclearIntent,
+				errors.New("inputs cannot be provided with a funding account"),
+			)
+		}
+
+		copied := *operation
+		addOperation(&copied)
+	}
+
+	if metadata.Change != nil {
+		addOperation(&types.Operation{
+			Type:    zen.OutputOpType,
+			Account: metadata.FundingAccount,
+			Amount:  metadata.Change,
+		})
+	}
+
+	return funded, nil
+}
+
+// multiSigInput returns the redeem script and the signers of an INPUT
+// operation spending a pay-to-script-hash multisig coin locked to
+// scriptAddress. The redeem script is read from the operation metadata.
+// Unless the signers are listed in the metadata, the first required keys
+// of the redeem script are expected to sign.
+func (s *ConstructionAPIService) multiSigInput(
+	operation *types.Operation,
+	scriptAddress zenutil.Address,
+) ([]byte, []*zenutil.AddressPubKey, *types.Error) {
+	var metadata zen.OperationMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil {
+		return nil, nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	if len(metadata.RedeemScript) == 0 {
+		return nil, nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("redeem script is required to spend %s", scriptAddress.EncodeAddress()),
+		)
+	}
+
+	redeemScript, err := hex.DecodeString(metadata.RedeemScript)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnclearIntent, fmt.Errorf("%w unable to decode redeem script", err))
+	}
+
+	redeemAddress, err := zenutil.NewAddressScriptHash(redeemScript, s.config.Params)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnclearIntent, err)
+	}
+	if redeemAddress.EncodeAddress() != scriptAddress.EncodeAddress() {
+		return nil, nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("redeem script does not hash to %s", scriptAddress.EncodeAddress()),
+		)
+	}
+
+	class, addresses, nRequired, err := txscript.ExtractPkScriptAddrs(redeemScript, s.config.Params)
+	if err != nil {
+		return nil, nil, wrapErr(ErrUnclearIntent, err)
+	}
+	if class != txscript.MultiSigTy {
+		return nil, nil, wrapErr(
+			ErrUnsupportedScriptType,
+			fmt.Errorf("unupported redeem script type: %s", class),
+		)
+	}
+	if len(addresses) < nRequired {
+		return nil, nil, wrapErr(
+			ErrUnclearIntent,
+			errors.New("redeem script contains invalid public keys"),
+		)
+	}
+
+	if len(metadata.Signers) == 0 {
+		signers := make([]*zenutil.AddressPubKey, nRequired)
+		for i := range signers {
+			signers[i] = addresses[i].(*zenutil.AddressPubKey)
+		}
+
+		return redeemScript, signers, nil
+	}
+
+	if len(metadata.Signers) != nRequired {
+		return nil, nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("expected %d signers but got %d", nRequired, len(metadata.Signers)),
+		)
+	}
+
+	// Signers are returned in the order of their keys
+	// in the redeem script.
+	requested := map[string]bool{}
+	for _, signer := range metadata.Signers {
+		requested[signer] = true
+	}
+
+	signers := make([]*zenutil.AddressPubKey, 0, nRequired)
+	for _, address := range addresses {
+		key := address.(*zenutil.AddressPubKey)
+		if requested[key.AddressPubKeyHash().EncodeAddress()] {
+			signers = append(signers, key)
+		}
+	}
+	if len(signers) != nRequired {
+		return nil, nil, wrapErr(
+			ErrUnclearIntent,
+			errors.New("signers must be distinct keys of the redeem script"),
+		)
+	}
+
+	return redeemScript, signers, nil
+}
+
+// nullDataScript returns the OP_RETURN script embedding the hex data in
+// the metadata of an OUTPUT operation, or nil if the operation has no data.
+func nullDataScript(operation *types.Operation) ([]byte, error) {
+	var metadata zen.OperationMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil {
+		return nil, err
+	}
+	if len(metadata.Data) == 0 {
+		return nil, nil
+	}
+
+	data, err := hex.DecodeString(metadata.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode output data", err)
+	}
+
+	return txscript.NullDataScript(data)
+}
+
+// outputOperation returns the OUTPUT operation for output. OP_RETURN
+// outputs have no account and return their data in the metadata. Outputs
+// without a single destination, such as bare multisig, have no account
+// and return their locking script in the metadata.
+func (s *ConstructionAPIService) outputOperation(
+	index int64,
+	networkIndex int64,
+	output *wire.TxOut,
+) (*types.Operation, *types.Error) {
+	op := &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{
+			Index:        index,
+			NetworkIndex: &networkIndex,
+		},
+		Type: zen.OutputOpType,
+		Amount: &types.Amount{
+			Value:    strconv.FormatInt(output.Value, 10),
+			Currency: s.config.Currency,
+		},
+	}
 
-	for i := range tx.TxIn {
-		address := matches[0].Operations[i].Account.Address
-		script, err := hex.DecodeString(metadata.ScriptPubKeys[i].Hex)
-		if err != nil {
-			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
-		}
-		class, _, err := zen.ParseSingleAddress(s.config.Params, script)
+	if txscript.GetScriptClass(output.PkScript) == txscript.NullDataTy {
+		pushes, err := txscript.PushedData(output.PkScript)
 		if err != nil {
 			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to parse address for utxo %d", err, i),
+				ErrUnableToParseIntermediateResult,
+				fmt.Errorf("%w unable to parse output data", err),
 			)
 		}
 
-		inputAddresses[i] = address
-		inputAmounts[i] = matches[0].Amounts[i].String()
+		metadata, err := types.MarshalMap(&zen.OperationMetadata{
+			Data: hex.EncodeToString(bytes.Join(pushes, nil)),
+		})
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		op.Metadata = metadata
+		return op, nil
+	}
 
-		if class != txscript.PubKeyHashReplayOutTy && class != txscript.PubKeyHashTy {
+	class, addrs, nRequired, err := txscript.ExtractPkScriptAddrs(output.PkScript, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(
+			ErrUnableToDecodeAddress,
+			fmt.Errorf("%w unable to parse output address", err),
+		)
+	}
+
+	switch class {
+	case txscript.PubKeyTy, txscript.PubKeyReplayOutTy,
+		txscript.PubKeyHashTy, txscript.PubKeyHashReplayOutTy,
+		txscript.ScriptHashTy, txscript.ScriptHashReplayOutTy:
+		if len(addrs) != 1 {
 			return nil, wrapErr(
-				ErrUnsupportedScriptType,
-				fmt.Errorf("unupported script type: %s", class),
+				ErrUnableToDecodeAddress,
+				fmt.Errorf("expecting 1 address, got %d", len(addrs)),
 			)
 		}
-		hash, err := txscript.CalcSignatureHash(
-			script,
-			txscript.SigHashAll,
-			tx,
-			i,
-		)
-		if err != nil {
-			return nil, wrapErr(ErrUnableToCalculateSignatureHash, err)
-		}
 
-		payloads[i] = &types.SigningPayload{
-			AccountIdentifier: &types.AccountIdentifier{
-				Address: address,
-			},
-			Bytes:         hash,
-			SignatureType: types.Ecdsa,
+		op.Account = &types.AccountIdentifier{
+			Address: addrs[0].String(),
 		}
 
+		return op, nil
 	}
 
-	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
-	if err := tx.Serialize(buf); err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.String()
 	}
 
-	rawTx, err := json.Marshal(&unsignedTransaction{
-		Transaction:    hex.EncodeToString(buf.Bytes()),
-		ScriptPubKeys:  metadata.ScriptPubKeys,
-		InputAmounts:   inputAmounts,
-		InputAddresses: inputAddresses,
+	metadata, err := types.MarshalMap(&zen.OperationMetadata{
+		ScriptPubKey: &zen.ScriptPubKey{
+			Hex:          hex.EncodeToString(output.PkScript),
+			RequiredSigs: int64(nRequired),
+			Type:         class.String(),
+			Addresses:    addresses,
+		},
 	})
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
-	return &types.ConstructionPayloadsResponse{
-		UnsignedTransaction: hex.EncodeToString(rawTx),
-		Payloads:            payloads,
+
+	op.Metadata = metadata
+	return op, nil
+}
+
+// forwardTransferOutput returns the vft_ccout entry for a FORWARD_TRANSFER
+// operation. The sidechain id, receiver and mainchain return address are
+// read from the operation metadata.
+func (s *ConstructionAPIService) forwardTransferOutput(
+	operation *types.Operation,
+	value int64,
+) (*wire.TxForwardTransferOut, *types.Error) {
+	if operation.Account != nil {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			errors.New("forward transfers credit the sidechain and cannot have an account"),
+		)
+	}
+
+	var metadata zen.OperationMetadata
+	if err := types.UnmarshalMap(operation.Metadata, &metadata); err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	scID, err := chainhash.NewHashFromStr(metadata.ScID)
+	if err != nil || len(metadata.ScID) != chainhash.MaxHashStringSize {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("invalid sidechain id %q", metadata.ScID),
+		)
+	}
+
+	receiver, err := chainhash.NewHashFromStr(metadata.Receiver)
+	if err != nil || len(metadata.Receiver) != chainhash.MaxHashStringSize {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			fmt.Errorf("invalid sidechain receiver %q", metadata.Receiver),
+		)
+	}
+
+	if len(metadata.MCReturnAddress) == 0 {
+		return nil, wrapErr(
+			ErrUnclearIntent,
+			errors.New("forward transfers must have a mainchain return address"),
+		)
+	}
+
+	addr, err := zenutil.DecodeAddress(metadata.MCReturnAddress, s.config.Params)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
+			"%w unable to decode address %s",
+			err,
+			metadata.MCReturnAddress,
+		))
+	}
+
+	returnAddr, ok := addr.(*zenutil.AddressPubKeyHash)
+	if !ok {
+		return nil, wrapErr(ErrUnableToDecodeAddress, fmt.Errorf(
+			"mainchain return address %s is not a P2PKH address",
+			metadata.MCReturnAddress,
+		))
+	}
+
+	return &wire.TxForwardTransferOut{
+		Value:           value,
+		Address:         *receiver,
+		ScID:            *scID,
+		MCReturnAddress: *returnAddr.Hash160(),
 	}, nil
 }
 
+// forwardTransferOperations returns the FORWARD_TRANSFER operations for the
+// vft_ccout entries of tx, indexed after the existing ops.
+func (s *ConstructionAPIService) forwardTransferOperations(
+	tx *wire.MsgTx,
+	ops []*types.Operation,
+) ([]*types.Operation, *types.Error) {
+	for i, ftOut := range tx.TxForwardTransferOut {
+		addr, err := zenutil.NewAddressPubKeyHash(ftOut.MCReturnAddress[:], s.config.Params)
+		if err != nil {
+			return nil, wrapErr(
+				ErrUnableToDecodeAddress,
+				fmt.Errorf("%w unable to parse mainchain return address", err),
+			)
+		}
+
+		metadata, err := types.MarshalMap(&zen.OperationMetadata{
+			ScID:            ftOut.ScID.String(),
+			Receiver:        ftOut.Address.String(),
+			MCReturnAddress: addr.EncodeAddress(),
+		})
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		networkIndex := int64(i)
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        int64(len(ops)),
+				NetworkIndex: &networkIndex,
+			},
+			Type: zen.ForwardTransferOpType,
+			Amount: &types.Amount{
+				Value:    strconv.FormatInt(ftOut.Value, 10),
+				Currency: s.config.Currency,
+			},
+			Metadata: metadata,
+		})
+	}
+
+	return ops, nil
+}
+
 func normalizeSignature(signature []byte) []byte {
 	sig := btcec.Signature{ // signature is in form of R || S
 		R: new(big.Int).SetBytes(signature[:32]),
@@ -435,6 +1438,9 @@ func (s *ConstructionAPIService) ConstructionCombine(
 		)
 	}
 
+	// Signatures are provided in the order of the payloads
+	// returned by ConstructionPayloads.
+	signatures := request.Signatures
 	for i := range tx.TxIn {
 		decodedScript, err := hex.DecodeString(unsigned.ScriptPubKeys[i].Hex)
 		if err != nil {
@@ -449,20 +1455,83 @@ func (s *ConstructionAPIService) ConstructionCombine(
 			)
 		}
 
-		fullsig := normalizeSignature(request.Signatures[i].Bytes)
-		pkData := request.Signatures[i].PublicKey.Bytes
+		switch class {
+		case txscript.PubKeyHashReplayOutTy, txscript.PubKeyHashTy:
+			if len(signatures) == 0 {
+				return nil, wrapErr(
+					ErrIncompleteSignatures,
+					fmt.Errorf("missing signature for input %d", i),
+				)
+			}
+
+			fullsig := normalizeSignature(signatures[0].Bytes)
+			pkData := signatures[0].PublicKey.Bytes
+			signatures = signatures[1:]
+
+			tx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(fullsig).AddData(pkData).Script()
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w calculate input signature", err))
+			}
+		case txscript.ScriptHashReplayOutTy, txscript.ScriptHashTy:
+			if i >= len(unsigned.RedeemScripts) || len(unsigned.RedeemScripts[i]) == 0 {
+				return nil, wrapErr(
+					ErrUnableToParseIntermediateResult,
+					fmt.Errorf("missing redeem script for input %d", i),
+				)
+			}
+
+			redeemScript, err := hex.DecodeString(unsigned.RedeemScripts[i])
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
+
+			_, _, nRequired, err := txscript.ExtractPkScriptAddrs(redeemScript, s.config.Params)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+			}
+
+			if len(signatures) < nRequired {
+				return nil, wrapErr(
+					ErrIncompleteSignatures,
+					fmt.Errorf("expected %d signatures for input %d but got %d", nRequired, i, len(signatures)),
+				)
+			}
+
+			sigs := make([][]byte, nRequired)
+			for j := range sigs {
+				sigs[j] = normalizeSignature(signatures[j].Bytes)
+			}
+			signatures = signatures[nRequired:]
+
+			script, signers, err := txscript.MultiSigSignatureScript(
+				s.config.Params,
+				&tx,
+				i,
+				redeemScript,
+				sigs,
+			)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w calculate input signature", err))
+			}
 
-		if class != txscript.PubKeyHashReplayOutTy && class != txscript.PubKeyHashTy {
+			if len(signers) < nRequired {
+				return nil, wrapErr(
+					ErrIncompleteSignatures,
+					fmt.Errorf("expected %d valid signatures for input %d but got %d", nRequired, i, len(signers)),
+				)
+			}
+
+			tx.TxIn[i].SignatureScript = script
+		default:
 			return nil, wrapErr(
 				ErrUnsupportedScriptType,
 				fmt.Errorf("unupported script type: %s", class),
 			)
 		}
+	}
 
-		tx.TxIn[i].SignatureScript, err = txscript.NewScriptBuilder().AddData(fullsig).AddData(pkData).Script()
-		if err != nil {
-			return nil, wrapErr(ErrUnableToParseIntermediateResult, fmt.Errorf("%w calculate input signature", err))
-		}
+	if rerr := verifyScripts(&tx, unsigned.ScriptPubKeys, nil, nil); rerr != nil {
+		return nil, rerr
 	}
 
 	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
@@ -471,8 +1540,9 @@ func (s *ConstructionAPIService) ConstructionCombine(
 	}
 
 	rawTx, err := json.Marshal(&signedTransaction{
-		Transaction:  hex.EncodeToString(buf.Bytes()),
-		InputAmounts: unsigned.InputAmounts,
+		Transaction:   hex.EncodeToString(buf.Bytes()),
+		InputAmounts:  unsigned.InputAmounts,
+		ScriptPubKeys: unsigned.ScriptPubKeys,
 	})
 	if err != nil {
 		return nil, wrapErr(
@@ -486,6 +1556,117 @@ func (s *ConstructionAPIService) ConstructionCombine(
 	}, nil
 }
 
+// verifyScripts executes the signature script of each input of tx
+// against the scriptPubKey it spends, so that invalid signatures are
+// caught before the transaction is broadcast. If blockHashes is not
+// nil, the blocks referenced by OP_CHECKBLOCKATHEIGHT are verified
+// against it.
+func verifyScripts(
+	tx *wire.MsgTx,
+	scriptPubKeys []*zen.ScriptPubKey,
+	blockHashes txscript.BlockHashProvider,
+	params *chaincfg.Params,
+) *types.Error {
+	flags := scriptVerifyFlags
+	if blockHashes != nil {
+		flags |= txscript.ScriptVerifyCheckBlockAtHeight
+	}
+
+	if len(scriptPubKeys) != len(tx.TxIn) {
+		return wrapErr(
+			ErrScriptPubKeysMissing,
+			fmt.Errorf("expected %d scriptPubKeys but got %d", len(tx.TxIn), len(scriptPubKeys)),
+		)
+	}
+
+	for i := range tx.TxIn {
+		pkScript, err := hex.DecodeString(scriptPubKeys[i].Hex)
+		if err != nil {
+			return wrapErr(ErrUnableToDecodeScriptPubKey, err)
+		}
+
+		vm, err := txscript.NewEngineWithBlockHashes(
+			pkScript,
+			tx,
+			i,
+			flags,
+			nil,
+			nil,
+			0,
+			blockHashes,
+			params,
+		)
+		if err == nil {
+			err = vm.Execute()
+		}
+		if err != nil {
+			return wrapScriptErr(i, err)
+		}
+	}
+
+	return nil
+}
+
+// blockHashes returns the hashes of the indexed tip and of the blocks
+// referenced by the OP_CHECKBLOCKATHEIGHT of scriptPubKeys that are
+// not exempt from verification.
+func (s *ConstructionAPIService) blockHashes(
+	ctx context.Context,
+	scriptPubKeys []*zen.ScriptPubKey,
+) (txscript.StaticBlockHashes, *types.Error) {
+	head, err := s.i.GetBlockLazy(ctx, nil)
+	if err != nil {
+		return nil, wrapErr(ErrCouldNotGetBestBlock, err)
+	}
+
+	hashes := txscript.StaticBlockHashes{}
+	add := func(block *types.BlockIdentifier) *types.Error {
+		hash, err := chainhash.NewHashFromStr(block.Hash)
+		if err != nil {
+			return wrapErr(ErrUnableToParseIntermediateResult, err)
+		}
+
+		hashes[int32(block.Index)] = *hash
+		return nil
+	}
+
+	best := int32(head.Block.BlockIdentifier.Index)
+	if rerr := add(head.Block.BlockIdentifier); rerr != nil {
+		return nil, rerr
+	}
+
+	for _, scriptPubKey := range scriptPubKeys {
+		pkScript, err := hex.DecodeString(scriptPubKey.Hex)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
+		}
+
+		heights, err := txscript.ExtractCheckBlockAtHeight(pkScript)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToDecodeScriptPubKey, err)
+		}
+
+		for _, height := range heights {
+			_, ok := hashes[height]
+			if ok || height > best || height < best-s.config.Params.CheckBlockAtHeightSafeDepth {
+				continue
+			}
+
+			index := int64(height)
+			block, err := s.i.GetBlockLazy(ctx, &types.PartialBlockIdentifier{Index: &index})
+			if err != nil {
+				return nil, wrapErr(ErrBlockNotFound, err)
+			}
+
+			if rerr := add(block.Block.BlockIdentifier); rerr != nil {
+				return nil, rerr
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
 // ConstructionHash implements the /construction/hash endpoint.
 func (s *ConstructionAPIService) ConstructionHash(
 	ctx context.Context,
@@ -593,29 +1774,17 @@ func (s *ConstructionAPIService) parseUnsignedTransaction(
 	}
 
 	for i, output := range tx.TxOut {
-		networkIndex := int64(i)
-		_, addr, err := zen.ParseSingleAddress(s.config.Params, output.PkScript)
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to parse output address", err),
-			)
+		op, rerr := s.outputOperation(int64(len(ops)), int64(i), output)
+		if rerr != nil {
+			return nil, rerr
 		}
 
-		ops = append(ops, &types.Operation{
-			OperationIdentifier: &types.OperationIdentifier{
-				Index:        int64(len(ops)),
-				NetworkIndex: &networkIndex,
-			},
-			Type: zen.OutputOpType,
-			Account: &types.AccountIdentifier{
-				Address: addr.String(),
-			},
-			Amount: &types.Amount{
-				Value:    strconv.FormatInt(output.Value, 10),
-				Currency: s.config.Currency,
-			},
-		})
+		ops = append(ops, op)
+	}
+
+	ops, rerr := s.forwardTransferOperations(&tx, ops)
+	if rerr != nil {
+		return nil, rerr
 	}
 
 	return &types.ConstructionParseResponse{
@@ -677,10 +1846,13 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 			)
 		}
 
+		inputSigners, rerr := s.inputSigners(&tx, i, pkScript.Class(), addr)
+		if rerr != nil {
+			return nil, rerr
+		}
+		signers = append(signers, inputSigners...)
+
 		networkIndex := int64(i)
-		signers = append(signers, &types.AccountIdentifier{
-			Address: addr.EncodeAddress(),
-		})
 		ops = append(ops, &types.Operation{
 			OperationIdentifier: &types.OperationIdentifier{
 				Index:        int64(len(ops)),
@@ -708,29 +1880,17 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 	}
 
 	for i, output := range tx.TxOut {
-		networkIndex := int64(i)
-		_, addr, err := zen.ParseSingleAddress(s.config.Params, output.PkScript)
-		if err != nil {
-			return nil, wrapErr(
-				ErrUnableToDecodeAddress,
-				fmt.Errorf("%w unable to parse output address", err),
-			)
+		op, rerr := s.outputOperation(int64(len(ops)), int64(i), output)
+		if rerr != nil {
+			return nil, rerr
 		}
 
-		ops = append(ops, &types.Operation{
-			OperationIdentifier: &types.OperationIdentifier{
-				Index:        int64(len(ops)),
-				NetworkIndex: &networkIndex,
-			},
-			Type: zen.OutputOpType,
-			Account: &types.AccountIdentifier{
-				Address: addr.String(),
-			},
-			Amount: &types.Amount{
-				Value:    strconv.FormatInt(output.Value, 10),
-				Currency: s.config.Currency,
-			},
-		})
+		ops = append(ops, op)
+	}
+
+	ops, rerr := s.forwardTransferOperations(&tx, ops)
+	if rerr != nil {
+		return nil, rerr
 	}
 
 	return &types.ConstructionParseResponse{
@@ -739,6 +1899,53 @@ func (s *ConstructionAPIService) parseSignedTransaction(
 	}, nil
 }
 
+// inputSigners returns the accounts that signed input idx of tx, which
+// spends a coin of class locked to address. The signers of
+// pay-to-script-hash multisig inputs are the keys whose signature is
+// included in the signature script.
+func (s *ConstructionAPIService) inputSigners(
+	tx *wire.MsgTx,
+	idx int,
+	class txscript.ScriptClass,
+	address zenutil.Address,
+) ([]*types.AccountIdentifier, *types.Error) {
+	if class != txscript.ScriptHashTy {
+		return []*types.AccountIdentifier{{Address: address.EncodeAddress()}}, nil
+	}
+
+	// The signature script is OP_0 <sigs...> <redeemScript>.
+	pushes, err := txscript.PushedData(tx.TxIn[idx].SignatureScript)
+	if err != nil || len(pushes) < 2 { // nolint:gomnd
+		return nil, wrapErr(
+			ErrUnableToComputePkScript,
+			fmt.Errorf("input %d is not a multisig input", idx),
+		)
+	}
+
+	_, keys, err := txscript.MultiSigSignatureScript(
+		s.config.Params,
+		tx,
+		idx,
+		pushes[len(pushes)-1],
+		pushes[1:len(pushes)-1],
+	)
+	if err != nil {
+		return nil, wrapErr(
+			ErrUnableToComputePkScript,
+			fmt.Errorf("%w: unable to match signatures of input %d", err, idx),
+		)
+	}
+
+	signers := make([]*types.AccountIdentifier, len(keys))
+	for i, key := range keys {
+		signers[i] = &types.AccountIdentifier{
+			Address: key.AddressPubKeyHash().EncodeAddress(),
+		}
+	}
+
+	return signers, nil
+}
+
 // ConstructionParse implements the /construction/parse endpoint.
 func (s *ConstructionAPIService) ConstructionParse(
 	ctx context.Context,
@@ -776,9 +1983,62 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 		)
 	}
 
+	bytesTx, err := hex.DecodeString(signed.Transaction)
+	if err != nil {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			fmt.Errorf("%w unable to decode hex transaction", err),
+		)
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(bytesTx)); err != nil {
+		return nil, wrapErr(
+			ErrUnableToParseIntermediateResult,
+			fmt.Errorf("%w unable to deserialize tx", err),
+		)
+	}
+
+	// Transactions combined before scriptPubKeys were included
+	// in the signed transaction cannot be verified.
+	if len(signed.ScriptPubKeys) > 0 {
+		blockHashes, rerr := s.blockHashes(ctx, signed.ScriptPubKeys)
+		if rerr != nil {
+			return nil, rerr
+		}
+
+		rerr = verifyScripts(&tx, signed.ScriptPubKeys, blockHashes, s.config.Params)
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+
 	txHash, err := s.client.SendRawTransaction(ctx, signed.Transaction)
 	if err != nil {
-		return nil, wrapErr(ErrBitcoind, fmt.Errorf("%w unable to submit transaction", err))
+		return nil, wrapClientErr(ErrBitcoind, fmt.Errorf("%w unable to submit transaction", err))
+	}
+
+	// Only transactions accepted by zend are tracked, so
+	// that rejected transactions are never rebroadcast.
+	inputs := make([]string, len(tx.TxIn))
+	for i, input := range tx.TxIn {
+		inputs[i] = zen.CoinIdentifier(
+			input.PreviousOutPoint.Hash.String(),
+			int64(input.PreviousOutPoint.Index),
+		)
+	}
+
+	if err := s.i.TrackTransaction(ctx, &broadcast.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: tx.TxHash().String(),
+		},
+		NetworkTransaction: signed.Transaction,
+		Inputs:             inputs,
+	}); err != nil {
+		return nil, wrapErr(
+			ErrUnableToTrackTransaction,
+			fmt.Errorf("%w: transaction %s was submitted but will not be rebroadcast", err, txHash),
+		)
 	}
 
 	return &types.TransactionIdentifierResponse{
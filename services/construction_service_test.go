@@ -15,14 +15,24 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/HorizenOfficial/rosetta-zen/zen"
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
 	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
+	"github.com/HorizenOfficial/rosetta-zen/zend/btcec"
+	"github.com/HorizenOfficial/rosetta-zen/zend/txscript"
+	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
+	"github.com/HorizenOfficial/rosetta-zen/zenutil"
+	"github.com/HorizenOfficial/rosetta-zen/zenutil/hdkeychain"
 
+	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,6 +55,93 @@ func forceMarshalMap(t *testing.T, i interface{}) map[string]interface{} {
 	return m
 }
 
+// testSigner signs construction payloads with a fixed private key,
+// so that combined transactions pass script verification.
+type testSigner struct {
+	key     *btcec.PrivateKey
+	address string
+}
+
+func newTestSigner(t *testing.T, seed byte) *testSigner {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{seed}, 32))
+	address, err := zenutil.NewAddressPubKeyHash(
+		zenutil.Hash160(key.PubKey().SerializeCompressed()),
+		zen.TestnetParams,
+	)
+	if err != nil {
+		t.Fatalf("could not derive address: %s", err)
+	}
+
+	return &testSigner{key: key, address: address.EncodeAddress()}
+}
+
+// scriptPubKey returns the replay protected P2PKH script
+// paying to the signer.
+func (s *testSigner) scriptPubKey(
+	t *testing.T,
+	replayHash string,
+	replayHeight int64,
+) *zen.ScriptPubKey {
+	address, err := zenutil.DecodeAddress(s.address, zen.TestnetParams)
+	if err != nil {
+		t.Fatalf("could not decode address: %s", err)
+	}
+
+	script, err := txscript.PayToAddrReplayOutScript(
+		address,
+		forceHexDecode(t, replayHash),
+		replayHeight,
+	)
+	if err != nil {
+		t.Fatalf("could not create script: %s", err)
+	}
+
+	asm, err := txscript.DisasmString(script)
+	if err != nil {
+		t.Fatalf("could not disassemble script: %s", err)
+	}
+
+	return &zen.ScriptPubKey{
+		ASM:          asm,
+		Hex:          hex.EncodeToString(script),
+		RequiredSigs: 1,
+		Type:         "pubkeyhashreplay",
+		Addresses:    []string{s.address},
+	}
+}
+
+// pubKey returns the compressed public key of the signer.
+func (s *testSigner) pubKey(t *testing.T) *zenutil.AddressPubKey {
+	pubKey, err := zenutil.NewAddressPubKey(
+		s.key.PubKey().SerializeCompressed(),
+		zen.TestnetParams,
+	)
+	if err != nil {
+		t.Fatalf("could not create public key address: %s", err)
+	}
+
+	return pubKey
+}
+
+// sign returns the signature of payload in the R || S
+// form expected by ConstructionCombine.
+func (s *testSigner) sign(t *testing.T, payload *types.SigningPayload) *types.Signature {
+	sig, err := s.key.Sign(payload.Bytes)
+	if err != nil {
+		t.Fatalf("could not sign payload: %s", err)
+	}
+
+	return &types.Signature{
+		Bytes:          append(sig.R.FillBytes(make([]byte, 32)), sig.S.FillBytes(make([]byte, 32))...),
+		SigningPayload: payload,
+		PublicKey: &types.PublicKey{
+			Bytes:     s.key.PubKey().SerializeCompressed(),
+			CurveType: types.Secp256k1,
+		},
+		SignatureType: types.Ecdsa,
+	}
+}
+
 func TestConstructionService(t *testing.T) {
 	networkIdentifier = &types.NetworkIdentifier{
 		Network:    zen.TestnetNetwork,
@@ -60,7 +157,7 @@ func TestConstructionService(t *testing.T) {
 
 	mockIndexer := &mocks.Indexer{}
 	mockClient := &mocks.Client{}
-	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer)
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
 	ctx := context.Background()
 
 	// Test Derive
@@ -137,12 +234,58 @@ func TestConstructionService(t *testing.T) {
 				},
 			},
 		},
-		EstimatedSize: 227,
+		EstimatedSize: 229,
 	}
 	assert.Equal(t, &types.ConstructionPreprocessResponse{
 		Options: forceMarshalMap(t, options),
 	}, preprocessResponse)
 
+	// Test Preprocess with fee estimation options
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				ConfirmationTarget:  zen.Int64Pointer(1),
+				FeeEstimationSource: feeSourceIndexer,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	feeOptions := *options
+	feeOptions.ConfirmationTarget = 1
+	feeOptions.FeeEstimationSource = feeSourceIndexer
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, &feeOptions),
+	}, preprocessResponse)
+
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				ConfirmationTarget: zen.Int64Pointer(maxConfirmationTarget + 1),
+			}),
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	preprocessResponse, err = servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				ConfirmationTarget: zen.Int64Pointer(0),
+			}),
+		},
+	)
+	assert.Nil(t, preprocessResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
 	// Test Metadata
 	metadata := &constructionMetadata{
 		ScriptPubKeys: []*zen.ScriptPubKey{
@@ -180,23 +323,27 @@ func TestConstructionService(t *testing.T) {
 	mockClient.On(
 		"GetBestBlock",
 		ctx).Return(
-		int64(312), nil).Twice()
+		int64(312), nil).Times(4)
 	mockClient.On(
 		"GetHashFromIndex",
 		ctx,
 		int64(212)).Return(
-		"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8", nil).Twice()
+		"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8", nil).Times(4)
 
 	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
 		NetworkIdentifier: networkIdentifier,
 		Options:           forceMarshalMap(t, options),
 	})
 	assert.Nil(t, err)
+	normalFeeMetadata := *metadata
+	normalFeeMetadata.FeeRate = zen.MinFeeRate * 10
+	normalFeeMetadata.ConfirmationTarget = defaultConfirmationTarget
+	normalFeeMetadata.FeeEstimationSource = feeSourceZend
 	assert.Equal(t, &types.ConstructionMetadataResponse{
-		Metadata: forceMarshalMap(t, metadata),
+		Metadata: forceMarshalMap(t, &normalFeeMetadata),
 		SuggestedFee: []*types.Amount{
 			{
-				Value:    "2270", // 1,420 * 0.75
+				Value:    "2290", // 1,420 * 0.75
 				Currency: zen.TestnetCurrency,
 			},
 		},
@@ -224,11 +371,100 @@ func TestConstructionService(t *testing.T) {
 		Options:           forceMarshalMap(t, options),
 	})
 	assert.Nil(t, err)
+	lowFeeMetadata := *metadata
+	lowFeeMetadata.FeeRate = zen.MinFeeRate
+	lowFeeMetadata.ConfirmationTarget = defaultConfirmationTarget
+	lowFeeMetadata.FeeEstimationSource = feeSourceZend
 	assert.Equal(t, &types.ConstructionMetadataResponse{
-		Metadata: forceMarshalMap(t, metadata),
+		Metadata: forceMarshalMap(t, &lowFeeMetadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "229", // we don't go below minimum fee rate
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}, metadataResponse)
+
+	// Priority fee estimated by the indexer
+	priorityOptions := *options
+	priorityOptions.ConfirmationTarget = 1
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		options.Coins,
+	).Return(
+		metadata.ScriptPubKeys,
+		nil,
+	).Once()
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		int64(1),
+	).Return(
+		float64(-1),
+		zen.ErrInsufficientFeeData,
+	).Once()
+	mockIndexer.On(
+		"EstimateFeeRate",
+		ctx,
+		int64(1),
+	).Return(
+		zen.MinFeeRate*5,
+		nil,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, &priorityOptions),
+	})
+	assert.Nil(t, err)
+	priorityFeeMetadata := *metadata
+	priorityFeeMetadata.FeeRate = zen.MinFeeRate * 5
+	priorityFeeMetadata.ConfirmationTarget = 1
+	priorityFeeMetadata.FeeEstimationSource = feeSourceIndexer
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, &priorityFeeMetadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "1145",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}, metadataResponse)
+
+	// No estimate available
+	economyOptions := *options
+	economyOptions.ConfirmationTarget = 10
+	economyOptions.FeeEstimationSource = feeSourceIndexer
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		options.Coins,
+	).Return(
+		metadata.ScriptPubKeys,
+		nil,
+	).Once()
+	mockIndexer.On(
+		"EstimateFeeRate",
+		ctx,
+		int64(10),
+	).Return(
+		float64(-1),
+		zen.ErrInsufficientFeeData,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, &economyOptions),
+	})
+	assert.Nil(t, err)
+	economyFeeMetadata := *metadata
+	economyFeeMetadata.FeeRate = zen.MinFeeRate
+	economyFeeMetadata.ConfirmationTarget = 10
+	economyFeeMetadata.FeeEstimationSource = feeSourceMinimum
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, &economyFeeMetadata),
 		SuggestedFee: []*types.Amount{
 			{
-				Value:    "227", // we don't go below minimum fee rate
+				Value:    "229",
 				Currency: zen.TestnetCurrency,
 			},
 		},
@@ -311,7 +547,7 @@ func TestConstructionService(t *testing.T) {
 	}, parseUnsignedResponse)
 
 	// Test Combine
-	signedRaw := "7b227472616e73616374696f6e223a22303130303030303030313038356233303936643638653262646134303432313437633531613330326531353433313237313764336564643161373265373131303432613138326230613230313030303030303661343733303434303232303632343234663837363563386361303936303134316362306566663132386339633639363762636661313632656239626539323637356533396633346639613730323230373434373830323730393239613130303633303762633538663837643366373132376163383635306264393636313036373837633930626339373663366332633031323130333136346637363336306566373965373531336566663330393565386236306135636639383232336265643064333130396161616265356630363162653431343066666666666666663031303063613961336230303030303030303365373661393134383633623435353736613133306463396338343838326436366663656165393235363463656230663838616332306638313638323066323431353062353634376536363262643961653339336638326632633662353662613665343839383363656264373230623361653836303730326434303062343030303030303030222c22696e7075745f616d6f756e7473223a5b222d31303030303030303030225d7d" // nolint
+	signedRaw := "7b227472616e73616374696f6e223a22303130303030303030313038356233303936643638653262646134303432313437633531613330326531353433313237313764336564643161373265373131303432613138326230613230313030303030303661343733303434303232303632343234663837363563386361303936303134316362306566663132386339633639363762636661313632656239626539323637356533396633346639613730323230373434373830323730393239613130303633303762633538663837643366373132376163383635306264393636313036373837633930626339373663366332633031323130333136346637363336306566373965373531336566663330393565386236306135636639383232336265643064333130396161616265356630363162653431343066666666666666663031303063613961336230303030303030303365373661393134383633623435353736613133306463396338343838326436366663656165393235363463656230663838616332306638313638323066323431353062353634376536363262643961653339336638326632633662353662613665343839383363656264373230623361653836303730326434303062343030303030303030222c22696e7075745f616d6f756e7473223a5b222d31303030303030303030225d2c227363726970745075624b657973223a5b7b2261736d223a224f505f445550204f505f484153483136302036343335326361326637333664633465373436346136356638623037656633313364376162353364204f505f455155414c564552494659204f505f434845434b5349472062366365336132666235336634396365333162636632643430346366336266613838636166373162643565633062346231646337656566386164383934373064203131204f505f434845434b424c4f434b4154484549474854222c22686578223a22373661393134363433353263613266373336646334653734363461363566386230376566333133643761623533643838616332306236636533613266623533663439636533316263663264343034636633626661383863616637316264356563306234623164633765656638616438393437306435626234222c2272657153696773223a312c2274797065223a227075626b6579686173687265706c6179222c22616464726573736573223a5b227a746348703272655235643441685a4c4c70356259454c7a665a5848514552516f6769225d7d5d7d" // nolint
 	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
 		NetworkIdentifier:   networkIdentifier,
 		UnsignedTransaction: unsignedRaw,
@@ -359,8 +595,77 @@ func TestConstructionService(t *testing.T) {
 		TransactionIdentifier: transactionIdentifier,
 	}, hashResponse)
 
+	// Test Submit with a replay protected input referencing a
+	// block that is not in the main chain
+	replayIndex := int64(11)
+	mockIndexer.On(
+		"GetBlockLazy",
+		ctx,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(
+		&types.BlockResponse{
+			Block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{
+					Index: 100,
+					Hash:  "0000000000000000000000000000000000000000000000000000000000000064",
+				},
+			},
+		},
+		nil,
+	)
+	mockIndexer.On(
+		"GetBlockLazy",
+		ctx,
+		&types.PartialBlockIdentifier{Index: &replayIndex},
+	).Return(
+		&types.BlockResponse{
+			Block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{
+					Index: replayIndex,
+					Hash:  "000000000000000000000000000000000000000000000000000000000000000b",
+				},
+			},
+		},
+		nil,
+	).Once()
+	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, submitResponse)
+	assert.Equal(t, ErrScriptVerificationFailed.Code, err.Code)
+	assert.Equal(t, "ErrCheckBlockAtHeightUnsatisfied", err.Details["script_error"])
+
 	// Test Submit
+	mockIndexer.On(
+		"GetBlockLazy",
+		ctx,
+		&types.PartialBlockIdentifier{Index: &replayIndex},
+	).Return(
+		&types.BlockResponse{
+			Block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{
+					Index: replayIndex,
+					Hash:  "0d4789adf8eec71d4b0becd51bf7ca88fa3bcf04d4f2bc31ce493fb52f3aceb6",
+				},
+			},
+		},
+		nil,
+	)
 	bitcoinTransaction := "0100000001085b3096d68e2bda4042147c51a302e154312717d3edd1a72e711042a182b0a2010000006a473044022062424f8765c8ca0960141cb0eff128c9c6967bcfa162eb9be92675e39f34f9a70220744780270929a1006307bc58f87d3f7127ac8650bd966106787c90bc976c6c2c012103164f76360ef79e7513eff3095e8b60a5cf98223bed0d3109aaabe5f061be4140ffffffff0100ca9a3b000000003e76a914863b45576a130dc9c84882d66fceae92564ceb0f88ac20f816820f24150b5647e662bd9ae393f82f2c6b56ba6e48983cebd720b3ae860702d400b400000000" // nolint
+	mockIndexer.On(
+		"TrackTransaction",
+		ctx,
+		&broadcast.Transaction{
+			TransactionIdentifier: transactionIdentifier,
+			NetworkTransaction:    bitcoinTransaction,
+			Inputs: []string{
+				"a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+			},
+		},
+	).Return(
+		nil,
+	).Once()
 	mockClient.On(
 		"SendRawTransaction",
 		ctx,
@@ -368,8 +673,8 @@ func TestConstructionService(t *testing.T) {
 	).Return(
 		transactionIdentifier.Hash,
 		nil,
-	)
-	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+	).Once()
+	submitResponse, err = servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
 		NetworkIdentifier: networkIdentifier,
 		SignedTransaction: signedRaw,
 	})
@@ -378,6 +683,1810 @@ func TestConstructionService(t *testing.T) {
 		TransactionIdentifier: transactionIdentifier,
 	}, submitResponse)
 
+	// Transactions rejected by zend are not tracked.
+	mockClient.On(
+		"SendRawTransaction",
+		ctx,
+		bitcoinTransaction,
+	).Return(
+		"",
+		fmt.Errorf("%w: code: -26", zen.ErrJSONRPCError),
+	).Once()
+	submitResponse, err = servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, submitResponse)
+	assert.Equal(t, ErrBitcoind.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_SidechainTransaction(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+
+	// Turn a signed transparent transaction into a sidechain
+	// transaction that also sends coins to a sidechain.
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(forceHexDecode(
+		t,
+		"0100000001085b3096d68e2bda4042147c51a302e154312717d3edd1a72e711042a182b0a2010000006a473044022062424f8765c8ca0960141cb0eff128c9c6967bcfa162eb9be92675e39f34f9a70220744780270929a1006307bc58f87d3f7127ac8650bd966106787c90bc976c6c2c012103164f76360ef79e7513eff3095e8b60a5cf98223bed0d3109aaabe5f061be4140ffffffff0100ca9a3b000000003e76a914863b45576a130dc9c84882d66fceae92564ceb0f88ac20f816820f24150b5647e662bd9ae393f82f2c6b56ba6e48983cebd720b3ae860702d400b400000000", // nolint
+	))))
+	tx.Version = wire.SidechainTxVersion
+	tx.TxForwardTransferOut = []*wire.TxForwardTransferOut{
+		{Value: 100000000},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, tx.Serialize(&buf))
+	signed, err := json.Marshal(&signedTransaction{
+		Transaction:  hex.EncodeToString(buf.Bytes()),
+		InputAmounts: []string{"-1000000000"},
+	})
+	assert.NoError(t, err)
+	signedRaw := hex.EncodeToString(signed)
+
+	// Test Parse Signed
+	val0 := int64(0)
+	parseResponse, rosettaErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       signedRaw,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index:        0,
+					NetworkIndex: &val0,
+				},
+				Type: zen.InputOpType,
+				Account: &types.AccountIdentifier{
+					Address: "ztcHp2reR5d4AhZLLp5bYELzfZXHQERQogi",
+				},
+				Amount: &types.Amount{
+					Value:    "-1000000000",
+					Currency: zen.TestnetCurrency,
+				},
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: &types.CoinIdentifier{
+						Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+					},
+					CoinAction: types.CoinSpent,
+				},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index:        1,
+					NetworkIndex: &val0,
+				},
+				Type: zen.OutputOpType,
+				Account: &types.AccountIdentifier{
+					Address: "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX",
+				},
+				Amount: &types.Amount{
+					Value:    "1000000000",
+					Currency: zen.TestnetCurrency,
+				},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index:        2,
+					NetworkIndex: &val0,
+				},
+				Type: zen.ForwardTransferOpType,
+				Amount: &types.Amount{
+					Value:    "100000000",
+					Currency: zen.TestnetCurrency,
+				},
+				Metadata: map[string]interface{}{
+					"scid":              "0000000000000000000000000000000000000000000000000000000000000000",
+					"receiver":          "0000000000000000000000000000000000000000000000000000000000000000",
+					"mc_return_address": "ztT9xm46wJHTkscMqDcEHBC7Lc4mq4AaNUB",
+				},
+			},
+		},
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: "ztcHp2reR5d4AhZLLp5bYELzfZXHQERQogi"},
+		},
+	}, parseResponse)
+
+	// Test Hash
+	hashResponse, rosettaErr := servicer.ConstructionHash(ctx, &types.ConstructionHashRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signedRaw,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: tx.TxHash().String(),
+		},
+	}, hashResponse)
+	assert.NotEqual(
+		t,
+		"a589c9941da87b15ffbb419569f38a1d44c805aeaafa167088d270de0fd8eed2",
+		hashResponse.TransactionIdentifier.Hash,
+	)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_ForwardTransfer(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+	signer := newTestSigner(t, 0x01)
+
+	scID := "0a2e1c6d5fb3fc77a1b40c7a4e0ba0b3b9c5fd9f1dd4a8d14e5c2bba3dc5b4e1"
+	receiver := "79fa5d8a9c3eb3c1d73e5c1df8c8d6e1a0b47d0a1e6f2c6a5d7b8e4f3c2b1a09"
+	returnAddress := "ztcHp2reR5d4AhZLLp5bYELzfZXHQERQogi"
+	ftMetadata := forceMarshalMap(t, &zen.OperationMetadata{
+		ScID:            scID,
+		Receiver:        receiver,
+		MCReturnAddress: returnAddress,
+	})
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: zen.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: signer.address,
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: zen.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: zen.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX",
+			},
+			Amount: &types.Amount{
+				Value:    "900000000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: zen.ForwardTransferOpType,
+			Amount: &types.Amount{
+				Value:    "100000000",
+				Currency: zen.TestnetCurrency,
+			},
+			Metadata: ftMetadata,
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins: []*types.Coin{
+			{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+				},
+				Amount: &types.Amount{
+					Value:    "-1000000000",
+					Currency: zen.TestnetCurrency,
+				},
+			},
+		},
+		EstimatedSize: 325,
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Payloads
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*zen.ScriptPubKey{
+			signer.scriptPubKey(
+				t,
+				"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+				212,
+			),
+		},
+		ReplayBlockHeight: 212,
+		ReplayBlockHash:   "0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+	}
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(
+		forceHexDecode(t, payloadsResponse.UnsignedTransaction),
+		&unsigned,
+	))
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(forceHexDecode(t, unsigned.Transaction))))
+	assert.Equal(t, int32(wire.SidechainTxVersion), tx.Version)
+	assert.Len(t, tx.TxOut, 1)
+	assert.Len(t, tx.TxForwardTransferOut, 1)
+	assert.Equal(t, int64(100000000), tx.TxForwardTransferOut[0].Value)
+	assert.Equal(t, scID, tx.TxForwardTransferOut[0].ScID.String())
+	assert.Equal(t, receiver, tx.TxForwardTransferOut[0].Address.String())
+
+	// The forward transfer is committed to by the signature hash.
+	transparent := tx.Copy()
+	transparent.TxForwardTransferOut = nil
+	transparentHash, hashErr := txscript.CalcSignatureHash(
+		forceHexDecode(t, metadata.ScriptPubKeys[0].Hex),
+		txscript.SigHashAll,
+		transparent,
+		0,
+	)
+	assert.NoError(t, hashErr)
+	assert.NotEqual(t, transparentHash, payloadsResponse.Payloads[0].Bytes)
+
+	val0 := int64(0)
+	parseOps := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        0,
+				NetworkIndex: &val0,
+			},
+			Type:       zen.InputOpType,
+			Account:    ops[0].Account,
+			Amount:     ops[0].Amount,
+			CoinChange: ops[0].CoinChange,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        1,
+				NetworkIndex: &val0,
+			},
+			Type:    zen.OutputOpType,
+			Account: ops[1].Account,
+			Amount:  ops[1].Amount,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        2,
+				NetworkIndex: &val0,
+			},
+			Type:     zen.ForwardTransferOpType,
+			Amount:   ops[2].Amount,
+			Metadata: ftMetadata,
+		},
+	}
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			signer.sign(t, payloadsResponse.Payloads[0]),
+		},
+	})
+	assert.Nil(t, err)
+
+	validSigned := combineResponse.SignedTransaction
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: signer.address},
+		},
+	}, parseSignedResponse)
+
+	// Test Combine with a signature over the wrong payload
+	wrongPayload := *payloadsResponse.Payloads[0]
+	wrongPayload.Bytes = forceHexDecode(
+		t,
+		"7068aa7955b0469aa51cefcf0ae0a45448fe945a4f606ca373df1f4c3ca8002f",
+	)
+	invalidSignature := signer.sign(t, &wrongPayload)
+	invalidSignature.SigningPayload = payloadsResponse.Payloads[0]
+	combineResponse, err = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures:          []*types.Signature{invalidSignature},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrScriptVerificationFailed.Code, err.Code)
+	assert.Equal(t, 0, err.Details["input_index"])
+	assert.Equal(t, "ErrNullFail", err.Details["script_error"])
+
+	// Test Submit with a scriptPubKey not matching the signature
+	var signed signedTransaction
+	assert.NoError(t, json.Unmarshal(
+		forceHexDecode(t, validSigned),
+		&signed,
+	))
+	signed.ScriptPubKeys = []*zen.ScriptPubKey{
+		newTestSigner(t, 0x02).scriptPubKey(
+			t,
+			"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+			212,
+		),
+	}
+	tampered, jsonErr := json.Marshal(&signed)
+	assert.NoError(t, jsonErr)
+	mockIndexer.On(
+		"GetBlockLazy",
+		ctx,
+		(*types.PartialBlockIdentifier)(nil),
+	).Return(
+		&types.BlockResponse{
+			Block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{
+					Index: 212,
+					Hash:  "f816820f24150b5647e662bd9ae393f82f2c6b56ba6e48983cebd720b3ae8607",
+				},
+			},
+		},
+		nil,
+	).Once()
+	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: hex.EncodeToString(tampered),
+	})
+	assert.Nil(t, submitResponse)
+	assert.Equal(t, ErrScriptVerificationFailed.Code, err.Code)
+	assert.Equal(t, 0, err.Details["input_index"])
+	assert.Equal(t, "ErrEqualVerify", err.Details["script_error"])
+
+	// Test Payloads with an invalid sidechain id
+	invalidOps := []*types.Operation{ops[0], ops[1], ops[2]}
+	invalidTransfer := *ops[2]
+	invalidTransfer.Metadata = forceMarshalMap(t, &zen.OperationMetadata{
+		ScID:            "1234",
+		Receiver:        receiver,
+		MCReturnAddress: returnAddress,
+	})
+	invalidOps[2] = &invalidTransfer
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        invalidOps,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	// Test Payloads without a mainchain return address
+	invalidTransfer.Metadata = forceMarshalMap(t, &zen.OperationMetadata{
+		ScID:     scID,
+		Receiver: receiver,
+	})
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        invalidOps,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	// Test Payloads with a mainchain account credited by the transfer
+	invalidTransfer.Metadata = ftMetadata
+	invalidTransfer.Account = &types.AccountIdentifier{Address: returnAddress}
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        invalidOps,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_MultiSig(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+
+	// 2-of-3 multisig redeem script
+	signers := []*testSigner{
+		newTestSigner(t, 0x01),
+		newTestSigner(t, 0x02),
+		newTestSigner(t, 0x03),
+	}
+	redeemScript, err := txscript.MultiSigScript([]*zenutil.AddressPubKey{
+		signers[0].pubKey(t),
+		signers[1].pubKey(t),
+		signers[2].pubKey(t),
+	}, 2)
+	assert.NoError(t, err)
+	scriptAddress, err := zenutil.NewAddressScriptHash(redeemScript, zen.TestnetParams)
+	assert.NoError(t, err)
+
+	replayHash := "0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8"
+	pkScript, err := txscript.PayToAddrReplayOutScript(
+		scriptAddress,
+		forceHexDecode(t, replayHash),
+		212,
+	)
+	assert.NoError(t, err)
+
+	// The third and first keys sign, out of order
+	inputMetadata := forceMarshalMap(t, &zen.OperationMetadata{
+		RedeemScript: hex.EncodeToString(redeemScript),
+		Signers:      []string{signers[2].address, signers[0].address},
+	})
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: zen.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: scriptAddress.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: zen.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+			Metadata: inputMetadata,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: zen.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX",
+			},
+			Amount: &types.Amount{
+				Value:    "999990000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, rerr := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, rerr)
+	var options preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &options))
+	assert.Equal(t, float64(381), options.EstimatedSize)
+
+	// Test Payloads
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*zen.ScriptPubKey{
+			{
+				Hex:          hex.EncodeToString(pkScript),
+				RequiredSigs: 1,
+				Type:         "scripthashreplay",
+				Addresses:    []string{scriptAddress.EncodeAddress()},
+			},
+		},
+		ReplayBlockHeight: 212,
+		ReplayBlockHash:   replayHash,
+	}
+	payloadsResponse, rerr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, rerr)
+	assert.Len(t, payloadsResponse.Payloads, 2)
+	assert.Equal(t, signers[0].address, payloadsResponse.Payloads[0].AccountIdentifier.Address)
+	assert.Equal(t, signers[2].address, payloadsResponse.Payloads[1].AccountIdentifier.Address)
+	assert.Equal(t, payloadsResponse.Payloads[0].Bytes, payloadsResponse.Payloads[1].Bytes)
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, rerr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rerr)
+	assert.Equal(t, scriptAddress.EncodeAddress(), parseUnsignedResponse.Operations[0].Account.Address)
+
+	// Test Combine
+	combineResponse, rerr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			signers[0].sign(t, payloadsResponse.Payloads[0]),
+			signers[2].sign(t, payloadsResponse.Payloads[1]),
+		},
+	})
+	assert.Nil(t, rerr)
+
+	var signed signedTransaction
+	assert.NoError(t, json.Unmarshal(forceHexDecode(t, combineResponse.SignedTransaction), &signed))
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(forceHexDecode(t, signed.Transaction))))
+	pushes, err := txscript.PushedData(tx.TxIn[0].SignatureScript)
+	assert.NoError(t, err)
+	assert.Len(t, pushes, 4)
+	assert.Empty(t, pushes[0])
+	assert.Equal(t, redeemScript, pushes[3])
+
+	// Test Parse Signed
+	parseSignedResponse, rerr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, rerr)
+	assert.Equal(t, scriptAddress.EncodeAddress(), parseSignedResponse.Operations[0].Account.Address)
+	assert.Equal(t, []*types.AccountIdentifier{
+		{Address: signers[0].address},
+		{Address: signers[2].address},
+	}, parseSignedResponse.AccountIdentifierSigners)
+
+	// Test Combine with a missing signature
+	combineResponse, rerr = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			signers[0].sign(t, payloadsResponse.Payloads[0]),
+		},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrIncompleteSignatures.Code, rerr.Code)
+
+	// Test Combine with a signature over the wrong payload
+	wrongPayload := *payloadsResponse.Payloads[1]
+	wrongPayload.Bytes = forceHexDecode(
+		t,
+		"7068aa7955b0469aa51cefcf0ae0a45448fe945a4f606ca373df1f4c3ca8002f",
+	)
+	combineResponse, rerr = servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			signers[0].sign(t, payloadsResponse.Payloads[0]),
+			signers[2].sign(t, &wrongPayload),
+		},
+	})
+	assert.Nil(t, combineResponse)
+	assert.Equal(t, ErrIncompleteSignatures.Code, rerr.Code)
+
+	// Test Payloads without a redeem script
+	missingRedeemScript := *ops[0]
+	missingRedeemScript.Metadata = nil
+	payloadsResponse, rerr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        []*types.Operation{&missingRedeemScript, ops[1]},
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, rerr.Code)
+
+	// Test Payloads with a signer not in the redeem script
+	unknownSigner := *ops[0]
+	unknownSigner.Metadata = forceMarshalMap(t, &zen.OperationMetadata{
+		RedeemScript: hex.EncodeToString(redeemScript),
+		Signers:      []string{signers[0].address, newTestSigner(t, 0x04).address},
+	})
+	payloadsResponse, rerr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        []*types.Operation{&unknownSigner, ops[1]},
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, rerr.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_NullData(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+	signer := newTestSigner(t, 0x01)
+
+	dataMetadata := forceMarshalMap(t, &zen.OperationMetadata{
+		Data: "48656c6c6f",
+	})
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: zen.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: signer.address,
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: zen.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: zen.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX",
+			},
+			Amount: &types.Amount{
+				Value:    "999990000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: zen.OutputOpType,
+			Amount: &types.Amount{
+				Value:    "0",
+				Currency: zen.TestnetCurrency,
+			},
+			Metadata: dataMetadata,
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins: []*types.Coin{
+			{
+				CoinIdentifier: ops[0].CoinChange.CoinIdentifier,
+				Amount:         ops[0].Amount,
+			},
+		},
+		EstimatedSize: 245,
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	// Test Payloads
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*zen.ScriptPubKey{
+			signer.scriptPubKey(
+				t,
+				"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+				212,
+			),
+		},
+		ReplayBlockHeight: 212,
+		ReplayBlockHash:   "0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+	}
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(
+		forceHexDecode(t, payloadsResponse.UnsignedTransaction),
+		&unsigned,
+	))
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(forceHexDecode(t, unsigned.Transaction))))
+	assert.Len(t, tx.TxOut, 2)
+	assert.Equal(t, int64(0), tx.TxOut[1].Value)
+	assert.Equal(t, forceHexDecode(t, "6a0548656c6c6f"), tx.TxOut[1].PkScript)
+
+	val0 := int64(0)
+	val1 := int64(1)
+	parseOps := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        0,
+				NetworkIndex: &val0,
+			},
+			Type:       zen.InputOpType,
+			Account:    ops[0].Account,
+			Amount:     ops[0].Amount,
+			CoinChange: ops[0].CoinChange,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        1,
+				NetworkIndex: &val0,
+			},
+			Type:    zen.OutputOpType,
+			Account: ops[1].Account,
+			Amount:  ops[1].Amount,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        2,
+				NetworkIndex: &val1,
+			},
+			Type:     zen.OutputOpType,
+			Amount:   ops[2].Amount,
+			Metadata: dataMetadata,
+		},
+	}
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations:               parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{},
+	}, parseUnsignedResponse)
+
+	// Test Combine
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			signer.sign(t, payloadsResponse.Payloads[0]),
+		},
+	})
+	assert.Nil(t, err)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionParseResponse{
+		Operations: parseOps,
+		AccountIdentifierSigners: []*types.AccountIdentifier{
+			{Address: signer.address},
+		},
+	}, parseSignedResponse)
+
+	// Test Payloads with invalid outputs
+	invalidOutputs := map[string]*types.Operation{
+		"data with a non-zero amount": {
+			OperationIdentifier: ops[2].OperationIdentifier,
+			Type:                zen.OutputOpType,
+			Amount:              ops[1].Amount,
+			Metadata:            dataMetadata,
+		},
+		"data with an account": {
+			OperationIdentifier: ops[2].OperationIdentifier,
+			Type:                zen.OutputOpType,
+			Account:             ops[1].Account,
+			Amount:              ops[2].Amount,
+			Metadata:            dataMetadata,
+		},
+		"data that is not hex": {
+			OperationIdentifier: ops[2].OperationIdentifier,
+			Type:                zen.OutputOpType,
+			Amount:              ops[2].Amount,
+			Metadata: forceMarshalMap(t, &zen.OperationMetadata{
+				Data: "hello",
+			}),
+		},
+		"zero amount without data": {
+			OperationIdentifier: ops[2].OperationIdentifier,
+			Type:                zen.OutputOpType,
+			Account:             ops[1].Account,
+			Amount:              ops[2].Amount,
+		},
+		"no account without data": {
+			OperationIdentifier: ops[2].OperationIdentifier,
+			Type:                zen.OutputOpType,
+			Amount:              ops[1].Amount,
+		},
+	}
+	for name, output := range invalidOutputs {
+		payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        []*types.Operation{ops[0], ops[1], output},
+			Metadata:          forceMarshalMap(t, metadata),
+		})
+		assert.Nil(t, payloadsResponse, name)
+		assert.Equal(t, ErrUnclearIntent.Code, err.Code, name)
+	}
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_OutputScriptClasses(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+	signer := newTestSigner(t, 0x01)
+	cosigner := newTestSigner(t, 0x02)
+
+	redeemScript, scriptErr := txscript.MultiSigScript(
+		[]*zenutil.AddressPubKey{signer.pubKey(t), cosigner.pubKey(t)},
+		1,
+	)
+	assert.NoError(t, scriptErr)
+	scriptHash, addrErr := zenutil.NewAddressScriptHash(redeemScript, zen.TestnetParams)
+	assert.NoError(t, addrErr)
+
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: zen.InputOpType,
+			Account: &types.AccountIdentifier{
+				Address: signer.address,
+			},
+			Amount: &types.Amount{
+				Value:    "-1000000000",
+				Currency: zen.TestnetCurrency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+				},
+				CoinAction: types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			Type: zen.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: scriptHash.EncodeAddress(),
+			},
+			Amount: &types.Amount{
+				Value:    "500000000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 2,
+			},
+			Type: zen.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: cosigner.pubKey(t).String(),
+			},
+			Amount: &types.Amount{
+				Value:    "499990000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+		},
+	)
+	assert.Nil(t, err)
+	var options preprocessOptions
+	assert.NoError(t, types.UnmarshalMap(preprocessResponse.Options, &options))
+	assert.Equal(t, float64(309), options.EstimatedSize)
+
+	// Test Payloads
+	replayHash := "0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8"
+	metadata := &constructionMetadata{
+		ScriptPubKeys: []*zen.ScriptPubKey{
+			signer.scriptPubKey(t, replayHash, 212),
+		},
+		ReplayBlockHeight: 212,
+		ReplayBlockHash:   replayHash,
+	}
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+
+	var unsigned unsignedTransaction
+	assert.NoError(t, json.Unmarshal(
+		forceHexDecode(t, payloadsResponse.UnsignedTransaction),
+		&unsigned,
+	))
+	var tx wire.MsgTx
+	assert.NoError(t, tx.Deserialize(bytes.NewReader(forceHexDecode(t, unsigned.Transaction))))
+	assert.Len(t, tx.TxOut, 2)
+	assert.Equal(t, txscript.ScriptHashReplayOutTy, txscript.GetScriptClass(tx.TxOut[0].PkScript))
+	assert.Equal(t, txscript.PubKeyReplayOutTy, txscript.GetScriptClass(tx.TxOut[1].PkScript))
+
+	val0 := int64(0)
+	val1 := int64(1)
+	parseOps := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        0,
+				NetworkIndex: &val0,
+			},
+			Type:       zen.InputOpType,
+			Account:    ops[0].Account,
+			Amount:     ops[0].Amount,
+			CoinChange: ops[0].CoinChange,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        1,
+				NetworkIndex: &val0,
+			},
+			Type:    zen.OutputOpType,
+			Account: ops[1].Account,
+			Amount:  ops[1].Amount,
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index:        2,
+				NetworkIndex: &val1,
+			},
+			Type:    zen.OutputOpType,
+			Account: ops[2].Account,
+			Amount:  ops[2].Amount,
+		},
+	}
+
+	// Test Parse Unsigned
+	parseUnsignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, parseOps, parseUnsignedResponse.Operations)
+
+	// Test Combine
+	combineResponse, err := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			signer.sign(t, payloadsResponse.Payloads[0]),
+		},
+	})
+	assert.Nil(t, err)
+
+	// Test Parse Signed
+	parseSignedResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, parseOps, parseSignedResponse.Operations)
+
+	// Test Parse with a bare multisig output, which has no account
+	replayOut, scriptErr := txscript.NewScriptBuilder().
+		AddData(forceHexDecode(t, replayHash)).
+		AddInt64(212).
+		AddOp(txscript.OP_CHECKBLOCKATHEIGHT).
+		Script()
+	assert.NoError(t, scriptErr)
+	multiSigScript := append(append([]byte{}, redeemScript...), replayOut...)
+	tx.AddTxOut(&wire.TxOut{Value: 1000, PkScript: multiSigScript})
+	var buf bytes.Buffer
+	assert.NoError(t, tx.Serialize(&buf))
+	unsigned.Transaction = hex.EncodeToString(buf.Bytes())
+	rawUnsigned, jsonErr := json.Marshal(&unsigned)
+	assert.NoError(t, jsonErr)
+
+	parseUnsignedResponse, err = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       hex.EncodeToString(rawUnsigned),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, parseUnsignedResponse.Operations, 4)
+	val2 := int64(2)
+	assert.Equal(t, &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{
+			Index:        3,
+			NetworkIndex: &val2,
+		},
+		Type: zen.OutputOpType,
+		Amount: &types.Amount{
+			Value:    "1000",
+			Currency: zen.TestnetCurrency,
+		},
+		Metadata: forceMarshalMap(t, &zen.OperationMetadata{
+			ScriptPubKey: &zen.ScriptPubKey{
+				Hex:          hex.EncodeToString(multiSigScript),
+				RequiredSigs: 1,
+				Type:         "multisigreplayout",
+				Addresses: []string{
+					signer.pubKey(t).String(),
+					cosigner.pubKey(t).String(),
+				},
+			},
+		}),
+	}, parseUnsignedResponse.Operations[3])
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_DeriveExtendedKey(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Offline,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+
+	master, keyErr := hdkeychain.NewMaster(
+		forceHexDecode(t, "000102030405060708090a0b0c0d0e0f"),
+		zen.TestnetParams,
+	)
+	assert.NoError(t, keyErr)
+	account, keyErr := master.DerivePath(hdkeychain.BIP44Path(zen.TestnetParams, 0, 0, 0)[:3])
+	assert.NoError(t, keyErr)
+	xpub, keyErr := account.Neuter()
+	assert.NoError(t, keyErr)
+	xpubKey, keyErr := xpub.ECPubKey()
+	assert.NoError(t, keyErr)
+	publicKey := &types.PublicKey{
+		Bytes:     xpubKey.SerializeCompressed(),
+		CurveType: types.Secp256k1,
+	}
+
+	child, keyErr := master.DerivePath(hdkeychain.BIP44Path(zen.TestnetParams, 0, 0, 5))
+	assert.NoError(t, keyErr)
+	childAddress, keyErr := child.Address(zen.TestnetParams)
+	assert.NoError(t, keyErr)
+	childKey, keyErr := child.ECPubKey()
+	assert.NoError(t, keyErr)
+
+	deriveResponse, err := servicer.ConstructionDerive(ctx, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: networkIdentifier,
+		PublicKey:         publicKey,
+		Metadata: forceMarshalMap(t, &deriveMetadata{
+			XPub: xpub.String(),
+			Path: "0/5",
+		}),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{
+			Address: childAddress.EncodeAddress(),
+		},
+		Metadata: forceMarshalMap(t, &deriveResponseMetadata{
+			PublicKey: hex.EncodeToString(childKey.SerializeCompressed()),
+			Path:      "m/0/5",
+		}),
+	}, deriveResponse)
+
+	mainnetXPub, keyErr := hdkeychain.NewKeyFromString(xpub.String())
+	assert.NoError(t, keyErr)
+	mainnetXPub.SetNet(zen.MainnetParams)
+
+	invalid := map[string]*types.ConstructionDeriveRequest{
+		"hardened path": {
+			PublicKey: publicKey,
+			Metadata:  forceMarshalMap(t, &deriveMetadata{XPub: xpub.String(), Path: "0'/5"}),
+		},
+		"invalid path": {
+			PublicKey: publicKey,
+			Metadata:  forceMarshalMap(t, &deriveMetadata{XPub: xpub.String(), Path: "0/x"}),
+		},
+		"public key not matching xpub": {
+			PublicKey: &types.PublicKey{
+				Bytes:     childKey.SerializeCompressed(),
+				CurveType: types.Secp256k1,
+			},
+			Metadata: forceMarshalMap(t, &deriveMetadata{XPub: xpub.String(), Path: "0/5"}),
+		},
+		"extended private key": {
+			PublicKey: publicKey,
+			Metadata:  forceMarshalMap(t, &deriveMetadata{XPub: account.String(), Path: "0/5"}),
+		},
+		"xpub for another network": {
+			PublicKey: publicKey,
+			Metadata:  forceMarshalMap(t, &deriveMetadata{XPub: mainnetXPub.String(), Path: "0/5"}),
+		},
+		"malformed xpub": {
+			PublicKey: publicKey,
+			Metadata:  forceMarshalMap(t, &deriveMetadata{XPub: "tpub1234", Path: "0/5"}),
+		},
+	}
+	for name, request := range invalid {
+		request.NetworkIdentifier = networkIdentifier
+		deriveResponse, err := servicer.ConstructionDerive(ctx, request)
+		assert.Nil(t, deriveResponse, name)
+		assert.Equal(t, ErrUnableToDerive.Code, err.Code, name)
+	}
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_CoinSelection(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+
+	fundingAccount := &types.AccountIdentifier{
+		Address: "ztcHp2reR5d4AhZLLp5bYELzfZXHQERQogi",
+	}
+	scriptHash, addrErr := zenutil.NewAddressScriptHash([]byte{txscript.OP_TRUE}, zen.TestnetParams)
+	assert.NoError(t, addrErr)
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: zen.OutputOpType,
+			Account: &types.AccountIdentifier{
+				Address: "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX",
+			},
+			Amount: &types.Amount{
+				Value:    "1000000000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				FundingAccount: fundingAccount,
+				CoinSelection:  coinSelectionLargestFirst,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins:          []*types.Coin{},
+		EstimatedSize:  82,
+		FundingAccount: fundingAccount,
+		CoinSelection:  coinSelectionLargestFirst,
+		Amount:         "1000000000",
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	input := &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{
+			Index: 1,
+		},
+		Type:    zen.InputOpType,
+		Account: fundingAccount,
+		Amount: &types.Amount{
+			Value:    "-1000000000",
+			Currency: zen.TestnetCurrency,
+		},
+		CoinChange: &types.CoinChange{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+			},
+			CoinAction: types.CoinSpent,
+		},
+	}
+	invalidPreprocess := map[string]*types.ConstructionPreprocessRequest{
+		"inputs with a funding account": {
+			Operations: append([]*types.Operation{input}, ops...),
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				FundingAccount: fundingAccount,
+			}),
+		},
+		"pay-to-script-hash funding account": {
+			Operations: ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				FundingAccount: &types.AccountIdentifier{
+					Address: scriptHash.EncodeAddress(),
+				},
+			}),
+		},
+		"invalid coin selection": {
+			Operations: ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				FundingAccount: fundingAccount,
+				CoinSelection:  "smallest_first",
+			}),
+		},
+		"coin selection without a funding account": {
+			Operations: append([]*types.Operation{input}, ops...),
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				CoinSelection: coinSelectionPrivacy,
+			}),
+		},
+	}
+	for name, request := range invalidPreprocess {
+		request.NetworkIdentifier = networkIdentifier
+		preprocessResponse, err := servicer.ConstructionPreprocess(ctx, request)
+		assert.Nil(t, preprocessResponse, name)
+		assert.Equal(t, ErrUnclearIntent.Code, err.Code, name)
+	}
+
+	// Test Metadata
+	coins := []*types.Coin{
+		{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:0",
+			},
+			Amount: &types.Amount{
+				Value:    "600000000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+		{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:1",
+			},
+			Amount: &types.Amount{
+				Value:    "700000000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+		{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:2",
+			},
+			Amount: &types.Amount{
+				Value:    "2000000000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}
+	selected := []*types.Coin{coins[1], coins[0]}
+	scriptPubKey := &zen.ScriptPubKey{
+		ASM:          "OP_DUP OP_HASH160 64352ca2f736dc4e7464a65f8b07ef313d7ab53d OP_EQUALVERIFY OP_CHECKSIG b6ce3a2fb53f49ce31bcf2d404cf3bfa88caf71bd5ec0b4b1dc7eef8ad89470d 11 OP_CHECKBLOCKATHEIGHT",
+		Hex:          "76a91464352ca2f736dc4e7464a65f8b07ef313d7ab53d88ac20b6ce3a2fb53f49ce31bcf2d404cf3bfa88caf71bd5ec0b4b1dc7eef8ad89470d5bb4",
+		RequiredSigs: 1,
+		Type:         "pubkeyhashreplay",
+		Addresses: []string{
+			"ztcHp2reR5d4AhZLLp5bYELzfZXHQERQogi",
+		},
+	}
+	pendingTransaction := &zen.Transaction{Hash: "pending"}
+
+	// The largest coin is spent by a transaction in the mempool,
+	// so the other two coins are selected.
+	mockClient.On(
+		"SuggestedFeeRate",
+		ctx,
+		defaultConfirmationTarget,
+	).Return(
+		zen.MinFeeRate*10,
+		nil,
+	).Twice()
+	mockIndexer.On("GetCoins", ctx, fundingAccount).Return(coins, nil, nil).Twice()
+	mockClient.On("RawMempool", ctx).Return([]string{"pending"}, nil).Twice()
+
+	// The pending transaction is only fetched once, the second
+	// selection reuses the cached mempool coins.
+	mockClient.On(
+		"GetRawTransaction",
+		ctx,
+		"pending",
+	).Return(
+		pendingTransaction,
+		[]string{coins[2].CoinIdentifier.Identifier},
+		nil,
+	).Once()
+	mockClient.On(
+		"ParseTransaction",
+		ctx,
+		pendingTransaction,
+		map[string]*storage.AccountCoin{},
+	).Return(
+		&types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "pending"},
+			Operations:            []*types.Operation{},
+		},
+		nil,
+	).Once()
+	mockIndexer.On(
+		"GetScriptPubKeys",
+		ctx,
+		selected,
+	).Return(
+		[]*zen.ScriptPubKey{scriptPubKey, scriptPubKey},
+		nil,
+	).Once()
+	mockClient.On("GetBestBlock", ctx).Return(int64(312), nil).Once()
+	mockClient.On(
+		"GetHashFromIndex",
+		ctx,
+		int64(212),
+	).Return(
+		"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+		nil,
+	).Once()
+
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	metadata := &constructionMetadata{
+		ScriptPubKeys:       []*zen.ScriptPubKey{scriptPubKey, scriptPubKey},
+		ReplayBlockHeight:   212,
+		ReplayBlockHash:     "0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+		FeeRate:             zen.MinFeeRate * 10,
+		ConfirmationTarget:  defaultConfirmationTarget,
+		FeeEstimationSource: feeSourceZend,
+		Coins:               selected,
+		FundingAccount:      fundingAccount,
+		Change: &types.Amount{
+			Value:    "299995520", // 1,300,000,000 - 1,000,000,000 - 4,480
+			Currency: zen.TestnetCurrency,
+		},
+	}
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "4480", // 448 * 10
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Metadata with insufficient funds
+	expensiveOptions := *options
+	expensiveOptions.Amount = "1300000000"
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, &expensiveOptions),
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrInsufficientFunds.Code, err.Code)
+
+	// Test Payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 2)
+	for _, payload := range payloadsResponse.Payloads {
+		assert.Equal(t, fundingAccount, payload.AccountIdentifier)
+	}
+
+	// Test Parse
+	parseResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, parseResponse.Operations, 4)
+	expected := []struct {
+		opType  string
+		address string
+		value   string
+	}{
+		{zen.InputOpType, fundingAccount.Address, "-700000000"},
+		{zen.InputOpType, fundingAccount.Address, "-600000000"},
+		{zen.OutputOpType, "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX", "1000000000"},
+		{zen.OutputOpType, fundingAccount.Address, "299995520"},
+	}
+	for i, op := range parseResponse.Operations {
+		assert.Equal(t, expected[i].opType, op.Type)
+		assert.Equal(t, expected[i].address, op.Account.Address)
+		assert.Equal(t, expected[i].value, op.Amount.Value)
+	}
+
+	// Inputs cannot be added to selected coins
+	payloadsResponse, err = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        append([]*types.Operation{input}, ops...),
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, payloadsResponse)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestConstructionService_BumpTransaction(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    zen.TestnetNetwork,
+		Blockchain: zen.Blockchain,
+	}
+
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Network:  networkIdentifier,
+		Params:   zen.TestnetParams,
+		Currency: zen.TestnetCurrency,
+	}
+
+	mockIndexer := &mocks.Indexer{}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient, mockIndexer, NewMempoolCoins(mockClient))
+	ctx := context.Background()
+
+	parentHash := "9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab"
+	fundingAccount := &types.AccountIdentifier{
+		Address: "ztcHp2reR5d4AhZLLp5bYELzfZXHQERQogi",
+	}
+	recipient := &types.AccountIdentifier{
+		Address: "ztfPiJyJL3UavuYw5Fiv1V1okdbsmY1b5qX",
+	}
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type:    zen.OutputOpType,
+			Account: recipient,
+			Amount: &types.Amount{
+				Value:    "100000",
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}
+
+	// Test Preprocess
+	preprocessResponse, err := servicer.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata: forceMarshalMap(t, &preprocessMetadata{
+				FundingAccount:  fundingAccount,
+				BumpTransaction: &types.TransactionIdentifier{Hash: parentHash},
+				FeeRate:         0.0005,
+			}),
+		},
+	)
+	assert.Nil(t, err)
+	options := &preprocessOptions{
+		Coins:           []*types.Coin{},
+		EstimatedSize:   82,
+		FundingAccount:  fundingAccount,
+		Amount:          "100000",
+		BumpTransaction: &types.TransactionIdentifier{Hash: parentHash},
+		FeeRate:         0.0005,
+	}
+	assert.Equal(t, &types.ConstructionPreprocessResponse{
+		Options: forceMarshalMap(t, options),
+	}, preprocessResponse)
+
+	invalidPreprocess := map[string]*preprocessMetadata{
+		"no fee rate": {
+			FundingAccount:  fundingAccount,
+			BumpTransaction: &types.TransactionIdentifier{Hash: parentHash},
+		},
+		"no funding account": {
+			BumpTransaction: &types.TransactionIdentifier{Hash: parentHash},
+			FeeRate:         0.0005,
+		},
+	}
+	for name, metadata := range invalidPreprocess {
+		preprocessResponse, err := servicer.ConstructionPreprocess(
+			ctx,
+			&types.ConstructionPreprocessRequest{
+				NetworkIdentifier: networkIdentifier,
+				Operations:        ops,
+				Metadata:          forceMarshalMap(t, metadata),
+			},
+		)
+		assert.Nil(t, preprocessResponse, name)
+		assert.Equal(t, ErrUnclearIntent.Code, err.Code, name)
+	}
+
+	// Test Metadata
+	fundingScript := &zen.ScriptPubKey{
+		ASM:          "OP_DUP OP_HASH160 64352ca2f736dc4e7464a65f8b07ef313d7ab53d OP_EQUALVERIFY OP_CHECKSIG b6ce3a2fb53f49ce31bcf2d404cf3bfa88caf71bd5ec0b4b1dc7eef8ad89470d 11 OP_CHECKBLOCKATHEIGHT",
+		Hex:          "76a91464352ca2f736dc4e7464a65f8b07ef313d7ab53d88ac20b6ce3a2fb53f49ce31bcf2d404cf3bfa88caf71bd5ec0b4b1dc7eef8ad89470d5bb4",
+		RequiredSigs: 1,
+		Type:         "pubkeyhashreplay",
+		Addresses:    []string{fundingAccount.Address},
+	}
+	recipientScript := &zen.ScriptPubKey{
+		Type:      "pubkeyhashreplay",
+		Addresses: []string{recipient.Address},
+	}
+	rawParent := &zen.Transaction{
+		Hash: parentHash,
+		Outputs: []*zen.Output{
+			{Value: 0.5, Index: 0, ScriptPubKey: recipientScript},
+			{Value: 0.1, Index: 1, ScriptPubKey: fundingScript},
+		},
+	}
+	change := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: zen.CoinIdentifier(parentHash, 1),
+		},
+		Amount: &types.Amount{
+			Value:    "10000000",
+			Currency: zen.TestnetCurrency,
+		},
+	}
+	parsedParent := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: parentHash},
+		Operations: []*types.Operation{
+			{
+				Type:    zen.OutputOpType,
+				Account: recipient,
+				Amount: &types.Amount{
+					Value:    "50000000",
+					Currency: zen.TestnetCurrency,
+				},
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: &types.CoinIdentifier{
+						Identifier: zen.CoinIdentifier(parentHash, 0),
+					},
+					CoinAction: types.CoinCreated,
+				},
+			},
+			{
+				Type:    zen.OutputOpType,
+				Account: fundingAccount,
+				Amount:  change.Amount,
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: change.CoinIdentifier,
+					CoinAction:     types.CoinCreated,
+				},
+			},
+		},
+	}
+
+	mockClient.On(
+		"MempoolEntry",
+		ctx,
+		parentHash,
+	).Return(
+		&zen.MempoolEntry{Size: 226, Fee: 0.00000226},
+		nil,
+	).Once()
+	mockClient.On(
+		"GetRawTransaction",
+		ctx,
+		parentHash,
+	).Return(
+		rawParent,
+		[]string{"a2b082a14210712ea7d1edd317273154e102a3517c144240da2b8ed696305b08:0"},
+		nil,
+	).Twice()
+	mockClient.On(
+		"ParseTransaction",
+		ctx,
+		rawParent,
+		map[string]*storage.AccountCoin{},
+	).Return(
+		parsedParent,
+		nil,
+	).Twice()
+	mockClient.On("RawMempool", ctx).Return([]string{parentHash}, nil).Once()
+	mockClient.On("GetBestBlock", ctx).Return(int64(312), nil).Once()
+	mockClient.On(
+		"GetHashFromIndex",
+		ctx,
+		int64(212),
+	).Return(
+		"0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+		nil,
+	).Once()
+
+	metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, err)
+	metadata := &constructionMetadata{
+		ScriptPubKeys:      []*zen.ScriptPubKey{fundingScript},
+		ReplayBlockHeight:  212,
+		ReplayBlockHash:    "0786aeb320d7eb3c98486eba566b2c2ff893e39abd62e647560b15240f8216f8",
+		FeeRate:            0.0005,
+		ConfirmationTarget: defaultConfirmationTarget,
+		Coins:              []*types.Coin{change},
+		FundingAccount:     fundingAccount,
+		Change: &types.Amount{
+			Value:    "9873876", // 10,000,000 - 100,000 - 26,124
+			Currency: zen.TestnetCurrency,
+		},
+	}
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, metadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "26124", // (226 + 301) * 50 - 226
+				Currency: zen.TestnetCurrency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Metadata for a transaction that is not in the mempool
+	mockClient.On(
+		"MempoolEntry",
+		ctx,
+		parentHash,
+	).Return(
+		nil,
+		zen.ErrTransactionNotFound,
+	).Once()
+	metadataResponse, err = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, options),
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+
+	// Test Payloads
+	payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, metadata),
+	})
+	assert.Nil(t, err)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+	assert.Equal(t, fundingAccount, payloadsResponse.Payloads[0].AccountIdentifier)
+
+	// Test Parse
+	parseResponse, err := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, parseResponse.Operations, 3)
+	assert.Equal(t, change.CoinIdentifier, parseResponse.Operations[0].CoinChange.CoinIdentifier)
+	assert.Equal(t, "-10000000", parseResponse.Operations[0].Amount.Value)
+	assert.Equal(t, recipient.Address, parseResponse.Operations[1].Account.Address)
+	assert.Equal(t, "100000", parseResponse.Operations[1].Amount.Value)
+	assert.Equal(t, fundingAccount.Address, parseResponse.Operations[2].Account.Address)
+	assert.Equal(t, "9873876", parseResponse.Operations[2].Amount.Value)
+
 	mockClient.AssertExpectations(t)
 	mockIndexer.AssertExpectations(t)
 }
@@ -15,6 +15,12 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+	"github.com/HorizenOfficial/rosetta-zen/zend/txscript"
+
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -42,6 +48,14 @@ var (
 		ErrCouldNotGetFeeRate,
 		ErrUnableToGetBalance,
 		ErrCouldNotGetBestBlock,
+		ErrInvalidSearch,
+		ErrUnableToSearchTransactions,
+		ErrScriptVerificationFailed,
+		ErrIncompleteSignatures,
+		ErrInsufficientFunds,
+		ErrUnableToTrackTransaction,
+		ErrTransactionNotTracked,
+		ErrUnableToGetBroadcast,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -191,6 +205,65 @@ var (
 		Code:    19, // nolint
 		Message: "Could not get best block height",
 	}
+
+	// ErrInvalidSearch is returned when a /search/transactions
+	// request cannot be served.
+	ErrInvalidSearch = &types.Error{
+		Code:    20, // nolint
+		Message: "Invalid search request",
+	}
+
+	// ErrUnableToSearchTransactions is returned by the indexer
+	// when it is not possible to search the transaction history.
+	ErrUnableToSearchTransactions = &types.Error{
+		Code:    21, // nolint
+		Message: "Unable to search transactions",
+	}
+
+	// ErrScriptVerificationFailed is returned when an input
+	// of a signed transaction fails script verification.
+	ErrScriptVerificationFailed = &types.Error{
+		Code:    22, // nolint
+		Message: "Transaction script verification failed",
+	}
+
+	// ErrIncompleteSignatures is returned when fewer valid
+	// signatures than required are provided for an input.
+	ErrIncompleteSignatures = &types.Error{
+		Code:    23, // nolint
+		Message: "Not enough signatures to spend input",
+	}
+
+	// ErrInsufficientFunds is returned when the coins
+	// of the funding account cannot pay for the outputs
+	// of a transaction and its fee.
+	ErrInsufficientFunds = &types.Error{
+		Code:    24, // nolint
+		Message: "Insufficient funds",
+	}
+
+	// ErrUnableToTrackTransaction is returned by the indexer
+	// when it is not possible to store a submitted transaction.
+	ErrUnableToTrackTransaction = &types.Error{
+		Code:    25, // nolint
+		Message: "Unable to track transaction",
+	}
+
+	// ErrTransactionNotTracked is returned when the status
+	// of a transaction that was not submitted through
+	// /construction/submit is requested.
+	ErrTransactionNotTracked = &types.Error{
+		Code:    26, // nolint
+		Message: "Transaction not tracked",
+	}
+
+	// ErrUnableToGetBroadcast is returned by the indexer
+	// when it is not possible to get the status of a
+	// tracked transaction.
+	ErrUnableToGetBroadcast = &types.Error{
+		Code:    27, // nolint
+		Message: "Unable to get transaction status",
+	}
 )
 
 // wrapErr adds details to the types.Error provided. We use a function
@@ -210,3 +283,32 @@ func wrapErr(rErr *types.Error, err error) *types.Error {
 
 	return newErr
 }
+
+// wrapClientErr is like wrapErr for errors returned by the zend
+// client. While the client's circuit breaker is open, the
+// retriable ErrNotReady is returned instead of rErr.
+func wrapClientErr(rErr *types.Error, err error) *types.Error {
+	if errors.Is(err, zen.ErrCircuitOpen) {
+		return wrapErr(ErrNotReady, err)
+	}
+
+	return wrapErr(rErr, err)
+}
+
+// wrapScriptErr returns ErrScriptVerificationFailed with the
+// index of the failing input and, if err is a txscript.Error,
+// its error code in the details.
+func wrapScriptErr(index int, err error) *types.Error {
+	newErr := wrapErr(
+		ErrScriptVerificationFailed,
+		fmt.Errorf("%w: input %d failed script verification", err, index),
+	)
+	newErr.Details["input_index"] = index
+
+	var scriptErr txscript.Error
+	if errors.As(err, &scriptErr) {
+		newErr.Details["script_error"] = scriptErr.ErrorCode.String()
+	}
+
+	return newErr
+}
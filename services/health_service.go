@@ -0,0 +1,225 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// stageZendIBD is the sync stage while zend is downloading
+	// the blockchain. The current and target indexes are
+	// the blocks and headers of zend.
+	stageZendIBD = "zend IBD"
+
+	// stageIndexing is the sync stage while the indexer is
+	// more than MaxSyncLag blocks behind zend's headers.
+	stageIndexing = "indexing"
+
+	// stageSynced is the sync stage once the indexer is
+	// at most MaxSyncLag blocks behind zend's headers.
+	stageSynced = "synced"
+)
+
+// syncStatus returns the *types.SyncStatus of an indexer
+// at currentIndex syncing to the headers known to zend.
+// zend is considered in IBD while it reports so or while
+// its own blocks are more than maxLag behind its headers
+// (for example, when it catches up after a restart).
+func syncStatus(
+	currentIndex int64,
+	info *zen.BlockchainInfo,
+	maxLag int64,
+) *types.SyncStatus {
+	targetIndex := info.Headers
+	stage := stageSynced
+	switch {
+	case info.InitialBlockDownload || targetIndex-info.Blocks > maxLag:
+		stage = stageZendIBD
+		currentIndex = info.Blocks
+	case targetIndex-currentIndex > maxLag:
+		stage = stageIndexing
+	}
+
+	return &types.SyncStatus{
+		CurrentIndex: currentIndex,
+		TargetIndex:  &targetIndex,
+		Stage:        &stage,
+	}
+}
+
+// HealthAPIServicer defines the health checks
+// used by orchestrators like Kubernetes.
+type HealthAPIServicer interface {
+	Health(context.Context) *HealthResponse
+}
+
+// HealthAPIService implements the HealthAPIServicer interface.
+type HealthAPIService struct {
+	config *configuration.Configuration
+	client Client
+	i      Indexer
+
+	// node is nil when we connect
+	// to an external zend.
+	node NodeProcess
+}
+
+// NewHealthAPIService returns a new *HealthAPIService.
+func NewHealthAPIService(
+	config *configuration.Configuration,
+	client Client,
+	i Indexer,
+	node NodeProcess,
+) HealthAPIServicer {
+	return &HealthAPIService{
+		config: config,
+		client: client,
+		i:      i,
+		node:   node,
+	}
+}
+
+// Health checks the database, the zend daemon and
+// the sync progress of the indexer. rosetta-zen is
+// healthy if the database can be read and zend is
+// running. It is ready once zend is done loading and
+// the indexer is synced.
+func (s *HealthAPIService) Health(ctx context.Context) *HealthResponse {
+	response := &HealthResponse{
+		MaxLag: s.config.MaxSyncLag,
+	}
+
+	// There is nothing to check in offline mode.
+	if s.config.Mode != configuration.Online {
+		response.Healthy = true
+		response.Ready = true
+
+		return response
+	}
+
+	databaseHealthy := true
+	if err := s.i.CheckDatabase(ctx); err != nil {
+		databaseHealthy = false
+		response.Errors = append(response.Errors, err.Error())
+	}
+	response.DatabaseHealthy = &databaseHealthy
+
+	zendAlive := true
+	zendStarted := true
+	if s.node != nil {
+		zendAlive = s.node.Alive()
+		response.ZendAlive = &zendAlive
+
+		// zend does not serve requests reliably
+		// until it is done loading.
+		zendState := s.node.State()
+		zendStarted = zendState == zen.NodeReady
+		state := string(zendState)
+		response.ZendState = &state
+		response.ZendError = s.node.LastError()
+	}
+
+	zendReachable := true
+	info, err := s.client.BlockchainInfo(ctx)
+	if err != nil {
+		zendReachable = false
+		response.Errors = append(response.Errors, err.Error())
+	}
+	response.ZendReachable = &zendReachable
+
+	synced := false
+	if databaseHealthy && zendReachable {
+		// The indexer has not synced any block
+		// if it does not have a head block.
+		currentIndex := int64(-1)
+		blockResponse, err := s.i.GetBlockLazy(ctx, nil)
+		if err == nil {
+			currentIndex = blockResponse.Block.BlockIdentifier.Index
+		}
+
+		lag := info.Headers - currentIndex
+		response.SyncStatus = syncStatus(currentIndex, info, s.config.MaxSyncLag)
+		response.Lag = &lag
+		response.ZendVerificationProgress = &info.VerificationProgress
+		synced = *response.SyncStatus.Stage == stageSynced
+	}
+
+	response.Healthy = databaseHealthy && zendAlive
+	response.Ready = response.Healthy && zendStarted && zendReachable && synced
+
+	return response
+}
+
+// HealthAPIController binds http requests to a HealthAPIServicer
+// and writes the service results to the http response.
+type HealthAPIController struct {
+	service HealthAPIServicer
+}
+
+// NewHealthAPIController creates a default api controller.
+func NewHealthAPIController(s HealthAPIServicer) server.Router {
+	return &HealthAPIController{
+		service: s,
+	}
+}
+
+// Routes returns all of the api route for the HealthAPIController.
+func (c *HealthAPIController) Routes() server.Routes {
+	return server.Routes{
+		{
+			Name:        "Healthz",
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/healthz",
+			HandlerFunc: c.Healthz,
+		},
+		{
+			Name:        "Readyz",
+			Method:      strings.ToUpper("Get"),
+			Pattern:     "/readyz",
+			HandlerFunc: c.Readyz,
+		},
+	}
+}
+
+// Healthz - Check if rosetta-zen is Healthy
+func (c *HealthAPIController) Healthz(w http.ResponseWriter, r *http.Request) {
+	result := c.service.Health(r.Context())
+	status := http.StatusOK
+	if !result.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	server.EncodeJSONResponse(result, status, w)
+}
+
+// Readyz - Check if rosetta-zen is Ready to Serve Requests
+func (c *HealthAPIController) Readyz(w http.ResponseWriter, r *http.Request) {
+	result := c.service.Health(r.Context())
+	status := http.StatusOK
+	if !result.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	server.EncodeJSONResponse(result, status, w)
+}
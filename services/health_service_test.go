@@ -0,0 +1,278 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNode struct {
+	state     zen.NodeState
+	lastError string
+}
+
+func (n *mockNode) Alive() bool {
+	return n.state != zen.NodeStopped && n.state != zen.NodeFailed
+}
+
+func (n *mockNode) State() zen.NodeState {
+	return n.state
+}
+
+func (n *mockNode) LastError() string {
+	return n.lastError
+}
+
+func TestHealth_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:       configuration.Offline,
+		MaxSyncLag: 3,
+	}
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewHealthAPIService(cfg, mockClient, mockIndexer, nil)
+
+	assert.Equal(t, &HealthResponse{
+		Healthy: true,
+		Ready:   true,
+		MaxLag:  3,
+	}, servicer.Health(context.Background()))
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestHealth_Online(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:       configuration.Online,
+		MaxSyncLag: 3,
+	}
+	ctx := context.Background()
+
+	blockResponse := &types.BlockResponse{
+		Block: &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{Index: 100, Hash: "block 100"},
+		},
+	}
+
+	tests := map[string]struct {
+		node        NodeProcess
+		databaseErr error
+		info        *zen.BlockchainInfo
+		infoErr     error
+		block       *types.BlockResponse
+		blockErr    error
+
+		healthy       bool
+		ready         bool
+		zendAlive     *bool
+		zendReachable bool
+		lag           *int64
+		stage         string
+	}{
+		"synced": {
+			info:          &zen.BlockchainInfo{Blocks: 102, Headers: 102},
+			block:         blockResponse,
+			healthy:       true,
+			ready:         true,
+			zendReachable: true,
+			lag:           int64Pointer(2),
+		},
+		"synced with embedded zend": {
+			node:          &mockNode{state: zen.NodeReady},
+			info:          &zen.BlockchainInfo{Blocks: 100, Headers: 100},
+			block:         blockResponse,
+			healthy:       true,
+			ready:         true,
+			zendAlive:     boolPointer(true),
+			zendReachable: true,
+			lag:           int64Pointer(0),
+		},
+		"zend loading block index": {
+			node:          &mockNode{state: zen.NodeLoadingBlockIndex},
+			info:          &zen.BlockchainInfo{Blocks: 100, Headers: 100},
+			block:         blockResponse,
+			healthy:       true,
+			zendAlive:     boolPointer(true),
+			zendReachable: true,
+			lag:           int64Pointer(0),
+		},
+		"indexing": {
+			info:          &zen.BlockchainInfo{Blocks: 104, Headers: 104},
+			block:         blockResponse,
+			healthy:       true,
+			zendReachable: true,
+			lag:           int64Pointer(4),
+		},
+		"zend IBD": {
+			info: &zen.BlockchainInfo{
+				Blocks:               50,
+				Headers:              102,
+				VerificationProgress: 0.4,
+				InitialBlockDownload: true,
+			},
+			block:         blockResponse,
+			healthy:       true,
+			zendReachable: true,
+			lag:           int64Pointer(2),
+			stage:         stageZendIBD,
+		},
+		"zend catching up": {
+			info:          &zen.BlockchainInfo{Blocks: 100, Headers: 104},
+			block:         blockResponse,
+			healthy:       true,
+			zendReachable: true,
+			lag:           int64Pointer(4),
+			stage:         stageZendIBD,
+		},
+		"nothing indexed": {
+			info:          &zen.BlockchainInfo{Blocks: 10, Headers: 10},
+			blockErr:      errors.New("head block not found"),
+			healthy:       true,
+			zendReachable: true,
+			lag:           int64Pointer(11),
+		},
+		"zend unreachable": {
+			infoErr: errors.New("connection refused"),
+			healthy: true,
+		},
+		"zend exited": {
+			node:      &mockNode{state: zen.NodeStopped, lastError: "Error: unable to bind"},
+			infoErr:   errors.New("connection refused"),
+			zendAlive: boolPointer(false),
+		},
+		"database error": {
+			databaseErr:   errors.New("database closed"),
+			info:          &zen.BlockchainInfo{Blocks: 100, Headers: 100},
+			zendReachable: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &mocks.Client{}
+			mockIndexer := &mocks.Indexer{}
+			servicer := NewHealthAPIService(cfg, mockClient, mockIndexer, test.node)
+
+			mockIndexer.On("CheckDatabase", ctx).Return(test.databaseErr).Once()
+			mockClient.On("BlockchainInfo", ctx).Return(test.info, test.infoErr).Once()
+			if test.databaseErr == nil && test.infoErr == nil {
+				mockIndexer.On(
+					"GetBlockLazy",
+					ctx,
+					(*types.PartialBlockIdentifier)(nil),
+				).Return(
+					test.block,
+					test.blockErr,
+				).Once()
+			}
+
+			response := servicer.Health(ctx)
+			assert.Equal(t, test.healthy, response.Healthy)
+			assert.Equal(t, test.ready, response.Ready)
+			assert.Equal(t, test.databaseErr == nil, *response.DatabaseHealthy)
+			assert.Equal(t, test.zendAlive, response.ZendAlive)
+			assert.Equal(t, test.zendReachable, *response.ZendReachable)
+			assert.Equal(t, test.lag, response.Lag)
+			assert.Equal(t, int64(3), response.MaxLag)
+			if test.node != nil {
+				assert.Equal(t, string(test.node.State()), *response.ZendState)
+				assert.Equal(t, test.node.LastError(), response.ZendError)
+			} else {
+				assert.Nil(t, response.ZendState)
+			}
+			if test.healthy && test.ready {
+				assert.Empty(t, response.Errors)
+				assert.Equal(t, stageSynced, *response.SyncStatus.Stage)
+			}
+			if len(test.stage) > 0 {
+				assert.Equal(t, test.stage, *response.SyncStatus.Stage)
+				assert.Equal(t, test.info.Blocks, response.SyncStatus.CurrentIndex)
+				assert.Equal(t, test.info.VerificationProgress, *response.ZendVerificationProgress)
+			}
+
+			mockClient.AssertExpectations(t)
+			mockIndexer.AssertExpectations(t)
+		})
+	}
+}
+
+type staticHealth struct {
+	response *HealthResponse
+}
+
+func (s *staticHealth) Health(context.Context) *HealthResponse {
+	return s.response
+}
+
+func TestHealthAPIController(t *testing.T) {
+	tests := map[string]struct {
+		response *HealthResponse
+
+		healthzStatus int
+		readyzStatus  int
+	}{
+		"ready": {
+			response:      &HealthResponse{Healthy: true, Ready: true},
+			healthzStatus: http.StatusOK,
+			readyzStatus:  http.StatusOK,
+		},
+		"syncing": {
+			response:      &HealthResponse{Healthy: true},
+			healthzStatus: http.StatusOK,
+			readyzStatus:  http.StatusServiceUnavailable,
+		},
+		"unhealthy": {
+			response:      &HealthResponse{},
+			healthzStatus: http.StatusServiceUnavailable,
+			readyzStatus:  http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := &HealthAPIController{
+				service: &staticHealth{response: test.response},
+			}
+
+			w := httptest.NewRecorder()
+			controller.Healthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			assert.Equal(t, test.healthzStatus, w.Code)
+
+			w = httptest.NewRecorder()
+			controller.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, test.readyzStatus, w.Code)
+		})
+	}
+}
+
+func int64Pointer(v int64) *int64 {
+	return &v
+}
+
+func boolPointer(v bool) *bool {
+	return &v
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/storage"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// mempoolTransaction is the set of coins spent and created
+// by a transaction in the mempool.
+type mempoolTransaction struct {
+	spent   []string
+	created []*storage.AccountCoin
+}
+
+// MempoolCoins caches the coins spent and created by the
+// transactions in the mempool. A transaction is only fetched
+// and parsed the first time it is seen, so each mempool
+// snapshot costs a single getrawmempool request plus one
+// getrawtransaction request per new transaction.
+type MempoolCoins struct {
+	client Client
+
+	transactions map[string]*mempoolTransaction
+	mutex        sync.Mutex
+}
+
+// NewMempoolCoins returns a new *MempoolCoins.
+func NewMempoolCoins(client Client) *MempoolCoins {
+	return &MempoolCoins{
+		client:       client,
+		transactions: map[string]*mempoolTransaction{},
+	}
+}
+
+// Changes returns the coins spent by transactions in
+// the mempool and the coins they create for account. Transactions
+// that leave the mempool while we are fetching them are skipped.
+func (m *MempoolCoins) Changes(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+) (map[string]struct{}, []*types.Coin, *types.Error) {
+	hashes, err := m.client.RawMempool(ctx)
+	if err != nil {
+		return nil, nil, wrapClientErr(ErrBitcoind, err)
+	}
+
+	transactions := make(map[string]*mempoolTransaction, len(hashes))
+	for _, hash := range hashes {
+		m.mutex.Lock()
+		transaction, ok := m.transactions[hash]
+		m.mutex.Unlock()
+
+		if !ok {
+			var rErr *types.Error
+			transaction, rErr = m.fetch(ctx, hash)
+			if rErr != nil {
+				return nil, nil, rErr
+			}
+			if transaction == nil {
+				continue
+			}
+		}
+
+		transactions[hash] = transaction
+	}
+
+	// Transactions that are no longer in the mempool are
+	// dropped from the cache.
+	m.mutex.Lock()
+	m.transactions = transactions
+	m.mutex.Unlock()
+
+	spent := map[string]struct{}{}
+	created := []*types.Coin{}
+	for _, hash := range hashes {
+		transaction, ok := transactions[hash]
+		if !ok {
+			continue
+		}
+
+		for _, coin := range transaction.spent {
+			spent[coin] = struct{}{}
+		}
+
+		for _, coin := range transaction.created {
+			if types.Hash(coin.Account) != types.Hash(account) {
+				continue
+			}
+
+			created = append(created, coin.Coin)
+		}
+	}
+
+	return spent, created, nil
+}
+
+// fetch returns the coins spent and created by the
+// mempool transaction with the provided hash. If the
+// transaction has left the mempool, nil is returned.
+func (m *MempoolCoins) fetch(
+	ctx context.Context,
+	hash string,
+) (*mempoolTransaction, *types.Error) {
+	rawTransaction, coins, err := m.client.GetRawTransaction(ctx, hash)
+	if errors.Is(err, zen.ErrTransactionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapClientErr(ErrBitcoind, err)
+	}
+
+	transaction, err := parseOutputs(ctx, m.client, rawTransaction)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	created := []*storage.AccountCoin{}
+	for _, op := range transaction.Operations {
+		if op.CoinChange == nil || op.CoinChange.CoinAction != types.CoinCreated {
+			continue
+		}
+
+		if op.Account == nil {
+			continue
+		}
+
+		created = append(created, &storage.AccountCoin{
+			Account: op.Account,
+			Coin: &types.Coin{
+				CoinIdentifier: op.CoinChange.CoinIdentifier,
+				Amount:         op.Amount,
+			},
+		})
+	}
+
+	return &mempoolTransaction{spent: coins, created: created}, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/storage"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMempoolCoins(t *testing.T) {
+	mockClient := &mocks.Client{}
+	mempoolCoins := NewMempoolCoins(mockClient)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{Address: "account"}
+	other := &types.AccountIdentifier{Address: "other"}
+
+	// txA spends x:0 and creates txA:0 (ours). txB spends
+	// y:0 and creates txB:0 (someone else's).
+	mockTransaction := func(hash string, spent string, owner *types.AccountIdentifier) {
+		raw := &zen.Transaction{
+			Hash:    hash,
+			Outputs: []*zen.Output{{Value: 0.00000005, Index: 0}},
+		}
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			hash,
+		).Return(
+			raw,
+			[]string{spent},
+			nil,
+		).Once()
+		mockClient.On(
+			"ParseTransaction",
+			ctx,
+			raw,
+			map[string]*storage.AccountCoin{},
+		).Return(
+			&types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+				Operations: []*types.Operation{
+					{
+						Type:    zen.OutputOpType,
+						Account: owner,
+						Amount: &types.Amount{
+							Value:    "5",
+							Currency: zen.MainnetCurrency,
+						},
+						CoinChange: &types.CoinChange{
+							CoinIdentifier: &types.CoinIdentifier{Identifier: hash + ":0"},
+							CoinAction:     types.CoinCreated,
+						},
+					},
+				},
+			},
+			nil,
+		).Once()
+	}
+	createdCoin := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: "txA:0"},
+		Amount: &types.Amount{
+			Value:    "5",
+			Currency: zen.MainnetCurrency,
+		},
+	}
+
+	t.Run("fetches new transactions", func(t *testing.T) {
+		mockClient.On("RawMempool", ctx).Return([]string{"txA", "txB"}, nil).Once()
+		mockTransaction("txA", "x:0", account)
+		mockTransaction("txB", "y:0", other)
+
+		spent, created, err := mempoolCoins.Changes(ctx, account)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]struct{}{"x:0": {}, "y:0": {}}, spent)
+		assert.Equal(t, []*types.Coin{createdCoin}, created)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("reuses cached transactions", func(t *testing.T) {
+		mockClient.On("RawMempool", ctx).Return([]string{"txA", "txB"}, nil).Twice()
+
+		spent, created, err := mempoolCoins.Changes(ctx, account)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]struct{}{"x:0": {}, "y:0": {}}, spent)
+		assert.Equal(t, []*types.Coin{createdCoin}, created)
+
+		spent, created, err = mempoolCoins.Changes(ctx, other)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]struct{}{"x:0": {}, "y:0": {}}, spent)
+		assert.Equal(t, []*types.Coin{
+			{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: "txB:0"},
+				Amount:         createdCoin.Amount,
+			},
+		}, created)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("drops transactions that left the mempool", func(t *testing.T) {
+		mockClient.On("RawMempool", ctx).Return([]string{"txB"}, nil).Once()
+
+		spent, created, err := mempoolCoins.Changes(ctx, account)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]struct{}{"y:0": {}}, spent)
+		assert.Equal(t, []*types.Coin{}, created)
+
+		// txA re-enters the mempool and is fetched again.
+		mockClient.On("RawMempool", ctx).Return([]string{"txA", "txB"}, nil).Once()
+		mockTransaction("txA", "x:0", account)
+
+		spent, created, err = mempoolCoins.Changes(ctx, account)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]struct{}{"x:0": {}, "y:0": {}}, spent)
+		assert.Equal(t, []*types.Coin{createdCoin}, created)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("mempool unavailable", func(t *testing.T) {
+		mockClient.On("RawMempool", ctx).Return(nil, zen.ErrJSONRPCError).Once()
+
+		spent, created, err := mempoolCoins.Changes(ctx, account)
+		assert.Nil(t, spent)
+		assert.Nil(t, created)
+		assert.Equal(t, ErrBitcoind.Code, err.Code)
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -16,9 +16,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
 	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -26,16 +31,19 @@ import (
 type MempoolAPIService struct {
 	config *configuration.Configuration
 	client Client
+	i      Indexer
 }
 
 // NewMempoolAPIService creates a new instance of a MempoolAPIService.
 func NewMempoolAPIService(
 	config *configuration.Configuration,
 	client Client,
+	i Indexer,
 ) server.MempoolAPIServicer {
 	return &MempoolAPIService{
 		config: config,
 		client: client,
+		i:      i,
 	}
 }
 
@@ -50,7 +58,7 @@ func (s *MempoolAPIService) Mempool(
 
 	mempoolTransactions, err := s.client.RawMempool(ctx)
 	if err != nil {
-		return nil, wrapErr(ErrBitcoind, err)
+		return nil, wrapClientErr(ErrBitcoind, err)
 	}
 
 	transactionIdentifiers := make([]*types.TransactionIdentifier, len(mempoolTransactions))
@@ -72,5 +80,160 @@ func (s *MempoolAPIService) MempoolTransaction(
 		return nil, wrapErr(ErrUnavailableOffline, nil)
 	}
 
-	return nil, wrapErr(ErrUnimplemented, nil)
+	rawTransaction, coins, err := s.client.GetRawTransaction(
+		ctx,
+		request.TransactionIdentifier.Hash,
+	)
+	if errors.Is(err, zen.ErrTransactionNotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapClientErr(ErrBitcoind, err)
+	}
+
+	coinMap, err := s.findCoins(ctx, coins)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToGetCoins, err)
+	}
+
+	transaction, err := s.client.ParseTransaction(ctx, rawTransaction, coinMap)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.MempoolTransactionResponse{
+		Transaction: transaction,
+	}, nil
+}
+
+// findCoins returns the coins spent by a mempool transaction. Coins
+// created on-chain are fetched from the indexer. Coins that are not
+// in the indexer are looked up in the outputs of the transaction that
+// created them, which handles chains of unconfirmed transactions.
+func (s *MempoolAPIService) findCoins(
+	ctx context.Context,
+	coins []string,
+) (map[string]*storage.AccountCoin, error) {
+	coinMap := map[string]*storage.AccountCoin{}
+	parents := map[string]*types.Transaction{}
+	for _, coinIdentifier := range coins {
+		coin, owner, err := s.i.GetCoin(
+			ctx,
+			&types.CoinIdentifier{Identifier: coinIdentifier},
+		)
+		if err == nil {
+			coinMap[coinIdentifier] = &storage.AccountCoin{
+				Account: owner,
+				Coin:    coin,
+			}
+			continue
+		}
+
+		if !errors.Is(err, storage.ErrCoinNotFound) {
+			return nil, fmt.Errorf("%w: unable to lookup coin %s", err, coinIdentifier)
+		}
+
+		parentHash := zen.TransactionHash(coinIdentifier)
+		parent, ok := parents[parentHash]
+		if !ok {
+			parent, err = s.parseParentOutputs(ctx, parentHash)
+			if err != nil {
+				return nil, err
+			}
+
+			parents[parentHash] = parent
+		}
+
+		accountCoin := createdCoin(parent.Operations, coinIdentifier)
+		if accountCoin == nil {
+			return nil, fmt.Errorf(
+				"coin %s is not created by transaction %s",
+				coinIdentifier,
+				parentHash,
+			)
+		}
+
+		coinMap[coinIdentifier] = accountCoin
+	}
+
+	return coinMap, nil
+}
+
+// parseParentOutputs fetches a transaction from zend and parses
+// only its outputs. We never need the inputs of a parent to
+// hydrate the coins it creates.
+func (s *MempoolAPIService) parseParentOutputs(
+	ctx context.Context,
+	hash string,
+) (*types.Transaction, error) {
+	rawParent, _, err := s.client.GetRawTransaction(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch parent transaction %s", err, hash)
+	}
+
+	parent, err := parseOutputs(ctx, s.client, rawParent)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse parent transaction %s", err, hash)
+	}
+
+	return parent, nil
+}
+
+// parseOutputs parses only the outputs of transaction, so
+// none of the coins it spends need to be looked up.
+func parseOutputs(
+	ctx context.Context,
+	client Client,
+	transaction *zen.Transaction,
+) (*types.Transaction, error) {
+	return client.ParseTransaction(
+		ctx,
+		&zen.Transaction{
+			Hash:    transaction.Hash,
+			Outputs: transaction.Outputs,
+		},
+		map[string]*storage.AccountCoin{},
+	)
+}
+
+// createdCoin returns the *storage.AccountCoin created
+// by an operation in operations with the provided
+// coin identifier (if it exists).
+func createdCoin(
+	operations []*types.Operation,
+	coinIdentifier string,
+) *storage.AccountCoin {
+	for _, op := range operations {
+		if op.CoinChange == nil || op.CoinChange.CoinAction != types.CoinCreated {
+			continue
+		}
+
+		if op.CoinChange.CoinIdentifier.Identifier != coinIdentifier {
+			continue
+		}
+
+		return &storage.AccountCoin{
+			Account: op.Account,
+			Coin: &types.Coin{
+				CoinIdentifier: op.CoinChange.CoinIdentifier,
+				Amount:         op.Amount,
+			},
+		}
+	}
+
+	return nil
+}
+
+// unspentCoins returns the coins that are not in spent.
+func unspentCoins(coins []*types.Coin, spent map[string]struct{}) []*types.Coin {
+	unspent := []*types.Coin{}
+	for _, coin := range coins {
+		if _, ok := spent[coin.CoinIdentifier.Identifier]; ok {
+			continue
+		}
+
+		unspent = append(unspent, coin)
+	}
+
+	return unspent
 }
@@ -16,10 +16,14 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/HorizenOfficial/rosetta-zen/configuration"
 	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,7 +33,8 @@ func TestMempoolEndpoints_Offline(t *testing.T) {
 		Mode: configuration.Offline,
 	}
 	mockClient := &mocks.Client{}
-	servicer := NewMempoolAPIService(cfg, mockClient)
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
 	ctx := context.Background()
 	mem, err := servicer.Mempool(ctx, nil)
 	assert.Nil(t, mem)
@@ -41,6 +46,7 @@ func TestMempoolEndpoints_Offline(t *testing.T) {
 	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
 	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
 }
 
 func TestMempoolEndpoints_Online(t *testing.T) {
@@ -49,7 +55,8 @@ func TestMempoolEndpoints_Online(t *testing.T) {
 	}
 
 	mockClient := &mocks.Client{}
-	servicer := NewMempoolAPIService(cfg, mockClient)
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
 	ctx := context.Background()
 
 	mockClient.On("RawMempool", ctx).Return([]string{
@@ -69,9 +76,266 @@ func TestMempoolEndpoints_Online(t *testing.T) {
 		},
 	}, mem)
 
-	memTransaction, err := servicer.MempoolTransaction(ctx, nil)
-	assert.Nil(t, memTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
 	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestMempoolTransaction(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: zen.MainnetCurrency,
+	}
+
+	confirmedCoin := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{
+			Identifier: "9401f535c210f3ff362d3f51dba88ecddf4f87ed9d0563c1f9e8af75eca1fd1a:0",
+		},
+		Amount: &types.Amount{
+			Value:    "1000",
+			Currency: zen.MainnetCurrency,
+		},
+	}
+	confirmedOwner := &types.AccountIdentifier{
+		Address: "ztjySYJL8g9i6wc2YTusbDpPZSpPM5xuTua",
+	}
+	unconfirmedCoin := &storage.AccountCoin{
+		Account: &types.AccountIdentifier{
+			Address: "ztrEXsPLywPcxE3Sn9qdWV6tYkBH4HnYwin",
+		},
+		Coin: &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{
+				Identifier: "14e8fe02ec4e237d8cb6bf95943bd05706a19f6bd29f9b2b1fefc4fa09ef6737:1",
+			},
+			Amount: &types.Amount{
+				Value:    "500",
+				Currency: zen.MainnetCurrency,
+			},
+		},
+	}
+
+	rawTransaction := &zen.Transaction{
+		Hash: "67c76a34cb6bde6f9628fdc8348c23191d3222e88386ed05c97e3c63384a01af",
+		Inputs: []*zen.Input{
+			{
+				TxHash: "9401f535c210f3ff362d3f51dba88ecddf4f87ed9d0563c1f9e8af75eca1fd1a",
+				Vout:   0,
+			},
+			{
+				TxHash: "14e8fe02ec4e237d8cb6bf95943bd05706a19f6bd29f9b2b1fefc4fa09ef6737",
+				Vout:   1,
+			},
+		},
+	}
+	rawParent := &zen.Transaction{
+		Hash: "14e8fe02ec4e237d8cb6bf95943bd05706a19f6bd29f9b2b1fefc4fa09ef6737",
+		Inputs: []*zen.Input{
+			{
+				TxHash: "4c292f9ba0e94f2d48a16f8765217e62b6673796bffd92c26b13ed5e661946bc",
+				Vout:   1,
+			},
+		},
+		Outputs: []*zen.Output{
+			{
+				Value: 0.000005,
+				Index: 1,
+			},
+		},
+	}
+	coins := []string{
+		confirmedCoin.CoinIdentifier.Identifier,
+		unconfirmedCoin.Coin.CoinIdentifier.Identifier,
+	}
+
+	parsedTransaction := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: rawTransaction.Hash,
+		},
+	}
+	parsedParent := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: rawParent.Hash,
+		},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{
+					Index: 0,
+				},
+				Type:    zen.OutputOpType,
+				Account: unconfirmedCoin.Account,
+				Amount:  unconfirmedCoin.Coin.Amount,
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: unconfirmedCoin.Coin.CoinIdentifier,
+					CoinAction:     types.CoinCreated,
+				},
+			},
+		},
+	}
+
+	t.Run("chained unconfirmed spend", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
+		ctx := context.Background()
+
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			rawTransaction.Hash,
+		).Return(
+			rawTransaction,
+			coins,
+			nil,
+		).Once()
+		mockIndexer.On(
+			"GetCoin",
+			ctx,
+			confirmedCoin.CoinIdentifier,
+		).Return(
+			confirmedCoin,
+			confirmedOwner,
+			nil,
+		).Once()
+		mockIndexer.On(
+			"GetCoin",
+			ctx,
+			unconfirmedCoin.Coin.CoinIdentifier,
+		).Return(
+			nil,
+			nil,
+			storage.ErrCoinNotFound,
+		).Once()
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			rawParent.Hash,
+		).Return(
+			rawParent,
+			[]string{"4c292f9ba0e94f2d48a16f8765217e62b6673796bffd92c26b13ed5e661946bc:1"},
+			nil,
+		).Once()
+		mockClient.On(
+			"ParseTransaction",
+			ctx,
+			&zen.Transaction{
+				Hash:    rawParent.Hash,
+				Outputs: rawParent.Outputs,
+			},
+			map[string]*storage.AccountCoin{},
+		).Return(
+			parsedParent,
+			nil,
+		).Once()
+		mockClient.On(
+			"ParseTransaction",
+			ctx,
+			rawTransaction,
+			map[string]*storage.AccountCoin{
+				confirmedCoin.CoinIdentifier.Identifier: {
+					Account: confirmedOwner,
+					Coin:    confirmedCoin,
+				},
+				unconfirmedCoin.Coin.CoinIdentifier.Identifier: unconfirmedCoin,
+			},
+		).Return(
+			parsedTransaction,
+			nil,
+		).Once()
+
+		memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: parsedTransaction.TransactionIdentifier,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.MempoolTransactionResponse{
+			Transaction: parsedTransaction,
+		}, memTransaction)
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+
+	t.Run("transaction not in mempool", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
+		ctx := context.Background()
+
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			rawTransaction.Hash,
+		).Return(
+			nil,
+			nil,
+			fmt.Errorf("%w: error fetching transaction", zen.ErrTransactionNotFound),
+		).Once()
+
+		memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: parsedTransaction.TransactionIdentifier,
+		})
+		assert.Nil(t, memTransaction)
+		assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+
+	t.Run("coin lookup failure", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockIndexer := &mocks.Indexer{}
+		servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
+		ctx := context.Background()
+
+		mockClient.On(
+			"GetRawTransaction",
+			ctx,
+			rawTransaction.Hash,
+		).Return(
+			rawTransaction,
+			coins,
+			nil,
+		).Once()
+		mockIndexer.On(
+			"GetCoin",
+			ctx,
+			confirmedCoin.CoinIdentifier,
+		).Return(
+			nil,
+			nil,
+			storage.ErrCoinLookupFailed,
+		).Once()
+
+		memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: parsedTransaction.TransactionIdentifier,
+		})
+		assert.Nil(t, memTransaction)
+		assert.Equal(t, ErrUnableToGetCoins.Code, err.Code)
+		mockClient.AssertExpectations(t)
+		mockIndexer.AssertExpectations(t)
+	})
+}
+
+func TestMempoolEndpoints_CircuitOpen(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+
+	mockClient := &mocks.Client{}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewMempoolAPIService(cfg, mockClient, mockIndexer)
+	ctx := context.Background()
+
+	mockClient.On("RawMempool", ctx).Return(
+		nil,
+		fmt.Errorf("%w: error fetching raw mempool", zen.ErrCircuitOpen),
+	).Once()
+	mem, err := servicer.Mempool(ctx, nil)
+	assert.Nil(t, mem)
+	assert.Equal(t, ErrNotReady.Code, err.Code)
+	assert.True(t, err.Retriable)
+
+	mockClient.On("RawMempool", ctx).Return(nil, zen.ErrJSONRPCError).Once()
+	mem, err = servicer.Mempool(ctx, nil)
+	assert.Nil(t, mem)
+	assert.Equal(t, ErrBitcoind.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+	mockIndexer.AssertExpectations(t)
 }
@@ -0,0 +1,88 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// unknownEndpoint is the endpoint label of requests
+	// to paths not served by the router.
+	unknownEndpoint = "unknown"
+
+	// unknownErrorCode is the code label of failed
+	// requests that did not return a *types.Error.
+	unknownErrorCode = "unknown"
+)
+
+// errorRecorder is a StatusRecorder that also keeps the
+// body of unsuccessful responses so that the code of the
+// returned *types.Error can be recorded.
+type errorRecorder struct {
+	*StatusRecorder
+	body bytes.Buffer
+}
+
+// Write keeps the body of unsuccessful responses.
+func (r *errorRecorder) Write(b []byte) (int, error) {
+	if r.Code != http.StatusOK {
+		r.body.Write(b)
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+// errorCode returns the code of the *types.Error
+// returned by an unsuccessful request.
+func (r *errorRecorder) errorCode() string {
+	var rosettaErr types.Error
+	if err := json.Unmarshal(r.body.Bytes(), &rosettaErr); err != nil || len(rosettaErr.Message) == 0 {
+		return unknownErrorCode
+	}
+
+	return strconv.FormatInt(int64(rosettaErr.Code), 10)
+}
+
+// MetricsMiddleware records the latency of each request
+// and the error code of each failed request by endpoint.
+func MetricsMiddleware(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &errorRecorder{StatusRecorder: NewStatusRecorder(w)}
+
+		inner.ServeHTTP(recorder, r)
+
+		// Only label known endpoints to bound the
+		// cardinality of the metrics.
+		endpoint := r.URL.Path
+		if recorder.Code == http.StatusNotFound || recorder.Code == http.StatusMethodNotAllowed {
+			endpoint = unknownEndpoint
+		}
+
+		metrics.APIDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		if recorder.Code != http.StatusOK {
+			metrics.APIErrors.WithLabelValues(endpoint, recorder.errorCode()).Inc()
+		}
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics-test/ok", func(w http.ResponseWriter, r *http.Request) {
+		server.EncodeJSONResponse(map[string]string{}, http.StatusOK, w)
+	})
+	mux.HandleFunc("/metrics-test/error", func(w http.ResponseWriter, r *http.Request) {
+		server.EncodeJSONResponse(ErrUnimplemented, http.StatusInternalServerError, w)
+	})
+	mux.HandleFunc("/metrics-test/panic", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	handler := MetricsMiddleware(mux)
+
+	tests := map[string]struct {
+		path string
+		code int
+
+		endpoint  string
+		errorCode string
+	}{
+		"success": {
+			path:     "/metrics-test/ok",
+			code:     http.StatusOK,
+			endpoint: "/metrics-test/ok",
+		},
+		"rosetta error": {
+			path:      "/metrics-test/error",
+			code:      http.StatusInternalServerError,
+			endpoint:  "/metrics-test/error",
+			errorCode: "0",
+		},
+		"other error": {
+			path:      "/metrics-test/panic",
+			code:      http.StatusInternalServerError,
+			endpoint:  "/metrics-test/panic",
+			errorCode: unknownErrorCode,
+		},
+		"unknown endpoint": {
+			path:      "/metrics-test/missing",
+			code:      http.StatusNotFound,
+			endpoint:  unknownEndpoint,
+			errorCode: unknownErrorCode,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var errorsBefore float64
+			if len(test.errorCode) > 0 {
+				errorsBefore = testutil.ToFloat64(
+					metrics.APIErrors.WithLabelValues(test.endpoint, test.errorCode),
+				)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, test.path, nil))
+			assert.Equal(t, test.code, w.Code)
+
+			if len(test.errorCode) > 0 {
+				assert.Equal(t, errorsBefore+1, testutil.ToFloat64(
+					metrics.APIErrors.WithLabelValues(test.endpoint, test.errorCode),
+				))
+			}
+		})
+	}
+
+	// All requests are timed
+	assert.Equal(t, 4, testutil.CollectAndCount(metrics.APIDuration))
+}
@@ -67,7 +67,7 @@ func (s *NetworkAPIService) NetworkStatus(
 
 	peers, err := s.client.GetPeers(ctx)
 	if err != nil {
-		return nil, wrapErr(ErrBitcoind, err)
+		return nil, wrapClientErr(ErrBitcoind, err)
 	}
 
 	cachedBlockResponse, err := s.i.GetBlockLazy(ctx, nil)
@@ -75,11 +75,21 @@ func (s *NetworkAPIService) NetworkStatus(
 		return nil, wrapErr(ErrNotReady, nil)
 	}
 
+	info, err := s.client.BlockchainInfo(ctx)
+	if err != nil {
+		return nil, wrapClientErr(ErrBitcoind, err)
+	}
+
 	return &types.NetworkStatusResponse{
 		CurrentBlockIdentifier: cachedBlockResponse.Block.BlockIdentifier,
 		CurrentBlockTimestamp:  cachedBlockResponse.Block.Timestamp,
 		GenesisBlockIdentifier: s.config.GenesisBlockIdentifier,
-		Peers:                  peers,
+		SyncStatus: syncStatus(
+			cachedBlockResponse.Block.BlockIdentifier.Index,
+			info,
+			s.config.MaxSyncLag,
+		),
+		Peers: peers,
 	}, nil
 }
 
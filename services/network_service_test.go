@@ -70,6 +70,8 @@ func TestNetworkEndpoints_Offline(t *testing.T) {
 	networkOptions, err := servicer.NetworkOptions(ctx, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, defaultNetworkOptions, networkOptions)
+	assert.Contains(t, networkOptions.Allow.OperationTypes, zen.ForwardTransferOpType)
+	assert.Contains(t, networkOptions.Allow.OperationTypes, zen.BackwardTransferOpType)
 
 	mockIndexer.AssertExpectations(t)
 	mockClient.AssertExpectations(t)
@@ -80,6 +82,7 @@ func TestNetworkEndpoints_Online(t *testing.T) {
 		Mode:                   configuration.Online,
 		Network:                networkIdentifier,
 		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
+		MaxSyncLag:             3,
 	}
 	mockIndexer := &mocks.Indexer{}
 	mockClient := &mocks.Client{}
@@ -113,11 +116,22 @@ func TestNetworkEndpoints_Online(t *testing.T) {
 		blockResponse,
 		nil,
 	)
+	targetIndex := int64(110)
+	stage := stageIndexing
+	mockClient.On("BlockchainInfo", ctx).Return(&zen.BlockchainInfo{
+		Blocks:  110,
+		Headers: 110,
+	}, nil).Once()
 	networkStatus, err := servicer.NetworkStatus(ctx, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, &types.NetworkStatusResponse{
 		GenesisBlockIdentifier: zen.MainnetGenesisBlockIdentifier,
 		CurrentBlockIdentifier: blockResponse.Block.BlockIdentifier,
+		SyncStatus: &types.SyncStatus{
+			CurrentIndex: 100,
+			TargetIndex:  &targetIndex,
+			Stage:        &stage,
+		},
 		Peers: []*types.Peer{
 			{
 				PeerID: "77.93.223.9:8333",
@@ -125,6 +139,39 @@ func TestNetworkEndpoints_Online(t *testing.T) {
 		},
 	}, networkStatus)
 
+	// The indexer is synced within MaxSyncLag blocks
+	targetIndex = 103
+	stage = stageSynced
+	mockClient.On("BlockchainInfo", ctx).Return(&zen.BlockchainInfo{
+		Blocks:  103,
+		Headers: 103,
+	}, nil).Once()
+	networkStatus, err = servicer.NetworkStatus(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.SyncStatus{
+		CurrentIndex: 100,
+		TargetIndex:  &targetIndex,
+		Stage:        &stage,
+	}, networkStatus.SyncStatus)
+
+	// zend is still downloading the blockchain, so
+	// its progress is reported instead
+	targetIndex = 1000
+	stage = stageZendIBD
+	mockClient.On("BlockchainInfo", ctx).Return(&zen.BlockchainInfo{
+		Blocks:               500,
+		Headers:              1000,
+		VerificationProgress: 0.5,
+		InitialBlockDownload: true,
+	}, nil).Once()
+	networkStatus, err = servicer.NetworkStatus(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.SyncStatus{
+		CurrentIndex: 500,
+		TargetIndex:  &targetIndex,
+		Stage:        &stage,
+	}, networkStatus.SyncStatus)
+
 	networkOptions, err := servicer.NetworkOptions(ctx, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, defaultNetworkOptions, networkOptions)
@@ -29,6 +29,7 @@ func NewBlockchainRouter(
 	config *configuration.Configuration,
 	client Client,
 	i Indexer,
+	node NodeProcess,
 	asserter *asserter.Asserter,
 ) http.Handler {
 	networkAPIService := NewNetworkAPIService(config, client, i)
@@ -43,29 +44,56 @@ func NewBlockchainRouter(
 		asserter,
 	)
 
-	accountAPIService := NewAccountAPIService(config, i)
+	// The account and construction services share the coins
+	// spent and created by the mempool.
+	mempoolCoins := NewMempoolCoins(client)
+
+	accountAPIService := NewAccountAPIService(config, client, i, mempoolCoins)
 	accountAPIController := server.NewAccountAPIController(
 		accountAPIService,
 		asserter,
 	)
+	accountCoinsAPIController := NewAccountCoinsAPIController(
+		accountAPIService,
+		asserter,
+	)
 
-	constructionAPIService := NewConstructionAPIService(config, client, i)
+	constructionAPIService := NewConstructionAPIService(config, client, i, mempoolCoins)
 	constructionAPIController := server.NewConstructionAPIController(
 		constructionAPIService,
 		asserter,
 	)
 
-	mempoolAPIService := NewMempoolAPIService(config, client)
+	mempoolAPIService := NewMempoolAPIService(config, client, i)
 	mempoolAPIController := server.NewMempoolAPIController(
 		mempoolAPIService,
 		asserter,
 	)
 
+	searchAPIService := NewSearchAPIService(config, i)
+	searchAPIController := NewSearchAPIController(
+		searchAPIService,
+		asserter,
+	)
+
+	broadcastAPIService := NewBroadcastAPIService(config, i)
+	broadcastAPIController := NewBroadcastAPIController(
+		broadcastAPIService,
+		asserter,
+	)
+
+	healthAPIService := NewHealthAPIService(config, client, i, node)
+	healthAPIController := NewHealthAPIController(healthAPIService)
+
 	return server.NewRouter(
 		networkAPIController,
 		blockAPIController,
 		accountAPIController,
+		accountCoinsAPIController,
 		constructionAPIController,
 		mempoolAPIController,
+		searchAPIController,
+		broadcastAPIController,
+		healthAPIController,
 	)
 }
@@ -0,0 +1,244 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	"github.com/HorizenOfficial/rosetta-zen/search"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// defaultSearchLimit is the number of transactions
+	// returned by /search/transactions if no limit
+	// is provided.
+	defaultSearchLimit = int64(100)
+
+	// maxSearchLimit is the maximum number of transactions
+	// returned by a single /search/transactions request.
+	maxSearchLimit = int64(1000)
+
+	// andOperator is the only search operator we
+	// support. All conditions must be satisfied by
+	// the same operation.
+	andOperator = "and"
+)
+
+// SearchAPIServicer defines the api actions for the SearchAPI service.
+// The version of rosetta-sdk-go we use does not provide it.
+type SearchAPIServicer interface {
+	SearchTransactions(
+		context.Context,
+		*SearchTransactionsRequest,
+	) (*SearchTransactionsResponse, *types.Error)
+}
+
+// SearchAPIService implements the SearchAPIServicer interface.
+type SearchAPIService struct {
+	config *configuration.Configuration
+	i      Indexer
+}
+
+// NewSearchAPIService returns a new *SearchAPIService.
+func NewSearchAPIService(
+	config *configuration.Configuration,
+	i Indexer,
+) SearchAPIServicer {
+	return &SearchAPIService{
+		config: config,
+		i:      i,
+	}
+}
+
+// transactionQuery converts a *SearchTransactionsRequest
+// into a *search.TransactionQuery.
+func (s *SearchAPIService) transactionQuery(
+	request *SearchTransactionsRequest,
+) (*search.TransactionQuery, error) {
+	if request.Operator != nil && *request.Operator != andOperator {
+		return nil, errors.New("only the and operator is supported")
+	}
+
+	query := &search.TransactionQuery{
+		MaxBlock: request.MaxBlock,
+		Offset:   0,
+		Limit:    defaultSearchLimit,
+		Success:  request.Success,
+	}
+
+	if request.Offset != nil {
+		if *request.Offset < 0 {
+			return nil, errors.New("offset must not be negative")
+		}
+		query.Offset = *request.Offset
+	}
+
+	if request.Limit != nil {
+		if *request.Limit <= 0 || *request.Limit > maxSearchLimit {
+			return nil, errors.New("limit must be between 1 and 1000")
+		}
+		query.Limit = *request.Limit
+	}
+
+	if request.TransactionIdentifier != nil {
+		query.TransactionHash = request.TransactionIdentifier.Hash
+	}
+
+	if request.CoinIdentifier != nil {
+		query.CoinIdentifier = request.CoinIdentifier.Identifier
+	}
+
+	if request.Type != nil {
+		query.OperationType = *request.Type
+	}
+
+	if request.Status != nil {
+		query.Status = *request.Status
+	}
+
+	if request.AccountIdentifier != nil {
+		if request.AccountIdentifier.SubAccount != nil {
+			return nil, errors.New("sub accounts are not supported")
+		}
+		query.Address = request.AccountIdentifier.Address
+	}
+
+	if request.Address != nil {
+		if len(query.Address) > 0 && query.Address != *request.Address {
+			return nil, errors.New("address does not match account identifier")
+		}
+		query.Address = *request.Address
+	}
+
+	if len(query.TransactionHash) == 0 &&
+		len(query.Address) == 0 &&
+		len(query.CoinIdentifier) == 0 {
+		return nil, errors.New("transaction identifier, address or coin identifier must be provided")
+	}
+
+	return query, nil
+}
+
+// SearchTransactions implements /search/transactions.
+func (s *SearchAPIService) SearchTransactions(
+	ctx context.Context,
+	request *SearchTransactionsRequest,
+) (*SearchTransactionsResponse, *types.Error) {
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(ErrUnavailableOffline, nil)
+	}
+
+	query, err := s.transactionQuery(request)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidSearch, err)
+	}
+
+	// We only index a single currency, so
+	// nothing can match any other currency.
+	if request.Currency != nil && types.Hash(request.Currency) != types.Hash(s.config.Currency) {
+		return &SearchTransactionsResponse{
+			Transactions: []*search.BlockTransaction{},
+			TotalCount:   0,
+		}, nil
+	}
+
+	transactions, totalCount, err := s.i.SearchTransactions(ctx, query)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToSearchTransactions, err)
+	}
+
+	response := &SearchTransactionsResponse{
+		Transactions: transactions,
+		TotalCount:   totalCount,
+	}
+
+	nextOffset := query.Offset + int64(len(transactions))
+	if nextOffset < totalCount {
+		response.NextOffset = &nextOffset
+	}
+
+	return response, nil
+}
+
+// SearchAPIController binds http requests to a SearchAPIServicer
+// and writes the service results to the http response.
+type SearchAPIController struct {
+	service  SearchAPIServicer
+	asserter *asserter.Asserter
+}
+
+// NewSearchAPIController creates a default api controller.
+func NewSearchAPIController(
+	s SearchAPIServicer,
+	asserter *asserter.Asserter,
+) server.Router {
+	return &SearchAPIController{
+		service:  s,
+		asserter: asserter,
+	}
+}
+
+// Routes returns all of the api route for the SearchAPIController.
+func (c *SearchAPIController) Routes() server.Routes {
+	return server.Routes{
+		{
+			Name:        "SearchTransactions",
+			Method:      strings.ToUpper("Post"),
+			Pattern:     "/search/transactions",
+			HandlerFunc: c.SearchTransactions,
+		},
+	}
+}
+
+// SearchTransactions - Search for Transactions
+func (c *SearchAPIController) SearchTransactions(w http.ResponseWriter, r *http.Request) {
+	searchTransactionsRequest := &SearchTransactionsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&searchTransactionsRequest); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	// Assert that the request is for a supported network
+	if err := c.asserter.ValidSupportedNetwork(
+		searchTransactionsRequest.NetworkIdentifier,
+	); err != nil {
+		server.EncodeJSONResponse(&types.Error{
+			Message: err.Error(),
+		}, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	result, serviceErr := c.service.SearchTransactions(r.Context(), searchTransactionsRequest)
+	if serviceErr != nil {
+		server.EncodeJSONResponse(serviceErr, http.StatusInternalServerError, w)
+
+		return
+	}
+
+	server.EncodeJSONResponse(result, http.StatusOK, w)
+}
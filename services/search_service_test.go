@@ -0,0 +1,234 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/configuration"
+	mocks "github.com/HorizenOfficial/rosetta-zen/mocks/services"
+	"github.com/HorizenOfficial/rosetta-zen/search"
+	"github.com/HorizenOfficial/rosetta-zen/zen"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTransactions_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Offline,
+	}
+	mockIndexer := &mocks.Indexer{}
+	servicer := NewSearchAPIService(cfg, mockIndexer)
+	ctx := context.Background()
+
+	resp, err := servicer.SearchTransactions(ctx, &SearchTransactionsRequest{})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockIndexer.AssertExpectations(t)
+}
+
+func TestSearchTransactions_Online(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:     configuration.Online,
+		Currency: zen.MainnetCurrency,
+	}
+	ctx := context.Background()
+
+	address := "znc3p7CFNTsz1s6CceskrTxKevQLPoDK4cK"
+	opType := zen.OutputOpType
+	operator := "and"
+	orOperator := "or"
+	offset := int64(2)
+	limit := int64(2)
+	negative := int64(-1)
+	tooLarge := maxSearchLimit + 1
+	maxBlock := int64(100)
+	success := true
+	nextOffset := int64(4)
+
+	transactions := []*search.BlockTransaction{
+		{
+			BlockIdentifier: &types.BlockIdentifier{Hash: "block 2", Index: 2},
+			Transaction: &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx 2"},
+			},
+		},
+		{
+			BlockIdentifier: &types.BlockIdentifier{Hash: "block 1", Index: 1},
+			Transaction: &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx 1"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		request *SearchTransactionsRequest
+
+		query        *search.TransactionQuery
+		transactions []*search.BlockTransaction
+		totalCount   int64
+		indexerErr   error
+
+		expected    *SearchTransactionsResponse
+		expectedErr *types.Error
+	}{
+		"account with more results": {
+			request: &SearchTransactionsRequest{
+				Operator:          &operator,
+				AccountIdentifier: &types.AccountIdentifier{Address: address},
+				Type:              &opType,
+				Success:           &success,
+				MaxBlock:          &maxBlock,
+				Offset:            &offset,
+				Limit:             &limit,
+			},
+			query: &search.TransactionQuery{
+				Address:       address,
+				OperationType: opType,
+				Success:       &success,
+				MaxBlock:      &maxBlock,
+				Offset:        offset,
+				Limit:         limit,
+			},
+			transactions: transactions,
+			totalCount:   5,
+			expected: &SearchTransactionsResponse{
+				Transactions: transactions,
+				TotalCount:   5,
+				NextOffset:   &nextOffset,
+			},
+		},
+		"address and coin on last page": {
+			request: &SearchTransactionsRequest{
+				Address:        &address,
+				CoinIdentifier: &types.CoinIdentifier{Identifier: "tx 1:0"},
+			},
+			query: &search.TransactionQuery{
+				Address:        address,
+				CoinIdentifier: "tx 1:0",
+				Limit:          defaultSearchLimit,
+			},
+			transactions: transactions[1:],
+			totalCount:   1,
+			expected: &SearchTransactionsResponse{
+				Transactions: transactions[1:],
+				TotalCount:   1,
+			},
+		},
+		"transaction hash": {
+			request: &SearchTransactionsRequest{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx 2"},
+			},
+			query: &search.TransactionQuery{
+				TransactionHash: "tx 2",
+				Limit:           defaultSearchLimit,
+			},
+			transactions: transactions[:1],
+			totalCount:   1,
+			expected: &SearchTransactionsResponse{
+				Transactions: transactions[:1],
+				TotalCount:   1,
+			},
+		},
+		"other currency": {
+			request: &SearchTransactionsRequest{
+				Address:  &address,
+				Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+			},
+			expected: &SearchTransactionsResponse{
+				Transactions: []*search.BlockTransaction{},
+			},
+		},
+		"indexer error": {
+			request: &SearchTransactionsRequest{
+				Address: &address,
+			},
+			query: &search.TransactionQuery{
+				Address: address,
+				Limit:   defaultSearchLimit,
+			},
+			indexerErr:  errors.New("scan failed"),
+			expectedErr: ErrUnableToSearchTransactions,
+		},
+		"missing anchor": {
+			request: &SearchTransactionsRequest{
+				Type: &opType,
+			},
+			expectedErr: ErrInvalidSearch,
+		},
+		"or operator": {
+			request: &SearchTransactionsRequest{
+				Operator: &orOperator,
+				Address:  &address,
+			},
+			expectedErr: ErrInvalidSearch,
+		},
+		"negative offset": {
+			request: &SearchTransactionsRequest{
+				Address: &address,
+				Offset:  &negative,
+			},
+			expectedErr: ErrInvalidSearch,
+		},
+		"limit too large": {
+			request: &SearchTransactionsRequest{
+				Address: &address,
+				Limit:   &tooLarge,
+			},
+			expectedErr: ErrInvalidSearch,
+		},
+		"address mismatch": {
+			request: &SearchTransactionsRequest{
+				Address:           &address,
+				AccountIdentifier: &types.AccountIdentifier{Address: "other"},
+			},
+			expectedErr: ErrInvalidSearch,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockIndexer := &mocks.Indexer{}
+			servicer := NewSearchAPIService(cfg, mockIndexer)
+
+			if test.query != nil {
+				mockIndexer.On(
+					"SearchTransactions",
+					ctx,
+					test.query,
+				).Return(
+					test.transactions,
+					test.totalCount,
+					test.indexerErr,
+				).Once()
+			}
+
+			resp, err := servicer.SearchTransactions(ctx, test.request)
+			if test.expectedErr != nil {
+				assert.Nil(t, resp)
+				assert.Equal(t, test.expectedErr.Code, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.expected, resp)
+			}
+
+			mockIndexer.AssertExpectations(t)
+		})
+	}
+}
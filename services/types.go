@@ -17,7 +17,10 @@ package services
 import (
 	"context"
 
+	"github.com/HorizenOfficial/rosetta-zen/broadcast"
+	"github.com/HorizenOfficial/rosetta-zen/search"
 	"github.com/HorizenOfficial/rosetta-zen/zen"
+	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -29,10 +32,6 @@ const (
 	// HistoricalBalanceLookup indicates
 	// that historical balance lookup is supported.
 	HistoricalBalanceLookup = true
-
-	// inlineFetchLimit is the maximum number
-	// of transactions to fetch inline.
-	inlineFetchLimit = 100
 )
 
 var (
@@ -51,8 +50,16 @@ type Client interface {
 	SendRawTransaction(context.Context, string) (string, error)
 	SuggestedFeeRate(context.Context, int64) (float64, error)
 	RawMempool(context.Context) ([]string, error)
+	MempoolEntry(context.Context, string) (*zen.MempoolEntry, error)
+	BlockchainInfo(context.Context) (*zen.BlockchainInfo, error)
 	GetBestBlock (context.Context) (int64, error)
 	GetHashFromIndex(context.Context, int64) (string, error)
+	GetRawTransaction(context.Context, string) (*zen.Transaction, []string, error)
+	ParseTransaction(
+		context.Context,
+		*zen.Transaction,
+		map[string]*storage.AccountCoin,
+	) (*types.Transaction, error)
 }
 
 // Indexer is used by the servicers to get block and account data.
@@ -70,6 +77,10 @@ type Indexer interface {
 		context.Context,
 		*types.AccountIdentifier,
 	) ([]*types.Coin, *types.BlockIdentifier, error)
+	GetCoin(
+		context.Context,
+		*types.CoinIdentifier,
+	) (*types.Coin, *types.AccountIdentifier, error)
 	GetScriptPubKeys(
 		context.Context,
 		[]*types.Coin,
@@ -80,6 +91,116 @@ type Indexer interface {
 		*types.Currency,
 		*types.PartialBlockIdentifier,
 	) (*types.Amount, *types.BlockIdentifier, error)
+	EstimateFeeRate(context.Context, int64) (float64, error)
+	SearchTransactions(
+		context.Context,
+		*search.TransactionQuery,
+	) ([]*search.BlockTransaction, int64, error)
+	TrackTransaction(context.Context, *broadcast.Transaction) error
+	GetBroadcast(context.Context, string) (*broadcast.Transaction, error)
+	CheckDatabase(context.Context) error
+}
+
+// NodeProcess is used by the health checks to determine
+// if the zend daemon started by rosetta-zen is running
+// and which state it is in.
+type NodeProcess interface {
+	Alive() bool
+	State() zen.NodeState
+	LastError() string
+}
+
+// SearchTransactionsRequest is used to search
+// for transactions matching a set of conditions.
+// It follows the Rosetta /search/transactions
+// specification.
+type SearchTransactionsRequest struct {
+	NetworkIdentifier     *types.NetworkIdentifier     `json:"network_identifier"`
+	Operator              *string                      `json:"operator,omitempty"`
+	MaxBlock              *int64                       `json:"max_block,omitempty"`
+	Offset                *int64                       `json:"offset,omitempty"`
+	Limit                 *int64                       `json:"limit,omitempty"`
+	TransactionIdentifier *types.TransactionIdentifier `json:"transaction_identifier,omitempty"`
+	AccountIdentifier     *types.AccountIdentifier     `json:"account_identifier,omitempty"`
+	CoinIdentifier        *types.CoinIdentifier        `json:"coin_identifier,omitempty"`
+	Currency              *types.Currency              `json:"currency,omitempty"`
+	Status                *string                      `json:"status,omitempty"`
+	Type                  *string                      `json:"type,omitempty"`
+	Address               *string                      `json:"address,omitempty"`
+	Success               *bool                        `json:"success,omitempty"`
+}
+
+// SearchTransactionsResponse contains an ordered collection
+// of BlockTransactions that match the query in
+// SearchTransactionsRequest. If there are more results,
+// NextOffset is populated.
+type SearchTransactionsResponse struct {
+	Transactions []*search.BlockTransaction `json:"transactions"`
+	TotalCount   int64                      `json:"total_count"`
+	NextOffset   *int64                     `json:"next_offset,omitempty"`
+}
+
+// AccountCoinsRequest is used to fetch the unspent
+// coins of an account. It follows the Rosetta
+// /account/coins specification.
+type AccountCoinsRequest struct {
+	NetworkIdentifier *types.NetworkIdentifier `json:"network_identifier"`
+	AccountIdentifier *types.AccountIdentifier `json:"account_identifier"`
+	IncludeMempool    bool                     `json:"include_mempool"`
+	Currencies        []*types.Currency        `json:"currencies,omitempty"`
+}
+
+// AccountCoinsResponse contains the unspent coins of
+// an account at BlockIdentifier. If the request
+// included the mempool, coins spent by mempool
+// transactions are omitted and coins created by
+// them are included.
+type AccountCoinsResponse struct {
+	BlockIdentifier *types.BlockIdentifier `json:"block_identifier"`
+	Coins           []*types.Coin          `json:"coins"`
+}
+
+// TransactionStatusRequest is used to fetch the status
+// of a transaction submitted through /construction/submit.
+type TransactionStatusRequest struct {
+	NetworkIdentifier     *types.NetworkIdentifier     `json:"network_identifier"`
+	TransactionIdentifier *types.TransactionIdentifier `json:"transaction_identifier"`
+}
+
+// TransactionStatusResponse contains the tracked state
+// of a submitted transaction. Conflicted transactions
+// include the transaction that spent their inputs.
+type TransactionStatusResponse struct {
+	*broadcast.Transaction
+}
+
+// HealthResponse is returned by /healthz and /readyz.
+// Checks that do not apply (for example, the state of
+// the zend daemon when connected to an external zend)
+// are omitted.
+type HealthResponse struct {
+	Healthy bool `json:"healthy"`
+	Ready   bool `json:"ready"`
+
+	DatabaseHealthy *bool `json:"database_healthy,omitempty"`
+	ZendAlive       *bool `json:"zend_alive,omitempty"`
+	ZendReachable   *bool `json:"zend_reachable,omitempty"`
+
+	// ZendState and ZendError are the state and the last
+	// error logged by the zend daemon started by rosetta-zen.
+	ZendState *string `json:"zend_state,omitempty"`
+	ZendError string  `json:"zend_error,omitempty"`
+
+	// Lag is the number of blocks between the indexer
+	// head and the headers known to zend. The indexer
+	// is synced when it is at most MaxLag and zend
+	// has completed its initial block download.
+	SyncStatus               *types.SyncStatus `json:"sync_status,omitempty"`
+	Lag                      *int64            `json:"lag,omitempty"`
+	MaxLag                   int64             `json:"max_lag"`
+	ZendVerificationProgress *float64          `json:"zend_verification_progress,omitempty"`
+
+	Errors []string `json:"errors,omitempty"`
 }
 
 type unsignedTransaction struct {
@@ -87,12 +208,63 @@ type unsignedTransaction struct {
 	ScriptPubKeys  []*zen.ScriptPubKey `json:"scriptPubKeys"`
 	InputAmounts   []string            `json:"input_amounts"`
 	InputAddresses []string            `json:"input_addresses"`
+
+	// RedeemScripts contains the hex encoded redeem script
+	// of each pay-to-script-hash input (empty otherwise).
+	RedeemScripts []string `json:"redeem_scripts,omitempty"`
+}
+
+// deriveMetadata is the metadata that can be provided
+// to ConstructionDerive to derive the address at Path
+// below the extended public key XPub.
+type deriveMetadata struct {
+	XPub string `json:"xpub,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// deriveResponseMetadata is returned from ConstructionDerive
+// when an address is derived from an extended public key.
+type deriveResponseMetadata struct {
+	PublicKey string `json:"public_key"`
+	Path      string `json:"path"`
+}
+
+// preprocessMetadata is the metadata that can be
+// provided to ConstructionPreprocess.
+type preprocessMetadata struct {
+	ConfirmationTarget  *int64 `json:"confirmation_target,omitempty"`
+	FeeEstimationSource string `json:"fee_estimation_source,omitempty"`
+
+	// FundingAccount is provided instead of INPUT operations
+	// to have ConstructionMetadata select the coins spent by
+	// the transaction using the CoinSelection strategy.
+	FundingAccount *types.AccountIdentifier `json:"funding_account,omitempty"`
+	CoinSelection  string                   `json:"coin_selection,omitempty"`
+
+	// BumpTransaction is an unconfirmed transaction paying
+	// FundingAccount. The coins are selected from its outputs
+	// so the transaction pays for its parent and the package
+	// reaches FeeRate (in ZEN per kB).
+	BumpTransaction *types.TransactionIdentifier `json:"bump_transaction,omitempty"`
+	FeeRate         float64                      `json:"fee_rate,omitempty"`
 }
 
 type preprocessOptions struct {
-	Coins         []*types.Coin `json:"coins"`
-	EstimatedSize float64       `json:"estimated_size"`
-	FeeMultiplier *float64      `json:"fee_multiplier,omitempty"`
+	Coins               []*types.Coin `json:"coins"`
+	EstimatedSize       float64       `json:"estimated_size"`
+	FeeMultiplier       *float64      `json:"fee_multiplier,omitempty"`
+	ConfirmationTarget  int64         `json:"confirmation_target,omitempty"`
+	FeeEstimationSource string        `json:"fee_estimation_source,omitempty"`
+
+	// Amount is the value of the outputs and forward
+	// transfers to be paid with coins selected from
+	// FundingAccount.
+	FundingAccount *types.AccountIdentifier `json:"funding_account,omitempty"`
+	CoinSelection  string                   `json:"coin_selection,omitempty"`
+	Amount         string                   `json:"amount,omitempty"`
+
+	BumpTransaction *types.TransactionIdentifier `json:"bump_transaction,omitempty"`
+	FeeRate         float64                      `json:"fee_rate,omitempty"`
 }
 
 type constructionMetadata struct {
@@ -100,11 +272,26 @@ type constructionMetadata struct {
 	ReplayBlockHeight int64           `json:"replay_block_height"`
 	ReplayBlockHash string            `json:"replay_block_hash"`
 
+	FeeRate             float64 `json:"fee_rate,omitempty"`
+	ConfirmationTarget  int64   `json:"confirmation_target,omitempty"`
+	FeeEstimationSource string  `json:"fee_estimation_source,omitempty"`
+
+	// Coins are the coins selected from FundingAccount
+	// and Change is the value returned to it, if any.
+	// ConstructionPayloads adds the INPUT operations
+	// spending them and the change OUTPUT operation.
+	Coins          []*types.Coin            `json:"coins,omitempty"`
+	FundingAccount *types.AccountIdentifier `json:"funding_account,omitempty"`
+	Change         *types.Amount            `json:"change,omitempty"`
 }
 
 type signedTransaction struct {
 	Transaction  string   `json:"transaction"`
 	InputAmounts []string `json:"input_amounts"`
+
+	// ScriptPubKeys are the scripts spent by each input. They
+	// are used to verify the transaction before it is submitted.
+	ScriptPubKeys []*zen.ScriptPubKey `json:"scriptPubKeys,omitempty"`
 }
 
 // ParseOperationMetadata is returned from
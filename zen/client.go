@@ -17,6 +17,7 @@ package zen
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,8 +25,11 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
 	"github.com/HorizenOfficial/rosetta-zen/zenutil"
 	"github.com/coinbase/rosetta-sdk-go/storage"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -37,11 +41,6 @@ const (
 	// genesis block of the bitcoin blockchain for polling
 	genesisBlockIndex = 0
 
-	// requestID is the JSON-RPC request ID we use for making requests.
-	// We don't need unique request IDs because we're processing all of
-	// our requests synchronously.
-	requestID = 1
-
 	// jSONRPCVersion is the JSON-RPC version we use for making requests
 	jSONRPCVersion = "2.0"
 
@@ -50,6 +49,17 @@ const (
 	// * 1 returns the JSON representation
 	// * 2 returns the JSON representation with included Transaction data
 	blockVerbosity = 2
+
+	// rawTransactionVerbosity represents the verbose level used when
+	// fetching transactions
+	// * 0 returns the hex representation
+	// * 1 returns the JSON representation
+	rawTransactionVerbosity = 1
+
+	// mempoolTxIndex is the index we provide when parsing
+	// transactions that are not included in a block. It never
+	// matches the position of the coinbase transaction.
+	mempoolTxIndex = -1
 )
 
 type requestMethod string
@@ -73,14 +83,21 @@ const (
 	// https://developer.bitcoin.org/reference/rpc/sendrawtransaction.html
 	requestMethodSendRawTransaction requestMethod = "sendrawtransaction"
 
-	// https://developer.bitcoin.org/reference/rpc/estimatesmartfee.html
-	requestMethodEstimateSmartFee requestMethod = "estimatefee"
+	// https://zcash.github.io/rpc/estimatefee.html
+	requestMethodEstimateFee requestMethod = "estimatefee"
 
 	// https://developer.bitcoin.org/reference/rpc/getrawmempool.html
 	requestMethodRawMempool requestMethod = "getrawmempool"
 
+	// https://developer.bitcoin.org/reference/rpc/getrawtransaction.html
+	requestMethodGetRawTransaction requestMethod = "getrawtransaction"
+
 	// blockNotFoundErrCode is the RPC error code when a block cannot be found
 	blockNotFoundErrCode = -5
+
+	// transactionNotFoundErrCode is the RPC error code when a transaction
+	// cannot be found
+	transactionNotFoundErrCode = -5
 )
 
 const (
@@ -91,10 +108,12 @@ const (
 	// returned in Bitcoin blocks to be milliseconds.
 	timeMultiplier = 1000
 
-	// rpc credentials are fixed in rosetta-bitcoin
-	// because we never expose access to the raw bitcoind
+	// rpc credentials are fixed for the embedded zend
+	// because we never expose access to the raw zend
 	// endpoints (that could be used perform an attack, like
-	// changing our peers).
+	// changing our peers). They can be overridden with
+	// WithBasicAuth or WithCookieFile when connecting to
+	// an external node.
 	rpcUsername = "rosetta"
 	rpcPassword = "rosetta"
 )
@@ -104,8 +123,28 @@ var (
 	// cannot be found by the node
 	ErrBlockNotFound = errors.New("unable to find block")
 
+	// ErrTransactionNotFound is returned by when the requested
+	// transaction cannot be found by the node
+	ErrTransactionNotFound = errors.New("unable to find transaction")
+
+	// ErrInsufficientFeeData is returned when there is not enough
+	// data to estimate a fee rate
+	ErrInsufficientFeeData = errors.New("insufficient data to estimate fee rate")
+
 	// ErrJSONRPCError is returned when receiving an error from a JSON-RPC response
 	ErrJSONRPCError = errors.New("JSON-RPC error")
+
+	// ErrGenesisMismatch is returned when the node we are connected
+	// to has a different genesis block than the one we expect
+	ErrGenesisMismatch = errors.New("genesis block mismatch")
+
+	// ErrInvalidCookie is returned when the RPC cookie file
+	// cannot be parsed
+	ErrInvalidCookie = errors.New("invalid RPC cookie")
+
+	// ErrCircuitOpen is returned without contacting zend
+	// while the circuit breaker is open
+	ErrCircuitOpen = errors.New("zend unavailable: circuit breaker is open")
 )
 
 // Client is used to fetch blocks from bitcoind and
@@ -117,10 +156,67 @@ var (
 type Client struct {
 	baseURL string
 
+	rpcUsername string
+	rpcPassword string
+	cookieFile  string
+	tlsConfig   *tls.Config
+
 	genesisBlockIdentifier *types.BlockIdentifier
 	currency               *types.Currency
 
 	httpClient *http.Client
+
+	retryPolicy RetryPolicy
+	breaker     *circuitBreaker
+	requestID   int64 // last JSON-RPC request ID, updated atomically
+}
+
+// ClientOption is used to override the default
+// settings of a Client.
+type ClientOption func(*Client)
+
+// WithBasicAuth sets the credentials used to
+// authenticate with the RPC server.
+func WithBasicAuth(username string, password string) ClientOption {
+	return func(b *Client) {
+		b.rpcUsername = username
+		b.rpcPassword = password
+	}
+}
+
+// WithCookieFile authenticates with the RPC server using
+// the cookie file written by zend. The file is read on
+// each request because zend rotates it on restart.
+func WithCookieFile(path string) ClientOption {
+	return func(b *Client) {
+		b.cookieFile = path
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used
+// when connecting to the RPC server.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(b *Client) {
+		b.tlsConfig = tlsConfig
+	}
+}
+
+// WithRetryPolicy retries requests that fail because
+// zend is unavailable according to policy. By default,
+// requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(b *Client) {
+		b.retryPolicy = policy
+	}
+}
+
+// WithCircuitBreaker stops sending requests to zend
+// according to policy when it is unavailable. By
+// default, no circuit breaker is used.
+func WithCircuitBreaker(policy CircuitBreakerPolicy) ClientOption {
+	return func(b *Client) {
+		b.breaker = newCircuitBreaker(policy)
+	}
 }
 
 // LocalhostURL returns the URL to use
@@ -134,21 +230,33 @@ func NewClient(
 	baseURL string,
 	genesisBlockIdentifier *types.BlockIdentifier,
 	currency *types.Currency,
+	options ...ClientOption,
 ) *Client {
-	return &Client{
+	client := &Client{
 		baseURL:                baseURL,
+		rpcUsername:            rpcUsername,
+		rpcPassword:            rpcPassword,
 		genesisBlockIdentifier: genesisBlockIdentifier,
 		currency:               currency,
-		httpClient:             newHTTPClient(defaultTimeout),
+		retryPolicy:            noRetryPolicy,
+	}
+
+	for _, opt := range options {
+		opt(client)
 	}
+
+	client.httpClient = newHTTPClient(defaultTimeout, client.tlsConfig)
+
+	return client
 }
 
 // newHTTPClient returns a new HTTP client
-func newHTTPClient(timeout time.Duration) *http.Client {
+func newHTTPClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
 	var netTransport = &http.Transport{
 		Dial: (&net.Dialer{
 			Timeout: dialTimeout,
 		}).Dial,
+		TLSClientConfig: tlsConfig,
 	}
 
 	httpClient := &http.Client{
@@ -185,6 +293,27 @@ func (b *Client) NetworkStatus(ctx context.Context) (*types.NetworkStatusRespons
 	}, nil
 }
 
+// CheckGenesis ensures the node we are connected to
+// has the genesis block we expect, so that we never index
+// a node running on a different network.
+func (b *Client) CheckGenesis(ctx context.Context) error {
+	hash, err := b.GetHashFromIndex(ctx, genesisBlockIndex)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get genesis block hash", err)
+	}
+
+	if hash != b.genesisBlockIdentifier.Hash {
+		return fmt.Errorf(
+			"%w: expected %s but node returned %s",
+			ErrGenesisMismatch,
+			b.genesisBlockIdentifier.Hash,
+			hash,
+		)
+	}
+
+	return nil
+}
+
 // GetPeers fetches the list of peer nodes
 func (b *Client) GetPeers(ctx context.Context) ([]*types.Peer, error) {
 	info, err := b.getPeerInfo(ctx)
@@ -292,23 +421,30 @@ func (b *Client) SendRawTransaction(
 	return response.Result, nil
 }
 
-// SuggestedFeeRate estimates the approximate fee per vKB needed
-// to get a transaction in a block within conf_target.
+// SuggestedFeeRate estimates the approximate fee per kB needed
+// to get a transaction in a block within conf_target. If zend
+// does not have enough data to make an estimate, it returns
+// ErrInsufficientFeeData.
 func (b *Client) SuggestedFeeRate(
 	ctx context.Context,
 	confTarget int64,
 ) (float64, error) {
 	// Parameters:
-	//   1. conf_target (confirmation target in blocks)
-	/* TODO: Waiting for estimatefee analysis from the mainchain guys
+	//   1. nblocks (confirmation target in blocks)
 	params := []interface{}{confTarget}
 
 	response := &suggestedFeeRateResponse{}
-	if err := b.post(ctx, requestMethodEstimateSmartFee, params, response); err != nil {
+	if err := b.post(ctx, requestMethodEstimateFee, params, response); err != nil {
 		return -1, fmt.Errorf("%w: error getting fee estimate", err)
-	}*/
+	}
 
-	return MinFeeRate, nil
+	// zend returns -1 when it has not seen enough transactions
+	// confirmed within the target to make an estimate.
+	if response.Result < 0 {
+		return -1, ErrInsufficientFeeData
+	}
+
+	return response.Result, nil
 }
 
 // PruneBlockchain prunes up to the provided height.
@@ -347,6 +483,100 @@ func (b *Client) RawMempool(
 	return response.Result, nil
 }
 
+// MempoolEntry returns the size and fee of a transaction
+// in the mempool. zend does not support `getmempoolentry`,
+// so the entry is looked up in the verbose mempool.
+func (b *Client) MempoolEntry(
+	ctx context.Context,
+	hash string,
+) (*MempoolEntry, error) {
+	// Parameters:
+	//   1. verbose
+	params := []interface{}{true}
+
+	response := &rawMempoolVerboseResponse{}
+	if err := b.post(ctx, requestMethodRawMempool, params, response); err != nil {
+		return nil, fmt.Errorf("%w: error getting raw mempool", err)
+	}
+
+	entry, ok := response.Result[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not in the mempool", ErrTransactionNotFound, hash)
+	}
+
+	return entry, nil
+}
+
+// GetRawTransaction fetches a transaction by hash. It also returns
+// the identifiers of all coins spent by the transaction, which must
+// be provided to ParseTransaction.
+func (b *Client) GetRawTransaction(
+	ctx context.Context,
+	hash string,
+) (*Transaction, []string, error) {
+	// Parameters:
+	//   1. txid (string, required)
+	//   2. verbose (integer, optional, default=0)
+	// https://developer.bitcoin.org/reference/rpc/getrawtransaction.html
+	params := []interface{}{hash, rawTransactionVerbosity}
+
+	response := &rawTransactionResponse{}
+	if err := b.post(ctx, requestMethodGetRawTransaction, params, response); err != nil {
+		return nil, nil, fmt.Errorf("%w: error fetching transaction %s", err, hash)
+	}
+
+	transaction := response.Result
+	coins := []string{}
+	for inputIndex, input := range transaction.Inputs {
+		txHash, vout, ok := b.getInputTxHash(input, mempoolTxIndex, inputIndex)
+		if !ok {
+			continue
+		}
+
+		coins = append(coins, CoinIdentifier(txHash, vout))
+	}
+
+	return transaction, coins, nil
+}
+
+// ParseTransaction returns a parsed transaction that is not included
+// in a block (i.e. in the mempool) given a raw transaction and a map
+// of the coins it spends.
+func (b *Client) ParseTransaction(
+	ctx context.Context,
+	transaction *Transaction,
+	coins map[string]*storage.AccountCoin,
+) (*types.Transaction, error) {
+	if transaction == nil {
+		return nil, errors.New("error parsing nil transaction")
+	}
+
+	txOps, err := b.parseTxOperations(
+		transaction.Inputs,
+		transaction.Outputs,
+		transaction.Hash,
+		mempoolTxIndex,
+		coins,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error parsing transaction operations", err)
+	}
+
+	metadata, err := transaction.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get metadata for transaction", err)
+	}
+
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: transaction.Hash,
+		},
+		Operations: txOps,
+		Metadata:   metadata,
+	}, nil
+}
+
 // getPeerInfo performs the `getpeerinfo` JSON-RPC request
 func (b *Client) getPeerInfo(
 	ctx context.Context,
@@ -384,8 +614,8 @@ func (b *Client) getBlock(
 	return response.Result, nil
 }
 
-// getBlockchainInfo performs the `getblockchaininfo` JSON-RPC request
-func (b *Client) getBlockchainInfo(
+// BlockchainInfo performs the `getblockchaininfo` JSON-RPC request
+func (b *Client) BlockchainInfo(
 	ctx context.Context,
 ) (*BlockchainInfo, error) {
 	params := []interface{}{}
@@ -420,7 +650,7 @@ func (b *Client) getBlockHash(
 ) (string, error) {
 	// Lookup best block if no PartialBlockIdentifier provided.
 	if identifier == nil || (identifier.Hash == nil && identifier.Index == nil) {
-		info, err := b.getBlockchainInfo(ctx)
+		info, err := b.BlockchainInfo(ctx)
 		if err != nil {
 			return "", fmt.Errorf("%w: unable to get blockchain info", err)
 		}
@@ -507,7 +737,7 @@ func (b *Client) parseTransactions(
 	}
 	txs := make([]*types.Transaction, len(block.Txs) + len(block.Certs))
 	for index, transaction := range block.Txs {
-		txOps, err := b.parseTxOperations(transaction.Inputs, transaction.Outputs, transaction.Hash, index, coins, false)
+		txOps, err := b.parseTxOperations(transaction.Inputs, transaction.Outputs, transaction.Hash, index, coins, nil)
 		if err != nil {
 			return nil, fmt.Errorf("%w: error parsing transaction operations", err)
 		}
@@ -532,16 +762,35 @@ func (b *Client) parseTransactions(
 
 	for index, certificate := range block.Certs {
 		txIndex := len(block.Txs) + index;
-		certTxOps, err := b.parseTxOperations(certificate.Inputs, certificate.Outputs, certificate.Hash, txIndex, coins, true)
+		// Backward transfers are only parsed once the certificate matures
+		changeOutputs := []*Output{}
+		for _, output := range certificate.Outputs {
+			if !output.BackwardTransfer {
+				changeOutputs = append(changeOutputs, output)
+			}
+		}
+
+		certTxOps, err := b.parseTxOperations(certificate.Inputs, changeOutputs, certificate.Hash, txIndex, coins, certificate.Cert)
 		if err != nil {
 			return nil, fmt.Errorf("%w: error parsing certificate transaction operations", err)
 		}
 
+		fee, err := certificateFee(certTxOps)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to compute certificate fee", err)
+		}
+
+		metadata, err := types.MarshalMap(&TransactionMetadata{Fee: &fee})
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get metadata for certificate", err)
+		}
+
 		tx := &types.Transaction{
 			TransactionIdentifier: &types.TransactionIdentifier{
 				Hash: certificate.Hash,
 			},
 			Operations: certTxOps,
+			Metadata:   metadata,
 		}
 
 		txs[txIndex] = tx
@@ -560,11 +809,15 @@ func (b *Client) parseTransactions(
 			}
 		}
 		// we put -1 for index because this param is only used to determine that this "tx" is not a coinbase tx
-		matureCertTxOps, err := b.parseTxOperations([]*Input{}, backwardTransferOutputs, matureCertificate.Hash, -1, coins, false)
+		matureCertTxOps, err := b.parseTxOperations([]*Input{}, backwardTransferOutputs, matureCertificate.Hash, -1, coins, matureCertificate.Cert)
 		if err != nil {
 			return nil, fmt.Errorf("%w: error parsing mature certificate transaction operations", err)
 		}
 
+		for _, op := range matureCertTxOps {
+			op.Metadata["matured"] = true
+		}
+
 		certAndMatureCertTogether:=false 
 		for _, tx := range txs {
 			if tx.TransactionIdentifier.Hash == matureCertificate.Hash {
@@ -593,6 +846,26 @@ func (b *Client) parseTransactions(
 	return txs, nil
 }
 
+// certificateFee returns the fee paid by a certificate (in
+// satoshis): the value of its inputs minus the value of its
+// change outputs. Backward transfers are paid by the sidechain,
+// so they are not part of the operations of a certificate.
+func certificateFee(operations []*types.Operation) (int64, error) {
+	fee := int64(0)
+	for _, op := range operations {
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return -1, err
+		}
+
+		// Inputs are negative, so the fee is the
+		// negated sum of all amounts.
+		fee -= value.Int64()
+	}
+
+	return fee, nil
+}
+
 func addCoinsFromSameBlock(operations []*types.Operation, coins map[string]*storage.AccountCoin) map[string]*storage.AccountCoin {
 	// In some cases, a transaction will spend an output
 	// from the same block.
@@ -619,13 +892,16 @@ func addCoinsFromSameBlock(operations []*types.Operation, coins map[string]*stor
 
 // parseTransactions returns the transaction operations for a specified transaction.
 // It uses a map of previous transactions to properly hydrate the input operations.
+// If certificate is not nil, the sidechain id, epoch and quality of the certificate
+// are added to the metadata of the inputs and the backward transfer outputs are
+// parsed as BACKWARD_TRANSFER operations.
 func (b *Client) parseTxOperations(
 	inputs []*Input,
 	outputs []*Output,
 	hash string,
 	txIndex int,
 	coins map[string]*storage.AccountCoin,
-	isImmatureCertificate bool,
+	certificate *Cert,
 ) ([]*types.Operation, error) {
 	txOps := []*types.Operation{}
 
@@ -662,14 +938,16 @@ func (b *Client) parseTxOperations(
 			return nil, fmt.Errorf("%w: error parsing tx input", err)
 		}
 
+		if certificate != nil {
+			if err := setCertificateOperation(txOp, InputOpType, certificate); err != nil {
+				return nil, err
+			}
+		}
+
 		txOps = append(txOps, txOp)
 	}
 
 	for _, output := range outputs {
-		if isImmatureCertificate && output.BackwardTransfer {
-			continue
-		}
-
 		outputIndex := int64(output.Index)
 
 		txOp, err := b.parseOutputTransactionOperation(
@@ -688,12 +966,40 @@ func (b *Client) parseTxOperations(
 			)
 		}
 
+		if certificate != nil && output.BackwardTransfer {
+			if err := setCertificateOperation(txOp, BackwardTransferOpType, certificate); err != nil {
+				return nil, err
+			}
+		}
+
 		txOps = append(txOps, txOp)
 	}
 
 	return txOps, nil
 }
 
+// setCertificateOperation sets the type of an operation belonging
+// to a certificate and adds the sidechain id, epoch and quality of
+// the certificate to its metadata.
+func setCertificateOperation(op *types.Operation, opType string, certificate *Cert) error {
+	metadata, err := certificate.Metadata()
+	if err != nil {
+		return fmt.Errorf("%w: unable to get certificate metadata", err)
+	}
+
+	if op.Metadata == nil {
+		op.Metadata = map[string]interface{}{}
+	}
+
+	for k, v := range metadata {
+		op.Metadata[k] = v
+	}
+
+	op.Type = opType
+
+	return nil
+}
+
 // parseOutputTransactionOperation returns the types.Operation for the specified
 // `bitcoinOutput` transaction output.
 func (b *Client) parseOutputTransactionOperation(
@@ -880,16 +1186,67 @@ func (b *Client) coinbaseTxOperation(
 	}, nil
 }
 
-// post makes a HTTP request to a Bitcoin node
+// post makes a HTTP request to a Bitcoin node. Idempotent
+// requests failing because zend is unavailable are retried
+// according to the RetryPolicy. Failures are counted by the
+// circuit breaker unless ctx was cancelled.
 func (b *Client) post(
 	ctx context.Context,
 	method requestMethod,
 	params []interface{},
 	response jSONRPCResponse,
+) error {
+	if err := b.breaker.allow(); err != nil {
+		metrics.RPCErrors.WithLabelValues(string(method)).Inc()
+		return err
+	}
+
+	maxAttempts := b.retryPolicy.MaxAttempts
+	if !idempotentMethod(method) {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = b.postOnce(ctx, method, params, response)
+		if err == nil || !isRetriable(err) || attempt >= maxAttempts {
+			break
+		}
+
+		if sleepErr := utils.ContextSleep(ctx, b.retryPolicy.backoff(attempt-1)); sleepErr != nil {
+			break
+		}
+	}
+
+	// A cancelled request says nothing about
+	// whether zend is available.
+	if ctx.Err() != nil {
+		b.breaker.release()
+	} else {
+		b.breaker.record(err == nil || !isRetriable(err))
+	}
+
+	metrics.RPCDuration.WithLabelValues(string(method)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.RPCErrors.WithLabelValues(string(method)).Inc()
+	}
+
+	return err
+}
+
+// postOnce sends a single JSON-RPC request to zend. Errors
+// after which the request can be retried are wrapped in a
+// *retriableError.
+func (b *Client) postOnce(
+	ctx context.Context,
+	method requestMethod,
+	params []interface{},
+	response jSONRPCResponse,
 ) error {
 	rpcRequest := &request{
 		JSONRPC: jSONRPCVersion,
-		ID:      requestID,
+		ID:      int(atomic.AddInt64(&b.requestID, 1)),
 		Method:  string(method),
 		Params:  params,
 	}
@@ -904,26 +1261,89 @@ func (b *Client) post(
 		return fmt.Errorf("%w: error constructing request", err)
 	}
 
+	username, password, err := b.credentials()
+	if err != nil {
+		return fmt.Errorf("%w: unable to load RPC credentials", err)
+	}
+
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(rpcUsername, rpcPassword)
+	req.SetBasicAuth(username, password)
 
 	// Perform the post request
 	res, err := b.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("%w: error posting to rpc-api", err)
+		err = fmt.Errorf("%w: error posting to rpc-api", err)
+		if ctx.Err() != nil {
+			return err
+		}
+
+		return &retriableError{err: err}
 	}
 	defer res.Body.Close()
 
-	// We expect JSON-RPC responses to return `200 OK` statuses
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return &retriableError{err: fmt.Errorf("%w: error reading response body", err)}
+	}
+
+	// zend replies to failed calls with non-200 statuses, so
+	// we look at the JSON-RPC error code when there is one.
+	var envelope struct {
+		Error *responseError `json:"error"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+	retriable := retriableStatus(res.StatusCode) ||
+		(envelope.Error != nil && retriableCode(envelope.Error.Code))
+
+	// The JSON-RPC error is decoded before looking at the
+	// status so that callers can match it (for example, a
+	// rejected transaction is returned with `500`).
+	decodeErr := json.Unmarshal(body, response)
+	if decodeErr == nil {
+		if err := response.Err(); err != nil {
+			if retriable {
+				return &retriableError{err: err}
+			}
+
+			return err
+		}
+	}
+
+	// We expect successful JSON-RPC responses to return `200 OK` statuses
 	if res.StatusCode != http.StatusOK {
-		val, _ := ioutil.ReadAll(res.Body)
-		return fmt.Errorf("invalid response: %s %s", res.Status, string(val))
+		err = fmt.Errorf("invalid response: %s %s", res.Status, string(body))
+		if retriable {
+			return &retriableError{err: err}
+		}
+
+		return err
+	}
+
+	if decodeErr != nil {
+		return fmt.Errorf("%w: error decoding response body", decodeErr)
+	}
+
+	return nil
+}
+
+// credentials returns the username and password to
+// authenticate with the RPC server. If a cookie file is
+// configured, it takes precedence over static credentials.
+func (b *Client) credentials() (string, string, error) {
+	if len(b.cookieFile) == 0 {
+		return b.rpcUsername, b.rpcPassword, nil
+	}
+
+	cookie, err := ioutil.ReadFile(b.cookieFile)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: unable to read cookie file %s", err, b.cookieFile)
 	}
 
-	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
-		return fmt.Errorf("%w: error decoding response body", err)
+	// The cookie has the form <username>:<password>
+	parts := strings.SplitN(strings.TrimSpace(string(cookie)), ":", 2)
+	if len(parts) != 2 {
+		return "", "", ErrInvalidCookie
 	}
 
-	// Handle errors that are returned in JSON-RPC responses with `200 OK` statuses
-	return response.Err()
+	return parts[0], parts[1], nil
 }
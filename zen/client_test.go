@@ -22,6 +22,8 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path"
 	"testing"
 
 	"github.com/coinbase/rosetta-sdk-go/storage"
@@ -1038,7 +1040,7 @@ func TestGetRawBlock(t *testing.T) {
 			},
 			responses: []responseFixture{
 				{
-					status: http.StatusOK,
+					status: http.StatusInternalServerError,
 					body:   loadFixture("get_block_not_found_response.json"),
 					url:    url,
 				},
@@ -2420,6 +2422,9 @@ func TestParseBlockWithCertificate(t *testing.T) {
 										Hex: "473044022014d8dee1da3821dce95e48060f8f38394aee00f84d03a8203611ff3e703c10a002205ce62cffdc12dd26742489120d50d071ff08f993b9cca0b31a73e0f20f20cb5d01210241b92fed18a3ded2b98459b5432982a0712912ad86b929ec6feb19655824b7cc",
 									},
 									Sequence: 4294967295,
+									ScID:    "2f1f1b22ef02396fcb5fcff08915767b57206d3dffca92b211ae4eed3c5f1db7",
+									Epoch:   Int64Pointer(0),
+									Quality: Int64Pointer(3),
 								}),
 							},
 							{
@@ -2455,6 +2460,9 @@ func TestParseBlockWithCertificate(t *testing.T) {
 								}),
 							},
 						},
+						Metadata: MustMarshalMap(&TransactionMetadata{
+							Fee: Int64Pointer(7075104855),
+						}),
 					},
 				},
 				Metadata: MustMarshalMap(&BlockMetadata{
@@ -2755,7 +2763,7 @@ func TestParseBlockWithMatureCertificate(t *testing.T) {
 									Index:        0,
 									NetworkIndex: Int64Pointer(1),
 								},
-								Type:   OutputOpType,
+								Type:   BackwardTransferOpType,
 								Status: SuccessStatus,
 								Account: &types.AccountIdentifier{
 									Address: "zteqa5taBUZaJFsTJpmD9KVvCSfWjEG7w2S",
@@ -2780,6 +2788,10 @@ func TestParseBlockWithMatureCertificate(t *testing.T) {
 											"zteqa5taBUZaJFsTJpmD9KVvCSfWjEG7w2S",
 										},
 									},
+									ScID:    "03be44ad626a288d2c7e05c7d40f2ba72b8b40749c96fa2cb2201fa0f3b01d6e",
+									Epoch:   Int64Pointer(0),
+									Quality: Int64Pointer(5),
+									Matured: true,
 								}),
 							},
 						},
@@ -3264,6 +3276,9 @@ func TestParseBlockWithCertAndMatureCert(t *testing.T) {
 										Hex: "473044022014d8dee1da3821dce95e48060f8f38394aee00f84d03a8203611ff3e703c10a002205ce62cffdc12dd26742489120d50d071ff08f993b9cca0b31a73e0f20f20cb5d01210241b92fed18a3ded2b98459b5432982a0712912ad86b929ec6feb19655824b7cc",
 									},
 									Sequence: 4294967295,
+									ScID:    "2f1f1b22ef02396fcb5fcff08915767b57206d3dffca92b211ae4eed3c5f1db7",
+									Epoch:   Int64Pointer(0),
+									Quality: Int64Pointer(3),
 								}),
 							},
 							{
@@ -3304,7 +3319,7 @@ func TestParseBlockWithCertAndMatureCert(t *testing.T) {
 									Index:        2,
 									NetworkIndex: Int64Pointer(1),
 								},
-								Type:   OutputOpType,
+								Type:   BackwardTransferOpType,
 								Status: SuccessStatus,
 								Account: &types.AccountIdentifier{
 									Address: "ztZzAfqxzua7EDHUMFq6hpQPhXyC1XPJMUs",
@@ -3329,9 +3344,16 @@ func TestParseBlockWithCertAndMatureCert(t *testing.T) {
 											"ztZzAfqxzua7EDHUMFq6hpQPhXyC1XPJMUs",
 										},
 									},
+									ScID:    "2f1f1b22ef02396fcb5fcff08915767b57206d3dffca92b211ae4eed3c5f1db7",
+									Epoch:   Int64Pointer(0),
+									Quality: Int64Pointer(3),
+									Matured: true,
 								}),
 							},
 						},
+						Metadata: MustMarshalMap(&TransactionMetadata{
+							Fee: Int64Pointer(7075104855),
+						}),
 					},
 				},
 				Metadata: MustMarshalMap(&BlockMetadata{
@@ -3430,7 +3452,27 @@ func TestSuggestedFeeRate(t *testing.T) {
 					url:    url,
 				},
 			},
-			expectedRate: float64(0.00001),
+			expectedRate: float64(0.00002345),
+		},
+		"insufficient data": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("insufficient_fee_rate.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrInsufficientFeeData,
+		},
+		"invalid conf target": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("invalid_fee_rate.json"),
+					url:    url,
+				},
+			},
+			expectedError: errors.New("Invalid conf_target"),
 		},
 	}
 
@@ -3467,6 +3509,154 @@ func TestSuggestedFeeRate(t *testing.T) {
 	}
 }
 
+func TestCheckGenesis(t *testing.T) {
+	tests := map[string]struct {
+		genesisBlockIdentifier *types.BlockIdentifier
+		responses              []responseFixture
+
+		expectedError error
+	}{
+		"matching genesis": {
+			genesisBlockIdentifier: MainnetGenesisBlockIdentifier,
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_genesis_block_hash_response.json"),
+					url:    url,
+				},
+			},
+		},
+		"different network": {
+			genesisBlockIdentifier: TestnetGenesisBlockIdentifier,
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_genesis_block_hash_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrGenesisMismatch,
+		},
+		"500 error": {
+			genesisBlockIdentifier: MainnetGenesisBlockIdentifier,
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, test.genesisBlockIdentifier, MainnetCurrency)
+			err := client.CheckGenesis(context.Background())
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	cookieDir, err := ioutil.TempDir("", "zend")
+	assert.NoError(t, err)
+	defer os.RemoveAll(cookieDir)
+
+	cookieFile := path.Join(cookieDir, ".cookie")
+	assert.NoError(t, ioutil.WriteFile(cookieFile, []byte("__cookie__:secret\n"), 0600))
+
+	invalidCookieFile := path.Join(cookieDir, ".invalid")
+	assert.NoError(t, ioutil.WriteFile(invalidCookieFile, []byte("secret"), 0600))
+
+	tests := map[string]struct {
+		options []ClientOption
+
+		expectedUsername string
+		expectedPassword string
+		expectedError    error
+	}{
+		"default credentials": {
+			expectedUsername: rpcUsername,
+			expectedPassword: rpcPassword,
+		},
+		"basic auth": {
+			options:          []ClientOption{WithBasicAuth("user", "password")},
+			expectedUsername: "user",
+			expectedPassword: "password",
+		},
+		"cookie file": {
+			options:          []ClientOption{WithCookieFile(cookieFile)},
+			expectedUsername: "__cookie__",
+			expectedPassword: "secret",
+		},
+		"invalid cookie file": {
+			options:       []ClientOption{WithCookieFile(invalidCookieFile)},
+			expectedError: ErrInvalidCookie,
+		},
+		"missing cookie file": {
+			options:       []ClientOption{WithCookieFile(path.Join(cookieDir, "missing"))},
+			expectedError: errors.New("unable to read cookie file"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				assert.True(ok)
+				assert.Equal(test.expectedUsername, username)
+				assert.Equal(test.expectedPassword, password)
+
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintln(w, loadFixture("get_genesis_block_hash_response.json"))
+			}))
+			defer ts.Close()
+
+			client := NewClient(
+				ts.URL,
+				MainnetGenesisBlockIdentifier,
+				MainnetCurrency,
+				test.options...,
+			)
+			err := client.CheckGenesis(context.Background())
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
 func TestRawMempool(t *testing.T) {
 	tests := map[string]struct {
 		responses []responseFixture
@@ -3533,6 +3723,371 @@ func TestRawMempool(t *testing.T) {
 	}
 }
 
+func TestBlockchainInfo(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedInfo  *BlockchainInfo
+		expectedError error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_blockchain_info_response.json"),
+					url:    url,
+				},
+			},
+			expectedInfo: &BlockchainInfo{
+				Chain:                "main",
+				Blocks:               1000,
+				BestBlockHash:        "00000000c937983704a73af28acdec37b049d214adbda81d7e2a3dd146f6ed09",
+				Headers:              1000,
+				VerificationProgress: 0.9999978065942465,
+				InitialBlockDownload: false,
+			},
+		},
+		"500 error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			info, err := client.BlockchainInfo(context.Background())
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedInfo, info)
+			}
+		})
+	}
+}
+
+func TestMempoolEntry(t *testing.T) {
+	tests := map[string]struct {
+		hash      string
+		responses []responseFixture
+
+		expectedEntry *MempoolEntry
+		expectedError error
+	}{
+		"successful": {
+			hash: "37b4fcc8e0b229412faeab8baad45d3eb8e4eec41840d6ac2103987163459e75",
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("raw_mempool_verbose.json"),
+					url:    url,
+				},
+			},
+			expectedEntry: &MempoolEntry{
+				Size:   374,
+				Fee:    0.0000748,
+				Time:   1603900012,
+				Height: 840000,
+				Depends: []string{
+					"9cec12d170e97e21a876fa2789e6bfc25aa22b8a5e05f3f276650844da0c33ab",
+				},
+			},
+		},
+		"not in mempool": {
+			hash: "7bbb29ae32117597fcdf21b464441abd571dad52d053b9c2f7204f8ea8c4762e",
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("raw_mempool_verbose.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrTransactionNotFound,
+		},
+		"500 error": {
+			hash: "37b4fcc8e0b229412faeab8baad45d3eb8e4eec41840d6ac2103987163459e75",
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			entry, err := client.MempoolEntry(context.Background(), test.hash)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedEntry, entry)
+			}
+		})
+	}
+}
+
+func TestGetRawTransaction(t *testing.T) {
+	tests := map[string]struct {
+		hash      string
+		responses []responseFixture
+
+		expectedTransaction *Transaction
+		expectedCoins       []string
+		expectedError       error
+	}{
+		"successful": {
+			hash: "67c76a34cb6bde6f9628fdc8348c23191d3222e88386ed05c97e3c63384a01af",
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_raw_transaction_response.json"),
+					url:    url,
+				},
+			},
+			expectedTransaction: block717983.Txs[1],
+			expectedCoins: []string{
+				"9401f535c210f3ff362d3f51dba88ecddf4f87ed9d0563c1f9e8af75eca1fd1a:0",
+				"14e8fe02ec4e237d8cb6bf95943bd05706a19f6bd29f9b2b1fefc4fa09ef6737:0",
+				"4c292f9ba0e94f2d48a16f8765217e62b6673796bffd92c26b13ed5e661946bc:1",
+			},
+		},
+		"not found": {
+			hash: "67c76a34cb6bde6f9628fdc8348c23191d3222e88386ed05c97e3c63384a01af",
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   loadFixture("get_raw_transaction_not_found_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrTransactionNotFound,
+		},
+		"500 error": {
+			hash: "67c76a34cb6bde6f9628fdc8348c23191d3222e88386ed05c97e3c63384a01af",
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   "{}",
+					url:    url,
+				},
+			},
+			expectedError: errors.New("invalid response: 500 Internal Server Error"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal("application/json", r.Header.Get("Content-Type"))
+				assert.Equal("POST", r.Method)
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			transaction, coins, err := client.GetRawTransaction(context.Background(), test.hash)
+			if test.expectedError != nil {
+				assert.Contains(err.Error(), test.expectedError.Error())
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedTransaction, transaction)
+				assert.Equal(test.expectedCoins, coins)
+			}
+		})
+	}
+}
+
+func TestSendRawTransaction(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedHash  string
+		expectedError error
+	}{
+		"successful": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   `{"result":"67c76a34cb6bde6f9628fdc8348c23191d3222e88386ed05c97e3c63384a01af","error":null,"id":1}`,
+					url:    url,
+				},
+			},
+			expectedHash: "67c76a34cb6bde6f9628fdc8348c23191d3222e88386ed05c97e3c63384a01af",
+		},
+		"rejected": {
+			responses: []responseFixture{
+				{
+					status: http.StatusInternalServerError,
+					body:   loadFixture("send_raw_transaction_rejected_response.json"),
+					url:    url,
+				},
+			},
+			expectedError: ErrJSONRPCError,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert = assert.New(t)
+			)
+
+			responses := make(chan responseFixture, len(test.responses))
+			for _, response := range test.responses {
+				responses <- response
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := <-responses
+				assert.Equal(response.url, r.URL.RequestURI())
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+			defer ts.Close()
+
+			client := NewClient(ts.URL, MainnetGenesisBlockIdentifier, MainnetCurrency)
+			hash, err := client.SendRawTransaction(context.Background(), "00")
+			if test.expectedError != nil {
+				assert.True(errors.Is(err, test.expectedError))
+				assert.Contains(err.Error(), "code: -26")
+			} else {
+				assert.NoError(err)
+				assert.Equal(test.expectedHash, hash)
+			}
+		})
+	}
+}
+
+func TestParseTransaction(t *testing.T) {
+	coins := map[string]*storage.AccountCoin{
+		"9401f535c210f3ff362d3f51dba88ecddf4f87ed9d0563c1f9e8af75eca1fd1a:0": {
+			Account: &types.AccountIdentifier{
+				Address: "ztrEXsPLywPcxE3Sn9qdWV6tYkBH4HnYwin",
+			},
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "9401f535c210f3ff362d3f51dba88ecddf4f87ed9d0563c1f9e8af75eca1fd1a:0",
+				},
+				Amount: &types.Amount{
+					Value:    "60000000",
+					Currency: MainnetCurrency,
+				},
+			},
+		},
+		"14e8fe02ec4e237d8cb6bf95943bd05706a19f6bd29f9b2b1fefc4fa09ef6737:0": {
+			Account: &types.AccountIdentifier{
+				Address: "ztrEXsPLywPcxE3Sn9qdWV6tYkBH4HnYwin",
+			},
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "14e8fe02ec4e237d8cb6bf95943bd05706a19f6bd29f9b2b1fefc4fa09ef6737:0",
+				},
+				Amount: &types.Amount{
+					Value:    "200000000",
+					Currency: MainnetCurrency,
+				},
+			},
+		},
+		"4c292f9ba0e94f2d48a16f8765217e62b6673796bffd92c26b13ed5e661946bc:1": {
+			Account: &types.AccountIdentifier{
+				Address: "ztrEXsPLywPcxE3Sn9qdWV6tYkBH4HnYwin",
+			},
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{
+					Identifier: "4c292f9ba0e94f2d48a16f8765217e62b6673796bffd92c26b13ed5e661946bc:1",
+				},
+				Amount: &types.Amount{
+					Value:    "7100000000",
+					Currency: MainnetCurrency,
+				},
+			},
+		},
+	}
+
+	client := NewClient("", MainnetGenesisBlockIdentifier, MainnetCurrency)
+
+	// A transaction parsed from the mempool must match the same
+	// transaction once it is included in a block.
+	block, err := client.ParseBlock(context.Background(), block717983, coins)
+	assert.NoError(t, err)
+
+	transaction, err := client.ParseTransaction(context.Background(), block717983.Txs[1], coins)
+	assert.NoError(t, err)
+	assert.Equal(t, block.Transactions[1], transaction)
+
+	// Parsing fails if a spent coin is not provided.
+	transaction, err = client.ParseTransaction(
+		context.Background(),
+		block717983.Txs[1],
+		map[string]*storage.AccountCoin{},
+	)
+	assert.Nil(t, transaction)
+	assert.Contains(t, err.Error(), "error finding previous tx")
+}
+
 
 // loadFixture takes a file name and returns the response fixture.
 func loadFixture(fileName string) string {
@@ -17,33 +17,225 @@ package zen
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/HorizenOfficial/rosetta-zen/metrics"
 	"github.com/HorizenOfficial/rosetta-zen/utils"
 
+	sdkUtils "github.com/coinbase/rosetta-sdk-go/utils"
+
 	"golang.org/x/sync/errgroup"
 )
 
 const (
 	zendLogger       = "zend"
 	zendStdErrLogger = "zend stderr"
+
+	// zendBinary is the path of the zend daemon
+	// in the rosetta-zen image.
+	zendBinary = "/app/zend"
+)
+
+// NodeState is the state of the zend daemon
+// started by a Supervisor.
+type NodeState string
+
+const (
+	// NodeStopped is the state of zend before it is
+	// started and while it is waiting to be restarted.
+	NodeStopped NodeState = "stopped"
+
+	// NodeStarting is the state of zend once its
+	// process is started.
+	NodeStarting NodeState = "starting"
+
+	// NodeLoadingBlockIndex is the state of zend while
+	// it loads the block index from disk.
+	NodeLoadingBlockIndex NodeState = "loading block index"
+
+	// NodeRescanning is the state of zend while it
+	// rescans the blockchain.
+	NodeRescanning NodeState = "rescanning"
+
+	// NodeReady is the state of zend once it is
+	// done loading and serves RPC requests.
+	NodeReady NodeState = "ready"
+
+	// NodeFailed is the state of zend once the
+	// Supervisor gave up restarting it.
+	NodeFailed NodeState = "failed"
 )
 
-func logPipe(ctx context.Context, pipe io.ReadCloser, identifier string) error {
+var (
+	// ErrZendCorrupted is returned by Supervisor.Run when
+	// zend reports that its database is corrupted.
+	ErrZendCorrupted = errors.New("zend database is corrupted")
+
+	// ErrZendOutOfDisk is returned by Supervisor.Run when
+	// zend reports that it is out of disk space.
+	ErrZendOutOfDisk = errors.New("zend is out of disk space")
+
+	// ErrZendExited is returned by Supervisor.Run when zend
+	// exited more than allowed by the SupervisorPolicy.
+	ErrZendExited = errors.New("zend exited")
+
+	// DefaultSupervisorPolicy is the SupervisorPolicy
+	// used to run zend in production.
+	DefaultSupervisorPolicy = SupervisorPolicy{
+		Restart: RetryPolicy{
+			MaxAttempts:    10,              // nolint:gomnd
+			InitialBackoff: 1 * time.Second, // nolint:gomnd
+			MaxBackoff:     2 * time.Minute, // nolint:gomnd
+			Multiplier:     2,               // nolint:gomnd
+			Jitter:         0.2,             // nolint:gomnd
+		},
+		StableAfter:     10 * time.Minute, // nolint:gomnd
+		ShutdownTimeout: 60 * time.Second, // nolint:gomnd
+	}
+
+	// stateMarkers are the log lines of zend
+	// that start each NodeState.
+	stateMarkers = []struct {
+		marker string
+		state  NodeState
+	}{
+		{marker: "Loading block index", state: NodeLoadingBlockIndex},
+		{marker: "Rescanning", state: NodeRescanning},
+		{marker: "Done loading", state: NodeReady},
+	}
+
+	// fatalMarkers are the log lines of zend after
+	// which restarting it does not help.
+	fatalMarkers = []struct {
+		marker string
+		err    error
+	}{
+		{marker: "Corrupted block database", err: ErrZendCorrupted},
+		{marker: "database corrupted", err: ErrZendCorrupted},
+		{marker: "Disk space is low", err: ErrZendOutOfDisk},
+		{marker: "No space left on device", err: ErrZendOutOfDisk},
+	}
+)
+
+// SupervisorPolicy determines how a Supervisor
+// restarts and stops zend.
+type SupervisorPolicy struct {
+	// Restart determines the delay before each restart.
+	// Its MaxAttempts is the number of consecutive starts
+	// before giving up (0 restarts forever).
+	Restart RetryPolicy
+
+	// StableAfter is how long zend must run before
+	// the consecutive starts are reset.
+	StableAfter time.Duration
+
+	// ShutdownTimeout is how long zend has to exit
+	// after SIGTERM before it is killed.
+	ShutdownTimeout time.Duration
+}
+
+// Supervisor runs zend, restarts it when it exits and
+// tracks its state from its logs.
+type Supervisor struct {
+	command []string
+	policy  SupervisorPolicy
+
+	mutex     sync.Mutex
+	state     NodeState
+	lastError string
+	fatal     error
+}
+
+// NewSupervisor returns a new *Supervisor running
+// zend with the configuration file at configPath.
+func NewSupervisor(configPath string, policy SupervisorPolicy) *Supervisor {
+	return &Supervisor{
+		command: []string{zendBinary, fmt.Sprintf("-conf=%s", configPath)},
+		policy:  policy,
+		state:   NodeStopped,
+	}
+}
+
+// State returns the current state of zend.
+func (s *Supervisor) State() NodeState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.state
+}
+
+// LastError returns the last error logged by zend.
+func (s *Supervisor) LastError() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.lastError
+}
+
+// Alive returns true while the zend process is running.
+func (s *Supervisor) Alive() bool {
+	switch s.State() {
+	case NodeStopped, NodeFailed:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *Supervisor) setState(state NodeState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.state = state
+}
+
+// observe updates the state of zend from a line
+// of its logs.
+func (s *Supervisor) observe(line string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, m := range stateMarkers {
+		if strings.Contains(line, m.marker) {
+			s.state = m.state
+		}
+	}
+
+	for _, m := range fatalMarkers {
+		if strings.Contains(line, m.marker) {
+			s.fatal = m.err
+			s.lastError = line
+		}
+	}
+
+	if strings.Contains(line, "Error:") {
+		s.lastError = line
+	}
+}
+
+func (s *Supervisor) logPipe(ctx context.Context, pipe io.Reader, identifier string) error {
 	logger := utils.ExtractLogger(ctx, identifier)
 	reader := bufio.NewReader(pipe)
 	for {
 		str, err := reader.ReadString('\n')
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
 			logger.Warnw("closing logger", "error", err)
 			return err
 		}
 
 		message := strings.Replace(str, "\n", "", -1)
+		s.observe(message)
 		messages := strings.SplitAfterN(message, " ", 2)
 
 		// Trim the timestamp from the log if it exists
@@ -61,14 +253,61 @@ func logPipe(ctx context.Context, pipe io.ReadCloser, identifier string) error {
 	}
 }
 
-// StartZEND starts a zend daemon in another goroutine
-// and logs the results to the console.
-func StartZEND(ctx context.Context, configPath string, g *errgroup.Group) error {
+// Run starts zend and restarts it with backoff each time
+// it exits, until ctx is canceled, zend reports a fatal
+// error or it exited more than allowed by the policy.
+func (s *Supervisor) Run(ctx context.Context) error {
 	logger := utils.ExtractLogger(ctx, "zend")
-	cmd := exec.Command(
-		"/app/zend",
-		fmt.Sprintf("-conf=%s", configPath),
-	) // #nosec G204
+
+	starts := 0
+	for {
+		starts++
+		started := time.Now()
+		err := s.runOnce(ctx)
+		if ctx.Err() != nil {
+			s.setState(NodeStopped)
+			return ctx.Err()
+		}
+
+		s.mutex.Lock()
+		fatal := s.fatal
+		s.mutex.Unlock()
+		if fatal != nil {
+			s.setState(NodeFailed)
+			return fmt.Errorf("%w: %s", fatal, s.LastError())
+		}
+
+		if time.Since(started) >= s.policy.StableAfter {
+			starts = 1
+		}
+
+		maxStarts := s.policy.Restart.MaxAttempts
+		if maxStarts > 0 && starts >= maxStarts {
+			s.setState(NodeFailed)
+			return fmt.Errorf("%w %d times: %v", ErrZendExited, starts, err)
+		}
+
+		s.setState(NodeStopped)
+		delay := s.policy.Restart.backoff(starts - 1)
+		logger.Warnw(
+			"zend exited, restarting",
+			"error", err,
+			"last error", s.LastError(),
+			"delay", delay,
+		)
+		metrics.NodeRestarts.Inc()
+		if err := sdkUtils.ContextSleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// runOnce runs zend until it exits or ctx is canceled.
+// When ctx is canceled, zend is sent SIGTERM and killed
+// if it does not exit within the ShutdownTimeout.
+func (s *Supervisor) runOnce(ctx context.Context) error {
+	logger := utils.ExtractLogger(ctx, "zend")
+	cmd := exec.Command(s.command[0], s.command[1:]...) // #nosec G204
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -80,24 +319,51 @@ func StartZEND(ctx context.Context, configPath string, g *errgroup.Group) error
 		return err
 	}
 
-	g.Go(func() error {
-		return logPipe(ctx, stdout, zendLogger)
-	})
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w: unable to start zend", err)
+	}
+	s.setState(NodeStarting)
 
-	g.Go(func() error {
-		return logPipe(ctx, stderr, zendStdErrLogger)
+	// All output must be read before calling
+	// cmd.Wait, which closes the pipes.
+	var pipes errgroup.Group
+	pipes.Go(func() error {
+		return s.logPipe(ctx, stdout, zendLogger)
+	})
+	pipes.Go(func() error {
+		return s.logPipe(ctx, stderr, zendStdErrLogger)
 	})
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("%w: unable to start zend", err)
+	done := make(chan error, 1)
+	go func() {
+		_ = pipes.Wait()
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
 	}
 
-	g.Go(func() error {
-		<-ctx.Done()
+	logger.Warnw("sending SIGTERM to zend")
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		logger.Warnw("unable to send SIGTERM to zend", "error", err)
+	}
 
-		logger.Warnw("sending interrupt to zend")
-		return cmd.Process.Signal(os.Interrupt)
-	})
+	timer := time.NewTimer(s.policy.ShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	logger.Warnw("zend did not exit, sending SIGKILL", "timeout", s.policy.ShutdownTimeout)
+	if err := cmd.Process.Kill(); err != nil {
+		logger.Warnw("unable to kill zend", "error", err)
+	}
 
-	return cmd.Wait()
+	return <-done
 }
@@ -0,0 +1,194 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSupervisorPolicy = SupervisorPolicy{
+	Restart: RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	},
+	StableAfter:     time.Minute,
+	ShutdownTimeout: 5 * time.Second,
+}
+
+// testSupervisor returns a *Supervisor running
+// script with /bin/sh instead of zend.
+func testSupervisor(t *testing.T, script string, policy SupervisorPolicy) *Supervisor {
+	dir, err := ioutil.TempDir("", "zend")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "zend.sh")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(script), 0600))
+
+	s := NewSupervisor("zen.conf", policy)
+	s.command = []string{"/bin/sh", path}
+
+	return s
+}
+
+func waitForState(t *testing.T, s *Supervisor, state NodeState) {
+	assert.Eventually(t, func() bool {
+		return s.State() == state
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSupervisor_Ready(t *testing.T) {
+	s := testSupervisor(t, strings.Join([]string{
+		"echo '2020-10-01 00:00:00 Loading block index...'",
+		"echo '2020-10-01 00:00:01 Done loading'",
+		"exec sleep 100",
+	}, "\n"), testSupervisorPolicy)
+	assert.Equal(t, NodeStopped, s.State())
+	assert.False(t, s.Alive())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	waitForState(t, s, NodeReady)
+	assert.True(t, s.Alive())
+
+	// zend exits on SIGTERM.
+	start := time.Now()
+	cancel()
+	assert.True(t, errors.Is(<-errs, context.Canceled))
+	assert.Less(t, int64(time.Since(start)), int64(testSupervisorPolicy.ShutdownTimeout))
+	assert.Equal(t, NodeStopped, s.State())
+	assert.False(t, s.Alive())
+}
+
+func TestSupervisor_Kill(t *testing.T) {
+	policy := testSupervisorPolicy
+	policy.ShutdownTimeout = 100 * time.Millisecond
+	s := testSupervisor(t, strings.Join([]string{
+		"trap '' TERM",
+		"echo 'Done loading'",
+		"while true; do sleep 1; done",
+	}, "\n"), policy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- s.Run(ctx) }()
+
+	waitForState(t, s, NodeReady)
+	cancel()
+
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, context.Canceled))
+	case <-time.After(5 * time.Second):
+		t.Fatal("zend was not killed")
+	}
+}
+
+func TestSupervisor_Restart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	starts := filepath.Join(dir, "starts")
+	s := testSupervisor(t, strings.Join([]string{
+		fmt.Sprintf("echo start >> %s", starts),
+		"echo 'Error: unable to bind to port' >&2",
+		"exit 1",
+	}, "\n"), testSupervisorPolicy)
+
+	err = s.Run(context.Background())
+	assert.True(t, errors.Is(err, ErrZendExited))
+	assert.Equal(t, NodeFailed, s.State())
+	assert.False(t, s.Alive())
+	assert.Equal(t, "Error: unable to bind to port", s.LastError())
+
+	contents, err := ioutil.ReadFile(starts)
+	assert.NoError(t, err)
+	assert.Equal(t, testSupervisorPolicy.Restart.MaxAttempts, strings.Count(string(contents), "start"))
+}
+
+func TestSupervisor_Fatal(t *testing.T) {
+	tests := map[string]struct {
+		line string
+		err  error
+	}{
+		"corrupted database": {
+			line: "Error: Corrupted block database detected",
+			err:  ErrZendCorrupted,
+		},
+		"out of disk": {
+			line: "Error: Disk space is low!",
+			err:  ErrZendOutOfDisk,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "starts")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			starts := filepath.Join(dir, "starts")
+			s := testSupervisor(t, strings.Join([]string{
+				fmt.Sprintf("echo start >> %s", starts),
+				fmt.Sprintf("echo '%s' >&2", test.line),
+				"exit 1",
+			}, "\n"), testSupervisorPolicy)
+
+			err = s.Run(context.Background())
+			assert.True(t, errors.Is(err, test.err))
+			assert.Equal(t, NodeFailed, s.State())
+			assert.Equal(t, test.line, s.LastError())
+
+			// zend is not restarted.
+			contents, err := ioutil.ReadFile(starts)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, strings.Count(string(contents), "start"))
+		})
+	}
+}
+
+func TestSupervisor_Observe(t *testing.T) {
+	s := NewSupervisor("zen.conf", testSupervisorPolicy)
+	s.setState(NodeStarting)
+
+	s.observe("2020-10-01 00:00:00 Loading block index...")
+	assert.Equal(t, NodeLoadingBlockIndex, s.State())
+
+	s.observe("2020-10-01 00:00:01 Rescanning last 288 blocks (from block 100)...")
+	assert.Equal(t, NodeRescanning, s.State())
+
+	s.observe("2020-10-01 00:00:02 Done loading")
+	assert.Equal(t, NodeReady, s.State())
+	assert.Empty(t, s.LastError())
+
+	s.observe("2020-10-01 00:00:03 Error: Unable to bind to 0.0.0.0:9033")
+	assert.Equal(t, NodeReady, s.State())
+	assert.Equal(t, "2020-10-01 00:00:03 Error: Unable to bind to 0.0.0.0:9033", s.LastError())
+}
@@ -0,0 +1,239 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zen
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// rpcInWarmupErrCode is the RPC error code returned
+	// while zend is loading the block index.
+	rpcInWarmupErrCode = -28
+)
+
+var (
+	// DefaultRetryPolicy is the RetryPolicy used to
+	// connect to zend in production.
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts:    5,                      // nolint:gomnd
+		InitialBackoff: 500 * time.Millisecond, // nolint:gomnd
+		MaxBackoff:     10 * time.Second,       // nolint:gomnd
+		Multiplier:     2,                      // nolint:gomnd
+		Jitter:         0.2,                    // nolint:gomnd
+	}
+
+	// DefaultCircuitBreakerPolicy is the CircuitBreakerPolicy
+	// used to connect to zend in production.
+	DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+		FailureThreshold: 5,                // nolint:gomnd
+		Cooldown:         30 * time.Second, // nolint:gomnd
+	}
+
+	// noRetryPolicy sends each request exactly once. It
+	// is used when no RetryPolicy is provided.
+	noRetryPolicy = RetryPolicy{MaxAttempts: 1}
+)
+
+// RetryPolicy determines how often and how fast
+// requests to zend are retried when they fail with
+// a retriable error (zend is unreachable, warming up
+// or its work queue is full).
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent
+	// before giving up, including the first attempt.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	// Each following delay is Multiplier times longer, up
+	// to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter is the fraction of each delay that is
+	// randomized so that clients don't retry in lockstep.
+	Jitter float64
+}
+
+// backoff returns the delay before the retry following
+// the attempt-th (zero-indexed) failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	delay -= delay * p.Jitter * rand.Float64() // nolint:gosec
+
+	return time.Duration(delay)
+}
+
+// CircuitBreakerPolicy determines when the Client stops
+// sending requests to an unavailable zend.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive requests
+	// that must fail with a retriable error to open the
+	// breaker.
+	FailureThreshold int
+
+	// Cooldown is how long the breaker stays open before a
+	// single request is allowed through to probe zend.
+	Cooldown time.Duration
+}
+
+// circuitBreaker fails requests fast with ErrCircuitOpen
+// after zend has been unavailable for FailureThreshold
+// consecutive requests. A nil circuitBreaker never opens.
+type circuitBreaker struct {
+	policy CircuitBreakerPolicy
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// newCircuitBreaker returns a closed circuitBreaker.
+func newCircuitBreaker(policy CircuitBreakerPolicy) *circuitBreaker {
+	return &circuitBreaker{policy: policy}
+}
+
+// allow returns ErrCircuitOpen if a request should
+// not be sent. Once the cooldown elapses, a single
+// probe request is allowed.
+func (c *circuitBreaker) allow() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.failures < c.policy.FailureThreshold {
+		return nil
+	}
+
+	if c.probing || time.Now().Before(c.openUntil) {
+		return ErrCircuitOpen
+	}
+
+	c.probing = true
+	return nil
+}
+
+// release lets another probe through after a probe request
+// ended without telling whether zend is available (its context
+// was cancelled). The failure count is left untouched.
+func (c *circuitBreaker) release() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.probing = false
+}
+
+// record updates the breaker with the outcome of a request.
+// Only failures indicating that zend is unavailable count
+// towards opening the breaker.
+func (c *circuitBreaker) record(available bool) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.probing = false
+	if available {
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.failures >= c.policy.FailureThreshold {
+		c.openUntil = time.Now().Add(c.policy.Cooldown)
+	}
+}
+
+// retriableError wraps an error after which
+// the request can be retried.
+type retriableError struct {
+	err error
+}
+
+func (e *retriableError) Error() string { return e.err.Error() }
+func (e *retriableError) Unwrap() error { return e.err }
+
+// isRetriable returns whether err was
+// caused by zend being unavailable.
+func isRetriable(err error) bool {
+	var r *retriableError
+	return errors.As(err, &r)
+}
+
+// IsUnavailable returns whether err was caused by zend
+// being unavailable (unreachable, warming up, overloaded
+// or the circuit breaker is open). Such requests can
+// succeed once zend is available again.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || isRetriable(err)
+}
+
+// idempotentMethod returns whether a request can be sent
+// again without side effects. Requests that change zend's
+// state (broadcasting a transaction or pruning) are never
+// retried: zend may have processed a request whose response
+// was lost.
+func idempotentMethod(method requestMethod) bool {
+	switch method {
+	case requestMethodGetBlock,
+		requestMethodGetBlockHash,
+		requestMethodGetBlockchainInfo,
+		requestMethodGetPeerInfo,
+		requestMethodEstimateFee,
+		requestMethodRawMempool,
+		requestMethodGetRawTransaction:
+		return true
+	default:
+		return false
+	}
+}
+
+// retriableStatus returns whether an HTTP status
+// indicates that zend is temporarily unavailable. zend
+// returns 503 when its RPC work queue is full.
+func retriableStatus(status int) bool {
+	switch status {
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retriableCode returns whether a JSON-RPC
+// error code is expected to go away on retry.
+func retriableCode(code int64) bool {
+	return code == rpcInWarmupErrCode
+}
@@ -0,0 +1,329 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.5,
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+		Jitter:         0.25,
+	}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 300 * time.Millisecond},
+		{2, 900 * time.Millisecond},
+		{3, time.Second},
+		{10, time.Second},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			delay := policy.backoff(test.attempt)
+			assert.True(t, delay <= test.max, "attempt %d: %s > %s", test.attempt, delay, test.max)
+			assert.True(t, delay >= test.max*3/4, "attempt %d: %s < %s", test.attempt, delay, test.max*3/4)
+		}
+	}
+}
+
+func TestPostRetries(t *testing.T) {
+	tests := map[string]struct {
+		responses []responseFixture
+
+		expectedRequests int64
+		expectedError    string
+	}{
+		"retry warmup": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("rpc_in_warmup_response.json"),
+				},
+				{
+					status: http.StatusInternalServerError,
+					body:   loadFixture("rpc_in_warmup_response.json"),
+				},
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_blockchain_info_response.json"),
+				},
+			},
+			expectedRequests: 3,
+		},
+		"retry work queue full": {
+			responses: []responseFixture{
+				{
+					status: http.StatusServiceUnavailable,
+					body:   "Work queue depth exceeded",
+				},
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_blockchain_info_response.json"),
+				},
+			},
+			expectedRequests: 2,
+		},
+		"give up after max attempts": {
+			responses: []responseFixture{
+				{
+					status: http.StatusServiceUnavailable,
+					body:   "Work queue depth exceeded",
+				},
+				{
+					status: http.StatusServiceUnavailable,
+					body:   "Work queue depth exceeded",
+				},
+				{
+					status: http.StatusOK,
+					body:   loadFixture("rpc_in_warmup_response.json"),
+				},
+			},
+			expectedRequests: 3,
+			expectedError:    "rpc in warmup",
+		},
+		"fatal error": {
+			responses: []responseFixture{
+				{
+					status: http.StatusOK,
+					body:   loadFixture("get_block_not_found_response.json"),
+				},
+			},
+			expectedRequests: 1,
+			expectedError:    "Block not found",
+		},
+		"fatal status": {
+			responses: []responseFixture{
+				{
+					status: http.StatusUnauthorized,
+					body:   "",
+				},
+			},
+			expectedRequests: 1,
+			expectedError:    "401 Unauthorized",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert   = assert.New(t)
+				requests int64
+			)
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := atomic.AddInt64(&requests, 1) - 1
+				response := test.responses[i]
+
+				w.WriteHeader(response.status)
+				fmt.Fprintln(w, response.body)
+			}))
+			defer ts.Close()
+
+			client := NewClient(
+				ts.URL,
+				MainnetGenesisBlockIdentifier,
+				MainnetCurrency,
+				WithRetryPolicy(testRetryPolicy),
+			)
+
+			response := &blockchainInfoResponse{}
+			err := client.post(context.Background(), requestMethodGetBlockchainInfo, nil, response)
+			if len(test.expectedError) > 0 {
+				assert.Contains(err.Error(), test.expectedError)
+			} else {
+				assert.NoError(err)
+				assert.Equal("main", response.Result.Chain)
+			}
+			assert.Equal(test.expectedRequests, atomic.LoadInt64(&requests))
+		})
+	}
+}
+
+func TestPostCircuitBreaker(t *testing.T) {
+	var (
+		requests  int64
+		available int32
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if atomic.LoadInt32(&available) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintln(w, loadFixture("get_blockchain_info_response.json"))
+	}))
+	defer ts.Close()
+
+	client := NewClient(
+		ts.URL,
+		MainnetGenesisBlockIdentifier,
+		MainnetCurrency,
+		WithCircuitBreaker(CircuitBreakerPolicy{
+			FailureThreshold: 2,
+			Cooldown:         50 * time.Millisecond,
+		}),
+	)
+	ctx := context.Background()
+
+	// Opens after FailureThreshold failed requests
+	for i := 0; i < 2; i++ {
+		_, err := client.GetBestBlock(ctx)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+		assert.True(t, IsUnavailable(err))
+	}
+
+	_, err := client.GetBestBlock(ctx)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.True(t, IsUnavailable(err))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+
+	// A failed probe opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetBestBlock(ctx)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	_, err = client.GetBestBlock(ctx)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int64(3), atomic.LoadInt64(&requests))
+
+	// A successful probe closes the breaker
+	atomic.StoreInt32(&available, 1)
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		_, err = client.GetBestBlock(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(5), atomic.LoadInt64(&requests))
+}
+
+func TestPostCircuitBreakerIgnoresFatalErrors(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		fmt.Fprintln(w, loadFixture("get_block_not_found_response.json"))
+	}))
+	defer ts.Close()
+
+	client := NewClient(
+		ts.URL,
+		MainnetGenesisBlockIdentifier,
+		MainnetCurrency,
+		WithCircuitBreaker(CircuitBreakerPolicy{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		response := &blockResponse{}
+		err := client.post(context.Background(), requestMethodGetBlock, nil, response)
+		assert.True(t, errors.Is(err, ErrBlockNotFound))
+		assert.False(t, IsUnavailable(err))
+	}
+	assert.Equal(t, int64(3), atomic.LoadInt64(&requests))
+}
+
+func TestPostDoesNotRetryStateChangingRequests(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := NewClient(
+		ts.URL,
+		MainnetGenesisBlockIdentifier,
+		MainnetCurrency,
+		WithRetryPolicy(testRetryPolicy),
+	)
+	ctx := context.Background()
+
+	_, err := client.SendRawTransaction(ctx, "00")
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+
+	_, err = client.PruneBlockchain(ctx, 100)
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+
+	// Read requests are still retried
+	_, err = client.GetBestBlock(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, int64(2+testRetryPolicy.MaxAttempts), atomic.LoadInt64(&requests))
+}
+
+func TestPostCircuitBreakerIgnoresCancelledRequests(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := NewClient(
+		ts.URL,
+		MainnetGenesisBlockIdentifier,
+		MainnetCurrency,
+		WithCircuitBreaker(CircuitBreakerPolicy{
+			FailureThreshold: 1,
+			Cooldown:         50 * time.Millisecond,
+		}),
+	)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A cancelled request does not open the breaker
+	_, err := client.GetBestBlock(cancelled)
+	assert.Error(t, err)
+	_, err = client.GetBestBlock(context.Background())
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+
+	// A cancelled probe does not keep the breaker open
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetBestBlock(cancelled)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	_, err = client.GetBestBlock(context.Background())
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+}
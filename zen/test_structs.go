@@ -508,6 +508,9 @@ var allTheAboveExpectedBlock = &types.Block{
 							Hex: "483045022100bc79a5cc588247193343fcd3ba491c27d1ab58c4717d22683326b10ddfab49e1022008dd7288e52c46cb118960aeb2652c51cb24b60c6ccb2e80a56b0fe21aa7edf20121039cc2d76c20dcfe4a140a04634e1852a2edff67290fffdf07a38eb5eb53ea1c51",
 						},
 						Sequence: 4294967295,
+						ScID:    "1f758350754c12ac8f75a547f745b75eb744b382e15d0d3b0e24a4b5c5acde00",
+						Epoch:   Int64Pointer(0),
+						Quality: Int64Pointer(11),
 					}),
 				},
 				//change from fee
@@ -545,6 +548,9 @@ var allTheAboveExpectedBlock = &types.Block{
 				},
 				
 			},
+			Metadata: MustMarshalMap(&TransactionMetadata{
+				Fee: Int64Pointer(500004249),
+			}),
 		},
 		// ceasing cert
 		{
@@ -578,6 +584,9 @@ var allTheAboveExpectedBlock = &types.Block{
 							Hex: "76a91463b7a86631fdfd480819e808ac74698f65812cad88ac201e1d7499b6af752266afe4d7a88319bbcfa33463e00e474f7c7055fa07d8080003bee212b4",
 						},
 						Sequence: 4294967295,
+						ScID:    "2f1f1b22ef02396fcb5fcff08915767b57206d3dffca92b211ae4eed3c5f1db7",
+						Epoch:   Int64Pointer(0),
+						Quality: Int64Pointer(3),
 					}),
 				},
 				{
@@ -613,6 +622,9 @@ var allTheAboveExpectedBlock = &types.Block{
 					}),
 				},
 			},
+			Metadata: MustMarshalMap(&TransactionMetadata{
+				Fee: Int64Pointer(1175100606),
+			}),
 		},
 		//ceasing matureCert
 		{
@@ -625,7 +637,7 @@ var allTheAboveExpectedBlock = &types.Block{
 						Index:        0,
 						NetworkIndex: Int64Pointer(1),
 					},
-					Type:   OutputOpType,
+					Type:   BackwardTransferOpType,
 					Status: SuccessStatus,
 					Account: &types.AccountIdentifier{
 						Address: "zteqa5taBUZaJFsTJpmD9KVvCSfWjEG7w2S",
@@ -650,6 +662,10 @@ var allTheAboveExpectedBlock = &types.Block{
 								"zteqa5taBUZaJFsTJpmD9KVvCSfWjEG7w2S",
 							},
 						},
+						ScID:    "03be44ad626a288d2c7e05c7d40f2ba72b8b40749c96fa2cb2201fa0f3b01d6e",
+						Epoch:   Int64Pointer(0),
+						Quality: Int64Pointer(5),
+						Matured: true,
 					}),
 				},
 			},
@@ -54,6 +54,14 @@ const (
 	// Coinbase.
 	CoinbaseOpType = "COINBASE"
 
+	// ForwardTransferOpType is used to describe
+	// a forward transfer to a sidechain.
+	ForwardTransferOpType = "FORWARD_TRANSFER"
+
+	// BackwardTransferOpType is used to describe
+	// a matured backward transfer from a sidechain.
+	BackwardTransferOpType = "BACKWARD_TRANSFER"
+
 	// SuccessStatus is the status of all
 	// Bitcoin operations because anything
 	// on-chain is considered successful.
@@ -83,6 +91,13 @@ const (
 	InputSize                   = 147              // 4 prev index, 32 prev hash, 4 sequence, 1 script size, 106 script sig
 	OutputOverhead              = 9                // 8 value, 1 script size
 	P2PKHReplayScriptPubkeySize = 63               // P2PKH size with replay protection
+	P2SHReplayScriptPubkeySize  = 61               // P2SH size with replay protection
+	ReplayProtectionSize        = 38               // 33 block hash, 4 block height, 1 OP_CHECKBLOCKATHEIGHT
+	SidechainOverhead           = 4                // 1 vcsw_ccin, 1 vsc_ccout, 1 vft_ccout, 1 vmbtr_out
+	ForwardTransferSize         = 92               // 8 value, 32 address, 32 scid, 20 mc return address
+	InputOverhead               = 40               // 4 prev index, 32 prev hash, 4 sequence
+	MultiSigSignatureSize       = 74               // 1 push, 72 signature, 1 sighash type
+	DustThreshold               = 660              // 3 * (72 output + 148 spending input) zatoshis at MinFeeRate
 )
 
 var (
@@ -127,6 +142,8 @@ var (
 		InputOpType,
 		OutputOpType,
 		CoinbaseOpType,
+		ForwardTransferOpType,
+		BackwardTransferOpType,
 	}
 
 	// OperationStatuses are all supported operation.Status.
@@ -168,6 +185,18 @@ type BlockchainInfo struct {
 	Chain         string `json:"chain"`
 	Blocks        int64  `json:"blocks"`
 	BestBlockHash string `json:"bestblockhash"`
+
+	// Headers is the height of the best header chain
+	// known to zend, which is the height zend is
+	// syncing to.
+	Headers int64 `json:"headers"`
+
+	// VerificationProgress is the estimated fraction
+	// of the blockchain verified by zend and
+	// InitialBlockDownload is true while zend is
+	// still downloading the blockchain.
+	VerificationProgress float64 `json:"verificationprogress"`
+	InitialBlockDownload bool    `json:"initialblockdownload"`
 }
 
 // PeerInfo is a collection of relevant info about a particular peer.
@@ -241,6 +270,16 @@ type Transaction struct {
 	Joinsplits []*Joinsplit `json:"vjoinsplit"`
 }
 
+// MempoolEntry is a transaction in the mempool, as returned
+// by verbose `getrawmempool` requests. Fee is in ZEN.
+type MempoolEntry struct {
+	Size    int64    `json:"size"`
+	Fee     float64  `json:"fee"`
+	Time    int64    `json:"time"`
+	Height  int64    `json:"height"`
+	Depends []string `json:"depends"`
+}
+
 type Certificate struct {
 	Hash       string       `json:"txid"`
 	Version    int32        `json:"version"`
@@ -263,6 +302,17 @@ type Cert struct {
 	TotalAmount 				   float64  `json:"totalAmount"`
 }
 
+// Metadata returns the metadata for the operations of a certificate.
+func (c Cert) Metadata() (map[string]interface{}, error) {
+	m := &OperationMetadata{
+		ScID:    c.Scid,
+		Epoch:   &c.EpochNumber,
+		Quality: &c.Quality,
+	}
+
+	return types.MarshalMap(m)
+}
+
 // Joinsplit is a raw Joinsplit transaction representation.
 type Joinsplit struct {
 	VPubOld       float64  `json:"vpub_old"`
@@ -296,6 +346,10 @@ type TransactionMetadata struct {
 	Version   int32        `json:"version,omitempty"`
 	Locktime  int64        `json:"locktime,omitempty"`
 	Joinsplit []*Joinsplit `json:"vjoinsplit,omitempty"`
+
+	// Fee is the fee paid by a certificate (in satoshis).
+	// It is not set for transactions.
+	Fee *int64 `json:"fee,omitempty"`
 }
 
 // Input is a raw input in a Bitcoin transaction.
@@ -347,8 +401,34 @@ type OperationMetadata struct {
 	ScriptSig *ScriptSig `json:"scriptsig,omitempty"`
 	Sequence  int64      `json:"sequence,omitempty"`
 
+	// Construction Input Metadata for pay-to-script-hash multisig
+	// coins: the hex encoded redeem script and, optionally, the
+	// addresses of the keys expected to sign.
+	RedeemScript string   `json:"redeem_script,omitempty"`
+	Signers      []string `json:"signers,omitempty"`
+
 	// Output Metadata
 	ScriptPubKey *ScriptPubKey `json:"scriptPubKey,omitempty"`
+
+	// Construction Output Metadata for OP_RETURN outputs: the hex
+	// encoded data to embed in the null data script.
+	Data string `json:"data,omitempty"`
+
+	// Forward Transfer and Certificate Metadata: Receiver is
+	// the sidechain address credited by a forward transfer and
+	// MCReturnAddress the mainchain address that is refunded
+	// if the sidechain ceases before the transfer is accepted.
+	ScID            string `json:"scid,omitempty"`
+	Receiver        string `json:"receiver,omitempty"`
+	MCReturnAddress string `json:"mc_return_address,omitempty"`
+	Epoch           *int64 `json:"epoch,omitempty"`
+	Quality         *int64 `json:"quality,omitempty"`
+
+	// Matured marks the backward transfers of a certificate
+	// that matured in the block. They are listed in a
+	// transaction with the hash of the certificate (which
+	// was included in an earlier block).
+	Matured bool `json:"matured,omitempty"`
 }
 
 // request represents the JSON-RPC request body
@@ -491,14 +571,10 @@ func (s sendRawTransactionResponse) Err() error {
 	)
 }
 
-type suggestedFeeRate struct {
-	FeeRate float64 `json:"feerate"`
-}
-
-// suggestedFeeRateResponse is the response body for `estimatesmartfee` requests
+// suggestedFeeRateResponse is the response body for `estimatefee` requests
 type suggestedFeeRateResponse struct {
-	Result *suggestedFeeRate `json:"result"`
-	Error  *responseError    `json:"error"`
+	Result float64        `json:"result"`
+	Error  *responseError `json:"error"`
 }
 
 func (s suggestedFeeRateResponse) Err() error {
@@ -533,6 +609,49 @@ func (r rawMempoolResponse) Err() error {
 	)
 }
 
+// rawMempoolVerboseResponse is the response body for verbose
+// `getrawmempool` requests.
+type rawMempoolVerboseResponse struct {
+	Result map[string]*MempoolEntry `json:"result"`
+	Error  *responseError           `json:"error"`
+}
+
+func (r rawMempoolVerboseResponse) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: error JSON RPC response, code: %d, message: %s",
+		ErrJSONRPCError,
+		r.Error.Code,
+		r.Error.Message,
+	)
+}
+
+// rawTransactionResponse is the response body for `getrawtransaction` requests.
+type rawTransactionResponse struct {
+	Result *Transaction   `json:"result"`
+	Error  *responseError `json:"error"`
+}
+
+func (r rawTransactionResponse) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+
+	if r.Error.Code == transactionNotFoundErrCode {
+		return ErrTransactionNotFound
+	}
+
+	return fmt.Errorf(
+		"%w: error JSON RPC response, code: %d, message: %s",
+		ErrJSONRPCError,
+		r.Error.Code,
+		r.Error.Message,
+	)
+}
+
 // CoinIdentifier converts a tx hash and vout into
 // the canonical CoinIdentifier.Identifier used in
 // rosetta-bitcoin.
@@ -82,7 +82,7 @@ var genesisBlock = wire.MsgBlock{
 		MerkleRoot: genesisMerkleRoot,                                                  // 4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b
 		Timestamp:  time.Unix(0x581EA6F5,0),                                            // 2009-01-03 18:15:05 +0000 UTC
 		Bits:       0x1f07ffff,                                                         // 486604799 [00000000ffff0000000000000000000000000000000000000000000000000000]
-		Nonce:      0x000000000000000000000000000000000000000000000000000000000000021d, // 2083236893
+		Nonce:      chainhash.Hash{0x1d, 0x02},                                         // 000000000000000000000000000000000000000000000000000000000000021d
 	},
 	Transactions: []*wire.MsgTx{&genesisCoinbaseTx}, //TODO
 }
@@ -110,7 +110,7 @@ var regTestGenesisBlock = wire.MsgBlock{
 		MerkleRoot: regTestGenesisMerkleRoot, // 4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b
 		Timestamp:  time.Unix(1494548150, 0), // 2011-02-02 23:16:42 +0000 UTC
 		Bits:       0x200f0f0f,               // 545259519 [7fffff0000000000000000000000000000000000000000000000000000000000]
-		Nonce:      0x000000000000000000000000000000000000000000000000000000000000003d,
+		Nonce:      chainhash.Hash{0x3d},      // 000000000000000000000000000000000000000000000000000000000000003d
 	},
 	Transactions: []*wire.MsgTx{&genesisCoinbaseTx}, //TODO
 }
@@ -138,7 +138,7 @@ var testNetGenesisBlock = wire.MsgBlock{
 		MerkleRoot: testNetGenesisMerkleRoot,                                           // 4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b
 		Timestamp:  time.Unix(1479443947, 0),                                           // 2011-02-02 23:16:42 +0000 UTC
 		Bits:       0x2007ffff,                                                         // 486604799 [00000000ffff0000000000000000000000000000000000000000000000000000]
-		Nonce:      0x0000000000000000000000000000000000000000000000000000000000000013, // 414098458
+		Nonce:      chainhash.Hash{0x13},                                               // 0000000000000000000000000000000000000000000000000000000000000013
 	},
 	Transactions: []*wire.MsgTx{&genesisCoinbaseTx}, //TODO
 }
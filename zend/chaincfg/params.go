@@ -7,6 +7,7 @@ package chaincfg
 import (
 	"errors"
 	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+	"github.com/HorizenOfficial/rosetta-zen/zend/equihash"
 	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
 	"math/big"
 	"strings"
@@ -132,6 +133,16 @@ type Params struct {
 	// coins (coinbase transactions) can be spent.
 	CoinbaseMaturity uint16
 
+	// CheckBlockAtHeightSafeDepth is the number of blocks below the main
+	// chain tip after which OP_CHECKBLOCKATHEIGHT is no longer enforced.
+	// Outputs referencing older blocks remain spendable regardless of the
+	// referenced hash.
+	CheckBlockAtHeightSafeDepth int32
+
+	// EquihashParams are the Equihash parameters of the block header
+	// solutions.
+	EquihashParams equihash.Params
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
 
@@ -160,6 +171,10 @@ type Params struct {
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID [4]byte
 	HDPublicKeyID  [4]byte
+
+	// BIP44 coin type used in the hierarchical deterministic path for
+	// address generation.
+	HDCoinType uint32
 }
 
 // MainNetParams defines the network parameters for the main Bitcoin network.
@@ -182,6 +197,8 @@ var MainNetParams = Params{
 	BIP0065Height:            0,
 	BIP0066Height:            0,
 	CoinbaseMaturity:         100,
+	CheckBlockAtHeightSafeDepth: 52596,
+	EquihashParams:           equihash.Params200_9,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
@@ -214,6 +231,10 @@ var MainNetParams = Params{
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x88, 0xad, 0xe4}, // starts with xprv
 	HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e}, // starts with xpub
+
+	// BIP44 coin type used in the hierarchical deterministic path for
+	// address generation.
+	HDCoinType: 121,
 }
 
 // RegressionNetParams defines the network parameters for the regression test
@@ -234,9 +255,11 @@ var RegressionNetParams = Params{
 	GenesisBlock:     &testNetGenesisBlock,
 	GenesisHash:      &testnetGenesisHash,
 	CoinbaseMaturity: 100,
+	CheckBlockAtHeightSafeDepth: 52596,
 	BIP0034Height:            0,
 	BIP0065Height:            0,      // Used by regression tests
 	BIP0066Height:            0,      // Used by regression tests
+	EquihashParams:           equihash.Params200_9,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
@@ -264,6 +287,10 @@ var RegressionNetParams = Params{
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // starts with tprv
 	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
+
+	// BIP44 coin type used in the hierarchical deterministic path for
+	// address generation.
+	HDCoinType: 1,
 }
 
 // TestNet3Params defines the network parameters for the test Bitcoin network
@@ -282,6 +309,8 @@ var RegtestParams = Params{
 	BIP0065Height:            0,
 	BIP0066Height:            0,
 	CoinbaseMaturity:         100,
+	CheckBlockAtHeightSafeDepth: 52596,
+	EquihashParams:           equihash.Params48_5,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
@@ -303,6 +332,10 @@ var RegtestParams = Params{
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // starts with tprv
 	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
+
+	// BIP44 coin type used in the hierarchical deterministic path for
+	// address generation.
+	HDCoinType: 1,
 }
 
 var (
@@ -0,0 +1,137 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package equihash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Equihash hashes with a personalized BLAKE2b, which golang.org/x/crypto/blake2b
+// does not support, so a minimal sequential implementation (RFC 7693) is
+// provided here.
+
+const (
+	blake2bBlockSize    = 128
+	blake2bPersonalSize = 16
+)
+
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b,
+	0xa54ff53a5f1d36f1, 0x510e527fade682d1, 0x9b05688c2b3e6c1f,
+	0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var blake2bSigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+// blake2b is an unkeyed, personalized BLAKE2b state. It is a value type so
+// that a partially written state can be copied and reused.
+type blake2b struct {
+	h      [8]uint64
+	t      uint64
+	block  [blake2bBlockSize]byte
+	offset int
+	size   int
+}
+
+// newBlake2b returns a BLAKE2b state producing size byte digests with the
+// provided 16 byte personalization.
+func newBlake2b(size int, personal [blake2bPersonalSize]byte) blake2b {
+	d := blake2b{h: blake2bIV, size: size}
+	d.h[0] ^= uint64(size) | 1<<16 | 1<<24
+	d.h[6] ^= binary.LittleEndian.Uint64(personal[:8])
+	d.h[7] ^= binary.LittleEndian.Uint64(personal[8:])
+
+	return d
+}
+
+// Write adds p to the hashed data.
+func (d *blake2b) Write(p []byte) {
+	for len(p) > 0 {
+		// The last block is only compressed in Sum, once we know it
+		// is the final one.
+		if d.offset == blake2bBlockSize {
+			d.t += blake2bBlockSize
+			d.compress(false)
+			d.offset = 0
+		}
+
+		n := copy(d.block[d.offset:], p)
+		d.offset += n
+		p = p[n:]
+	}
+}
+
+// Sum returns the digest of the data written so far without modifying
+// the state.
+func (d blake2b) Sum() []byte {
+	d.t += uint64(d.offset)
+	for i := d.offset; i < blake2bBlockSize; i++ {
+		d.block[i] = 0
+	}
+	d.compress(true)
+
+	out := make([]byte, 64)
+	for i, v := range d.h {
+		binary.LittleEndian.PutUint64(out[i*8:], v)
+	}
+
+	return out[:d.size]
+}
+
+// compress mixes the current block into the state.
+func (d *blake2b) compress(final bool) {
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(d.block[i*8:])
+	}
+
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], blake2bIV[:])
+	v[12] ^= d.t
+	if final {
+		v[14] = ^v[14]
+	}
+
+	g := func(a, b, c, d int, x, y uint64) {
+		v[a] = v[a] + v[b] + x
+		v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+		v[c] = v[c] + v[d]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+		v[a] = v[a] + v[b] + y
+		v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+		v[c] = v[c] + v[d]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+	}
+
+	for _, s := range blake2bSigma {
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
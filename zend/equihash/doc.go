@@ -0,0 +1,8 @@
+// Package equihash implements verification of Equihash proof-of-work
+// solutions as used by zend.
+//
+// A block header commits to an Equihash solution over the header fields
+// preceding the nonce. This package checks that a solution is a valid
+// generalized birthday collision for the given parameters; checking the
+// block hash against the target is left to the caller.
+package equihash
@@ -0,0 +1,252 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package equihash
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidParams is returned when the Equihash parameters are not
+	// supported.
+	ErrInvalidParams = errors.New("invalid equihash parameters")
+
+	// ErrInvalidSolutionSize is returned when the solution length does not
+	// match the parameters.
+	ErrInvalidSolutionSize = errors.New("invalid equihash solution size")
+
+	// ErrInvalidSolution is returned when the solution does not satisfy
+	// the Equihash collision rules.
+	ErrInvalidSolution = errors.New("invalid equihash solution")
+)
+
+// Params defines the Equihash parameters N and K.
+type Params struct {
+	N uint32
+	K uint32
+}
+
+var (
+	// Params200_9 are the parameters used by the zen main and test
+	// networks.
+	Params200_9 = Params{N: 200, K: 9}
+
+	// Params144_5 are the parameters introduced by the Zcash and zen
+	// Equihash forks for ASIC-resistant mining.
+	Params144_5 = Params{N: 144, K: 5}
+
+	// Params48_5 are the parameters used by the zen regression test
+	// network.
+	Params48_5 = Params{N: 48, K: 5}
+)
+
+// knownParams lists the parameters recognized by ParamsForSolution.
+var knownParams = []Params{Params200_9, Params144_5, Params48_5}
+
+// ParamsForSolution returns the known parameters whose solution size matches
+// the length of solution.
+func ParamsForSolution(solution []byte) (Params, error) {
+	for _, p := range knownParams {
+		if p.SolutionSize() == len(solution) {
+			return p, nil
+		}
+	}
+
+	return Params{}, fmt.Errorf("%w: no parameters for %d bytes",
+		ErrInvalidSolutionSize, len(solution))
+}
+
+// validate ensures the parameters can be used to verify solutions.
+func (p Params) validate() error {
+	if p.K == 0 || p.K >= p.N || p.N%8 != 0 || p.N%(p.K+1) != 0 ||
+		p.N > 512 || p.collisionBitLength()+1 >= 32 {
+		return fmt.Errorf("%w: n=%d k=%d", ErrInvalidParams, p.N, p.K)
+	}
+
+	return nil
+}
+
+// collisionBitLength is the number of bits that must collide at each step.
+func (p Params) collisionBitLength() int {
+	return int(p.N / (p.K + 1))
+}
+
+// collisionByteLength is the number of bytes used to store each collision
+// chunk of an expanded hash.
+func (p Params) collisionByteLength() int {
+	return (p.collisionBitLength() + 7) / 8
+}
+
+// indicesPerHashOutput is the number of n-bit hashes extracted from a single
+// BLAKE2b output.
+func (p Params) indicesPerHashOutput() int {
+	return int(512 / p.N)
+}
+
+// SolutionSize returns the length in bytes of a minimally encoded solution.
+func (p Params) SolutionSize() int {
+	return (1 << p.K) * (p.collisionBitLength() + 1) / 8
+}
+
+// personalization returns the BLAKE2b personalization for the parameters.
+func (p Params) personalization() [blake2bPersonalSize]byte {
+	var personal [blake2bPersonalSize]byte
+	copy(personal[:], "ZcashPoW")
+	binary.LittleEndian.PutUint32(personal[8:], p.N)
+	binary.LittleEndian.PutUint32(personal[12:], p.K)
+
+	return personal
+}
+
+// row is an intermediate node of the solution tree: the xor of the expanded
+// hashes of its indices, with the already colliding prefix trimmed.
+type row struct {
+	hash    []byte
+	indices []uint32
+}
+
+// Verify checks that solution is a valid Equihash solution for the given
+// parameters over input, the serialized header fields preceding the nonce,
+// and nonce.
+func Verify(params Params, input []byte, nonce []byte, solution []byte) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	if len(solution) != params.SolutionSize() {
+		return fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidSolutionSize,
+			len(solution), params.SolutionSize())
+	}
+
+	state := newBlake2b(params.indicesPerHashOutput()*int(params.N)/8,
+		params.personalization())
+	state.Write(input)
+	state.Write(nonce)
+
+	indices := indicesFromMinimal(solution, params.collisionBitLength())
+	collisionBytes := params.collisionByteLength()
+	hashLen := int(params.K+1) * collisionBytes
+
+	rows := make([]row, len(indices))
+	for i, index := range indices {
+		rows[i] = row{
+			hash:    generateHash(params, state, index),
+			indices: []uint32{index},
+		}
+	}
+
+	for len(rows) > 1 {
+		merged := make([]row, 0, len(rows)/2)
+		for i := 0; i < len(rows); i += 2 {
+			a, b := rows[i], rows[i+1]
+			for j := 0; j < collisionBytes; j++ {
+				if a.hash[j] != b.hash[j] {
+					return fmt.Errorf("%w: no collision at %d", ErrInvalidSolution,
+						len(a.indices))
+				}
+			}
+
+			if b.indices[0] < a.indices[0] {
+				return fmt.Errorf("%w: indices out of order", ErrInvalidSolution)
+			}
+
+			if !distinctIndices(a.indices, b.indices) {
+				return fmt.Errorf("%w: duplicate indices", ErrInvalidSolution)
+			}
+
+			hash := make([]byte, hashLen-collisionBytes)
+			for j := range hash {
+				hash[j] = a.hash[collisionBytes+j] ^ b.hash[collisionBytes+j]
+			}
+
+			merged = append(merged, row{
+				hash:    hash,
+				indices: append(append([]uint32{}, a.indices...), b.indices...),
+			})
+		}
+
+		rows = merged
+		hashLen -= collisionBytes
+	}
+
+	for _, b := range rows[0].hash {
+		if b != 0 {
+			return fmt.Errorf("%w: non-zero final hash", ErrInvalidSolution)
+		}
+	}
+
+	return nil
+}
+
+// generateHash returns the expanded n-bit hash for index.
+func generateHash(params Params, state blake2b, index uint32) []byte {
+	perOutput := uint32(params.indicesPerHashOutput())
+
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], index/perOutput)
+	state.Write(buf[:])
+	out := state.Sum()
+
+	start := (index % perOutput) * params.N / 8
+	return expandArray(out[start:start+params.N/8], params.collisionBitLength())
+}
+
+// indicesFromMinimal decodes the big-endian, bitLen+1 bit indices packed in a
+// minimally encoded solution.
+func indicesFromMinimal(solution []byte, bitLen int) []uint32 {
+	width := bitLen + 1
+	indices := make([]uint32, 0, len(solution)*8/width)
+
+	var acc uint64
+	var accBits int
+	for _, b := range solution {
+		acc = acc<<8 | uint64(b)
+		accBits += 8
+		if accBits >= width {
+			accBits -= width
+			indices = append(indices, uint32(acc>>uint(accBits))&(1<<uint(width)-1))
+		}
+	}
+
+	return indices
+}
+
+// expandArray splits in into bitLen bit chunks, storing each big-endian chunk
+// in (bitLen+7)/8 bytes.
+func expandArray(in []byte, bitLen int) []byte {
+	width := (bitLen + 7) / 8
+	out := make([]byte, 0, len(in)*8/bitLen*width)
+
+	var acc uint64
+	var accBits int
+	for _, b := range in {
+		acc = acc<<8 | uint64(b)
+		accBits += 8
+		if accBits >= bitLen {
+			accBits -= bitLen
+			chunk := (acc >> uint(accBits)) & (1<<uint(bitLen) - 1)
+			for x := width - 1; x >= 0; x-- {
+				out = append(out, byte(chunk>>(8*uint(x))))
+			}
+		}
+	}
+
+	return out
+}
+
+// distinctIndices returns whether a and b share no index.
+func distinctIndices(a, b []uint32) bool {
+	for _, i := range a {
+		for _, j := range b {
+			if i == j {
+				return false
+			}
+		}
+	}
+
+	return true
+}
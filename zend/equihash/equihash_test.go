@@ -0,0 +1,256 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package equihash
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// decodeHex decodes s, failing the test on error.
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+
+	return b
+}
+
+// minimalFromIndices packs indices into a minimally encoded solution, the
+// inverse of indicesFromMinimal.
+func minimalFromIndices(indices []uint32, bitLen int) []byte {
+	width := uint(bitLen + 1)
+	out := make([]byte, 0, len(indices)*int(width)/8)
+
+	var acc uint64
+	var accBits uint
+	for _, index := range indices {
+		acc = acc<<width | uint64(index)
+		accBits += width
+		for accBits >= 8 {
+			accBits -= 8
+			out = append(out, byte(acc>>accBits))
+		}
+	}
+
+	return out
+}
+
+// TestBlake2b tests the personalized BLAKE2b against digests computed with
+// the reference implementation.
+func TestBlake2b(t *testing.T) {
+	long := make([]byte, 512)
+	for i := range long {
+		long[i] = byte(i)
+	}
+
+	tests := []struct {
+		params Params
+		size   int
+		chunks [][]byte
+		want   string
+	}{
+		{
+			Params200_9, 50, nil,
+			"42fadb7376483e2167dbb245215129da15280a65062e68cf07cc9bc3f71905b8" +
+				"070472455b9fc809308919b7834c78b40726",
+		},
+		{
+			Params200_9, 50, [][]byte{long[:100], long[100:128], long[128:]},
+			"d7ee8213680159fdba70d51153831521c6c52007ee5f46b751619389631ffdc7" +
+				"5e30d4ce3cba0a022b94ca1d044d3c1caf45",
+		},
+		{
+			Params48_5, 60, [][]byte{[]byte("abc")},
+			"38098d28d404cb9304cafcb904d9eeed66be0850d68b0f6c7d22b95f2c2a1bbe" +
+				"15fe52a076b73618aca61a994c06b05e3264426c34bc263957fd9d40",
+		},
+	}
+
+	for i, test := range tests {
+		d := newBlake2b(test.size, test.params.personalization())
+		for _, chunk := range test.chunks {
+			d.Write(chunk)
+		}
+
+		if got := hex.EncodeToString(d.Sum()); got != test.want {
+			t.Errorf("#%d: got %s, want %s", i, got, test.want)
+		}
+	}
+}
+
+// regtestInput, regtestNonce and regtestSolution are the Equihash input,
+// nonce and solution of a block header mined with the (48, 5) parameters.
+const (
+	regtestInput = "04000000" +
+		"b9c4f2c332bd1badb52b8a0a2a0a3e8db5e5566f0f8e6d5eab212ae7b13b1d0a" +
+		"8e00984732c5983c9cd20b8befc34b77b3c01aa26bdb0c3bed67822acf907a3a" +
+		"66d4012f66d4758b33d22889956ed49d95c112d891637f53e6f000c46ce9650d" +
+		"6bc46d610f0f0f20"
+	regtestNonce    = "0500000000000000000000000000000000000000000000000000000000000000"
+	regtestSolution = "04fb2f1ce482e17cd907a05638e1055998e71a4a0cff53bbd9d31743385f5a57575ecb93"
+)
+
+// TestVerify tests verifying valid and tampered solutions.
+func TestVerify(t *testing.T) {
+	input := decodeHex(t, regtestInput)
+	nonce := decodeHex(t, regtestNonce)
+	solution := decodeHex(t, regtestSolution)
+
+	if err := Verify(Params48_5, input, nonce, solution); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	indices := indicesFromMinimal(solution, Params48_5.collisionBitLength())
+	if len(indices) != 32 {
+		t.Fatalf("indicesFromMinimal: got %d indices, want 32", len(indices))
+	}
+	if encoded := minimalFromIndices(indices, 8); hex.EncodeToString(encoded) != regtestSolution {
+		t.Fatalf("minimalFromIndices: got %x, want %s", encoded, regtestSolution)
+	}
+
+	// swapped exchanges the first two indices, which still collide but are
+	// no longer ordered.
+	swapped := append([]uint32{}, indices...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	// duplicated repeats the first half of the solution.
+	duplicated := append(append([]uint32{}, indices[:16]...), indices[:16]...)
+
+	// changed replaces the last index.
+	changed := append([]uint32{}, indices...)
+	changed[31] ^= 0x01
+
+	tamperedInput := append([]byte{}, input...)
+	tamperedInput[len(tamperedInput)-1] ^= 0x01
+
+	tests := []struct {
+		name     string
+		params   Params
+		input    []byte
+		solution []byte
+		err      error
+	}{
+		{"input", Params48_5, tamperedInput, solution, ErrInvalidSolution},
+		{"order", Params48_5, input, minimalFromIndices(swapped, 8), ErrInvalidSolution},
+		{"duplicate", Params48_5, input, minimalFromIndices(duplicated, 8), ErrInvalidSolution},
+		{"index", Params48_5, input, minimalFromIndices(changed, 8), ErrInvalidSolution},
+		{"size", Params48_5, input, solution[:35], ErrInvalidSolutionSize},
+		{"params", Params144_5, input, solution, ErrInvalidSolutionSize},
+		{"invalid params", Params{N: 48, K: 48}, input, solution, ErrInvalidParams},
+		{"unaligned params", Params{N: 50, K: 4}, input, solution, ErrInvalidParams},
+	}
+
+	for _, test := range tests {
+		err := Verify(test.params, test.input, nonce, test.solution)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+// TestVerify200_9 tests verifying a solution with the parameters of the main
+// network.  The test data holds the Equihash input, nonce and solution of a
+// block header, one per line.
+func TestVerify200_9(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "solution_200_9.hex"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	lines := strings.Fields(string(b))
+	if len(lines) != 3 {
+		t.Fatalf("unexpected test data with %d lines", len(lines))
+	}
+	input := decodeHex(t, lines[0])
+	nonce := decodeHex(t, lines[1])
+	solution := decodeHex(t, lines[2])
+
+	params, err := ParamsForSolution(solution)
+	if err != nil || params != Params200_9 {
+		t.Fatalf("ParamsForSolution: got %+v, %v", params, err)
+	}
+
+	if err := Verify(params, input, nonce, solution); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	indices := indicesFromMinimal(solution, params.collisionBitLength())
+	indices[len(indices)-2], indices[len(indices)-1] =
+		indices[len(indices)-1], indices[len(indices)-2]
+	err = Verify(params, input, nonce, minimalFromIndices(indices, 20))
+	if !errors.Is(err, ErrInvalidSolution) {
+		t.Errorf("Verify: got %v, want %v", err, ErrInvalidSolution)
+	}
+}
+
+// TestVerifyMainnetGenesis tests verifying the solution of the genesis block
+// header of the main network, 0007104ccda289427919efc39dc9e4d499804b7bebc22df55f8b834301260602.
+func TestVerifyMainnetGenesis(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("..", "wire", "testdata", "header_0007104c.hex"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	// The header holds the Equihash input (108 bytes), the nonce
+	// (32 bytes) and the solution after its 3 byte length prefix.
+	header := decodeHex(t, strings.TrimSpace(string(b)))
+	input := header[:108]
+	nonce := header[108:140]
+	solution := header[143:]
+
+	if err := Verify(Params200_9, input, nonce, solution); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	for _, i := range []int{0, 671, 1343} {
+		tampered := append([]byte{}, solution...)
+		tampered[i] ^= 0x01
+		err := Verify(Params200_9, input, nonce, tampered)
+		if !errors.Is(err, ErrInvalidSolution) {
+			t.Errorf("Verify with byte %d flipped: got %v, want %v", i, err,
+				ErrInvalidSolution)
+		}
+	}
+
+	// The solution is only valid for the header it was found for.
+	tampered := append([]byte{}, input...)
+	tampered[0] = 0x01
+	if err := Verify(Params200_9, tampered, nonce, solution); !errors.Is(err, ErrInvalidSolution) {
+		t.Errorf("Verify with version 1: got %v, want %v", err, ErrInvalidSolution)
+	}
+}
+
+// TestParamsForSolution tests selecting the parameters from the solution
+// size.
+func TestParamsForSolution(t *testing.T) {
+	tests := []struct {
+		size   int
+		params Params
+		err    error
+	}{
+		{1344, Params200_9, nil},
+		{100, Params144_5, nil},
+		{36, Params48_5, nil},
+		{0, Params{}, ErrInvalidSolutionSize},
+		{1343, Params{}, ErrInvalidSolutionSize},
+	}
+
+	for _, test := range tests {
+		params, err := ParamsForSolution(make([]byte, test.size))
+		if !errors.Is(err, test.err) {
+			t.Errorf("%d: got error %v, want %v", test.size, err, test.err)
+			continue
+		}
+		if params != test.params {
+			t.Errorf("%d: got %+v, want %+v", test.size, params, test.params)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+)
+
+// BlockHashProvider provides the main chain block hashes needed to
+// verify OP_CHECKBLOCKATHEIGHT.
+type BlockHashProvider interface {
+	// BestHeight returns the height of the main chain tip.
+	BestHeight() (int32, error)
+
+	// BlockHash returns the hash of the main chain block at
+	// the provided height.
+	BlockHash(height int32) (*chainhash.Hash, error)
+}
+
+// StaticBlockHashes is a BlockHashProvider backed by a map of
+// block heights to hashes.  The highest height in the map is
+// considered the main chain tip.  It is intended for snapshots of
+// the blocks referenced by a transaction and for tests.
+type StaticBlockHashes map[int32]chainhash.Hash
+
+// BestHeight returns the highest height in the map.
+func (s StaticBlockHashes) BestHeight() (int32, error) {
+	if len(s) == 0 {
+		return 0, fmt.Errorf("no block hashes available")
+	}
+
+	best := int32(-1)
+	for height := range s {
+		if height > best {
+			best = height
+		}
+	}
+
+	return best, nil
+}
+
+// BlockHash returns the hash stored for the provided height.
+func (s StaticBlockHashes) BlockHash(height int32) (*chainhash.Hash, error) {
+	hash, ok := s[height]
+	if !ok {
+		return nil, fmt.Errorf("no block hash for height %d", height)
+	}
+
+	return &hash, nil
+}
+
+// ExtractCheckBlockAtHeight returns the block heights referenced by
+// the OP_CHECKBLOCKATHEIGHT opcodes of the passed script, such as the
+// replay protected scripts created by PayToAddrReplayOutScript.
+func ExtractCheckBlockAtHeight(script []byte) ([]int32, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+
+	var heights []int32
+	for i := 1; i < len(pops); i++ {
+		if pops[i].opcode.value != OP_CHECKBLOCKATHEIGHT {
+			continue
+		}
+
+		// The height is pushed right before the opcode, either
+		// as a small integer or as a script number.
+		pop := pops[i-1]
+		if isSmallInt(pop.opcode) {
+			heights = append(heights, int32(asSmallInt(pop.opcode)))
+			continue
+		}
+
+		height, err := makeScriptNum(pop.data, false, 4)
+		if err != nil {
+			return nil, err
+		}
+		heights = append(heights, height.Int32())
+	}
+
+	return heights, nil
+}
+
+// checkBlockHash verifies that vchBlockHash, in internal byte order,
+// is the hash of the main chain block at height according to zend's
+// rules: the referenced block must not be above the tip, blocks more
+// than safeDepth below the tip are exempt, and the hash of any other
+// block must match.
+func checkBlockHash(provider BlockHashProvider, safeDepth int32, height int32,
+	vchBlockHash []byte) error {
+	best, err := provider.BestHeight()
+	if err != nil {
+		str := fmt.Sprintf("unable to get best block height: %v", err)
+		return scriptError(ErrCheckBlockAtHeightUnsatisfied, str)
+	}
+
+	if height > best {
+		str := fmt.Sprintf("referenced block height %d is above the "+
+			"best block height %d", height, best)
+		return scriptError(ErrCheckBlockAtHeightUnsatisfied, str)
+	}
+
+	if height < best-safeDepth {
+		return nil
+	}
+
+	hash, err := provider.BlockHash(height)
+	if err != nil {
+		str := fmt.Sprintf("unable to get block hash at height %d: %v",
+			height, err)
+		return scriptError(ErrCheckBlockAtHeightUnsatisfied, str)
+	}
+
+	if !bytes.Equal(hash[:], vchBlockHash) {
+		str := fmt.Sprintf("referenced block hash does not match block "+
+			"%s at height %d", hash, height)
+		return scriptError(ErrCheckBlockAtHeightUnsatisfied, str)
+	}
+
+	return nil
+}
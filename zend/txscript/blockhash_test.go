@@ -0,0 +1,225 @@
+// Copyright (c) 2013-2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg"
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
+)
+
+// TestCheckBlockAtHeight ensures OP_CHECKBLOCKATHEIGHT is verified against
+// the BlockHashProvider according to zend's rules when
+// ScriptVerifyCheckBlockAtHeight is set.
+func TestCheckBlockAtHeight(t *testing.T) {
+	t.Parallel()
+
+	blockHash := func(b byte) chainhash.Hash {
+		var hash chainhash.Hash
+		for i := range hash {
+			hash[i] = b
+		}
+		return hash
+	}
+
+	params := &chaincfg.MainNetParams
+	best := params.CheckBlockAtHeightSafeDepth + 100
+	oldest := best - params.CheckBlockAtHeightSafeDepth
+	provider := StaticBlockHashes{
+		0:      blockHash(0x01),
+		oldest: blockHash(0x02),
+		100:    blockHash(0x03),
+		best:   blockHash(0x04),
+	}
+
+	tests := []struct {
+		name     string
+		hash     chainhash.Hash
+		height   int64
+		flags    ScriptFlags
+		provider BlockHashProvider
+		noParams bool
+		err      *ErrorCode
+	}{
+		{
+			name:   "not verified without flag",
+			hash:   blockHash(0xff),
+			height: int64(best),
+		},
+		{
+			name:     "matching hash",
+			hash:     blockHash(0x04),
+			height:   int64(best),
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+		},
+		{
+			name:     "mismatched hash",
+			hash:     blockHash(0xff),
+			height:   int64(best),
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+			err:      errCode(ErrCheckBlockAtHeightUnsatisfied),
+		},
+		{
+			name:     "height above tip",
+			hash:     blockHash(0x04),
+			height:   int64(best) + 1,
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+			err:      errCode(ErrCheckBlockAtHeightUnsatisfied),
+		},
+		{
+			name:     "mismatched hash at safe depth",
+			hash:     blockHash(0xff),
+			height:   int64(oldest),
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+			err:      errCode(ErrCheckBlockAtHeightUnsatisfied),
+		},
+		{
+			name:     "mismatched hash below safe depth",
+			hash:     blockHash(0xff),
+			height:   int64(oldest) - 1,
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+		},
+		{
+			name:     "unknown block",
+			hash:     blockHash(0x03),
+			height:   int64(best) - 1,
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+			err:      errCode(ErrCheckBlockAtHeightUnsatisfied),
+		},
+		{
+			name:   "flag without provider",
+			hash:   blockHash(0x04),
+			height: int64(best),
+			flags:  ScriptVerifyCheckBlockAtHeight,
+			err:    errCode(ErrInvalidFlags),
+		},
+		{
+			name:     "flag without chain parameters",
+			hash:     blockHash(0x04),
+			height:   int64(best),
+			flags:    ScriptVerifyCheckBlockAtHeight,
+			provider: provider,
+			noParams: true,
+			err:      errCode(ErrInvalidFlags),
+		},
+	}
+
+	for _, test := range tests {
+		tx := &wire.MsgTx{
+			Version: 1,
+			TxIn: []*wire.TxIn{{
+				SignatureScript: mustParseShortForm("TRUE"),
+				Sequence:        wire.MaxTxInSequenceNum,
+			}},
+			TxOut: []*wire.TxOut{{Value: 1}},
+		}
+		pkScript, err := NewScriptBuilder().AddData(test.hash[:]).
+			AddInt64(test.height).AddOp(OP_CHECKBLOCKATHEIGHT).Script()
+		if err != nil {
+			t.Fatalf("%s: unable to build script: %v", test.name, err)
+		}
+
+		chainParams := params
+		if test.noParams {
+			chainParams = nil
+		}
+		vm, err := NewEngineWithBlockHashes(pkScript, tx, 0, test.flags,
+			nil, nil, -1, test.provider, chainParams)
+		if err == nil {
+			err = vm.Execute()
+		}
+
+		if test.err == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		if !IsErrorCode(err, *test.err) {
+			t.Errorf("%s: expected error %v, got %v", test.name,
+				*test.err, err)
+		}
+	}
+}
+
+// TestStaticBlockHashes ensures StaticBlockHashes reports the highest
+// height as the tip and fails for unknown heights.
+func TestStaticBlockHashes(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (StaticBlockHashes{}).BestHeight(); err == nil {
+		t.Error("BestHeight succeeded without block hashes")
+	}
+
+	provider := StaticBlockHashes{5: chainhash.Hash{0x05}, 2: chainhash.Hash{0x02}}
+	best, err := provider.BestHeight()
+	if err != nil || best != 5 {
+		t.Errorf("BestHeight: got %d, %v, want 5", best, err)
+	}
+
+	hash, err := provider.BlockHash(2)
+	if err != nil || *hash != (chainhash.Hash{0x02}) {
+		t.Errorf("BlockHash(2): got %v, %v", hash, err)
+	}
+
+	if _, err := provider.BlockHash(3); err == nil {
+		t.Error("BlockHash succeeded for an unknown height")
+	}
+}
+
+// TestExtractCheckBlockAtHeight ensures the heights referenced by
+// OP_CHECKBLOCKATHEIGHT are extracted from replay protected scripts.
+func TestExtractCheckBlockAtHeight(t *testing.T) {
+	t.Parallel()
+
+	hash := chainhash.Hash{0x01}
+	replayScript := func(height int64) []byte {
+		script, err := NewScriptBuilder().AddOp(OP_DUP).AddOp(OP_HASH160).
+			AddData(make([]byte, 20)).AddOp(OP_EQUALVERIFY).
+			AddOp(OP_CHECKSIG).AddData(hash[:]).AddInt64(height).
+			AddOp(OP_CHECKBLOCKATHEIGHT).Script()
+		if err != nil {
+			t.Fatalf("unable to build script: %v", err)
+		}
+		return script
+	}
+
+	tests := []struct {
+		name   string
+		script []byte
+		want   []int32
+	}{
+		{"small height", replayScript(16), []int32{16}},
+		{"height", replayScript(1234567), []int32{1234567}},
+		{"no replay protection", mustParseShortForm("DUP HASH160 DATA_20 " +
+			"0x0000000000000000000000000000000000000000 EQUALVERIFY " +
+			"CHECKSIG"), nil},
+	}
+
+	for _, test := range tests {
+		heights, err := ExtractCheckBlockAtHeight(test.script)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(heights, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, heights, test.want)
+		}
+	}
+}
+
+// errCode returns a pointer to the provided ErrorCode.
+func errCode(code ErrorCode) *ErrorCode {
+	return &code
+}
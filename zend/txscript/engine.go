@@ -12,6 +12,7 @@ import (
 	"math/big"
 
 	"github.com/HorizenOfficial/rosetta-zen/zend/btcec"
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg"
 	"github.com/HorizenOfficial/rosetta-zen/zend/wire"
 )
 
@@ -94,6 +95,12 @@ const (
 	// operation whose public key isn't serialized in a compressed format
 	// non-standard.
 	ScriptVerifyWitnessPubKeyType
+
+	// ScriptVerifyCheckBlockAtHeight defines whether to verify the block
+	// hash referenced by OP_CHECKBLOCKATHEIGHT against the main chain
+	// using the engine's BlockHashProvider.  When it is not set, the
+	// opcode only checks that the referenced height is not negative.
+	ScriptVerifyCheckBlockAtHeight
 )
 
 const (
@@ -136,6 +143,8 @@ type Engine struct {
 	witnessVersion  int
 	witnessProgram  []byte
 	inputAmount     int64
+	blockHashes     BlockHashProvider
+	chainParams     *chaincfg.Params
 }
 
 // hasFlag returns whether the script engine instance has the passed flag set.
@@ -846,6 +855,19 @@ func (vm *Engine) SetAltStack(data [][]byte) {
 func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags,
 	sigCache *SigCache, hashCache *TxSigHashes, inputAmount int64) (*Engine, error) {
 
+	return NewEngineWithBlockHashes(scriptPubKey, tx, txIdx, flags, sigCache,
+		hashCache, inputAmount, nil, nil)
+}
+
+// NewEngineWithBlockHashes is like NewEngine, but the provided
+// BlockHashProvider of the chain described by chainParams is used to
+// verify OP_CHECKBLOCKATHEIGHT when the ScriptVerifyCheckBlockAtHeight
+// flag is set.
+func NewEngineWithBlockHashes(scriptPubKey []byte, tx *wire.MsgTx, txIdx int,
+	flags ScriptFlags, sigCache *SigCache, hashCache *TxSigHashes,
+	inputAmount int64, blockHashes BlockHashProvider,
+	chainParams *chaincfg.Params) (*Engine, error) {
+
 	// The provided transaction input index must refer to a valid input.
 	if txIdx < 0 || txIdx >= len(tx.TxIn) {
 		str := fmt.Sprintf("transaction input index %d is negative or "+
@@ -874,13 +896,23 @@ func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags
 	// when it should be. The same goes for segwit which will pull in
 	// additional scripts for execution from the witness stack.
 	vm := Engine{flags: flags, sigCache: sigCache, hashCache: hashCache,
-		inputAmount: inputAmount}
+		inputAmount: inputAmount, blockHashes: blockHashes,
+		chainParams: chainParams}
 	if vm.hasFlag(ScriptVerifyCleanStack) && (!vm.hasFlag(ScriptBip16) &&
 		!vm.hasFlag(ScriptVerifyWitness)) {
 		return nil, scriptError(ErrInvalidFlags,
 			"invalid flags combination")
 	}
 
+	// Block hashes referenced by OP_CHECKBLOCKATHEIGHT can only be
+	// verified when there is a chain to verify them against.
+	if vm.hasFlag(ScriptVerifyCheckBlockAtHeight) &&
+		(blockHashes == nil || chainParams == nil) {
+		return nil, scriptError(ErrInvalidFlags,
+			"check block at height requires a block hash provider "+
+				"and chain parameters")
+	}
+
 	// The signature script must only contain data pushes when the
 	// associated flag is set.
 	if vm.hasFlag(ScriptVerifySigPushOnly) && !IsPushOnlyScript(scriptSig) {
@@ -914,7 +946,10 @@ func NewEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int, flags ScriptFlags
 		vm.scriptIdx++
 	}
 
-	if vm.hasFlag(ScriptBip16) && isScriptHash(vm.scripts[1]) {
+	// zend evaluates the redeem script of replay protected
+	// pay-to-script-hash outputs as well.
+	if vm.hasFlag(ScriptBip16) && (isScriptHash(vm.scripts[1]) ||
+		isScriptHashReplayOut(vm.scripts[1])) {
 		// Only accept input scripts that push data for P2SH.
 		if !isPushOnly(vm.scripts[0]) {
 			return nil, scriptError(ErrNotPushOnly,
@@ -338,6 +338,11 @@ const (
 	// serialized in a compressed format.
 	ErrWitnessPubKeyType
 
+	// ErrCheckBlockAtHeightUnsatisfied is returned when a script contains
+	// an OP_CHECKBLOCKATHEIGHT whose block hash does not match the main
+	// chain block at the referenced height.
+	ErrCheckBlockAtHeightUnsatisfied
+
 	// numErrorCodes is the maximum error code number used in tests.  This
 	// entry MUST be the last entry in the enum.
 	numErrorCodes
@@ -409,6 +414,7 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrMinimalIf:                          "ErrMinimalIf",
 	ErrWitnessPubKeyType:                  "ErrWitnessPubKeyType",
 	ErrDiscourageUpgradableWitnessProgram: "ErrDiscourageUpgradableWitnessProgram",
+	ErrCheckBlockAtHeightUnsatisfied:      "ErrCheckBlockAtHeightUnsatisfied",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -80,6 +80,7 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrMinimalIf, "ErrMinimalIf"},
 		{ErrWitnessPubKeyType, "ErrWitnessPubKeyType"},
 		{ErrDiscourageUpgradableWitnessProgram, "ErrDiscourageUpgradableWitnessProgram"},
+		{ErrCheckBlockAtHeightUnsatisfied, "ErrCheckBlockAtHeightUnsatisfied"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 
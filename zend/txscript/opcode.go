@@ -1214,20 +1214,27 @@ func opcodeCheckBlockAtHeight(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	if nHeight < 0 || !checkBlockHash(nHeight, vchBlockHash) {
+	if nHeight < 0 {
 		return scriptError(ErrInvalidStackOperation,
 			"check blockhash at height failed")
 	}
 
+	// The referenced block is only verified against the main chain when
+	// the flag is set, since it requires a BlockHashProvider.
+	if vm.hasFlag(ScriptVerifyCheckBlockAtHeight) {
+		err := checkBlockHash(vm.blockHashes,
+			vm.chainParams.CheckBlockAtHeightSafeDepth, nHeight.Int32(),
+			vchBlockHash)
+		if err != nil {
+			return err
+		}
+	}
+
 	vm.dstack.PopByteArray()
 	vm.dstack.PopByteArray()
 	return nil
 }
 
-func checkBlockHash(nHeight scriptNum, vchBlockHash []byte) bool {
-	return true //return true just for now
-}
-
 // opcodeCheckSequenceVerify compares the top item on the data stack to the
 // LockTime field of the transaction containing the script signature
 // validating if the transaction outputs are spendable yet.  If flag
@@ -352,11 +352,18 @@ func shallowCopyTx(tx *wire.MsgTx) wire.MsgTx {
 	// for the copied inputs and outputs and point the final slice of
 	// pointers into the contiguous arrays.  This avoids a lot of small
 	// allocations.
+	//
+	// The cross-chain inputs and outputs of sidechain transactions are
+	// never modified while hashing, so they are shared with tx.
 	txCopy := wire.MsgTx{
-		Version:  tx.Version,
-		TxIn:     make([]*wire.TxIn, len(tx.TxIn)),
-		TxOut:    make([]*wire.TxOut, len(tx.TxOut)),
-		LockTime: tx.LockTime,
+		Version:              tx.Version,
+		TxIn:                 make([]*wire.TxIn, len(tx.TxIn)),
+		TxOut:                make([]*wire.TxOut, len(tx.TxOut)),
+		LockTime:             tx.LockTime,
+		TxCswIn:              tx.TxCswIn,
+		TxScCreationOut:      tx.TxScCreationOut,
+		TxForwardTransferOut: tx.TxForwardTransferOut,
+		TxBwtRequestOut:      tx.TxBwtRequestOut,
 	}
 	txIns := make([]wire.TxIn, len(tx.TxIn))
 	for i, oldTxIn := range tx.TxIn {
@@ -109,7 +109,7 @@ func sign(chainParams *chaincfg.Params, tx *wire.MsgTx, idx int,
 	}
 
 	switch class {
-	case PubKeyTy:
+	case PubKeyTy, PubKeyReplayOutTy:
 		// look up key for address
 		key, _, err := kdb.GetKey(addresses[0])
 		if err != nil {
@@ -262,6 +262,41 @@ func mergeMultiSig(tx *wire.MsgTx, idx int, addresses []zenutil.Address,
 	possibleSigs = extractSigs(sigPops, possibleSigs)
 	possibleSigs = extractSigs(prevPops, possibleSigs)
 
+	addrToSig := matchMultiSigSigs(tx, idx, addresses, pkPops, possibleSigs)
+
+	// Extra opcode to handle the extra arg consumed (due to previous bugs
+	// in the reference implementation).
+	builder := NewScriptBuilder().AddOp(OP_FALSE)
+	doneSigs := 0
+	// This assumes that addresses are in the same order as in the script.
+	for _, addr := range addresses {
+		sig, ok := addrToSig[addr.EncodeAddress()]
+		if !ok {
+			continue
+		}
+		builder.AddData(sig)
+		doneSigs++
+		if doneSigs == nRequired {
+			break
+		}
+	}
+
+	// padding for missing ones.
+	for i := doneSigs; i < nRequired; i++ {
+		builder.AddOp(OP_0)
+	}
+
+	script, _ := builder.Script()
+	return script
+}
+
+// matchMultiSigSigs matches the signatures in possibleSigs to the public keys
+// in addresses, which must be the public keys of the multisig script pkPops
+// spent by input idx of tx. It returns the first valid signature found for
+// each address, keyed by its encoded form.
+func matchMultiSigSigs(tx *wire.MsgTx, idx int, addresses []zenutil.Address,
+	pkPops []parsedOpcode, possibleSigs [][]byte) map[string][]byte {
+
 	// Now we need to match the signatures to pubkeys, the only real way to
 	// do that is to try to verify them all and match it to the pubkey
 	// that verifies it. we then can go through the addresses in order
@@ -313,30 +348,59 @@ sigLoop:
 		}
 	}
 
+	return addrToSig
+}
+
+// MultiSigSignatureScript returns the signature script spending input idx of
+// tx through the pay-to-script-hash multisig redeemScript, using the provided
+// signatures with their hash type appended.  Signatures are matched to the
+// public keys of redeemScript and pushed in the same order, as required by
+// OP_CHECKMULTISIG.  Signatures that don't verify are discarded.  The public
+// keys whose signature was included are returned alongside the script, which
+// only satisfies redeemScript if their number matches the required signatures.
+func MultiSigSignatureScript(chainParams *chaincfg.Params, tx *wire.MsgTx,
+	idx int, redeemScript []byte, sigs [][]byte) ([]byte, []*zenutil.AddressPubKey, error) {
+
+	class, addresses, nRequired, err := ExtractPkScriptAddrs(redeemScript,
+		chainParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	if class != MultiSigTy {
+		return nil, nil, fmt.Errorf("redeem script is %s, not %s", class,
+			MultiSigTy)
+	}
+
+	pkPops, err := parseScript(redeemScript)
+	if err != nil {
+		return nil, nil, err
+	}
+	addrToSig := matchMultiSigSigs(tx, idx, addresses, pkPops, sigs)
+
 	// Extra opcode to handle the extra arg consumed (due to previous bugs
 	// in the reference implementation).
 	builder := NewScriptBuilder().AddOp(OP_FALSE)
-	doneSigs := 0
-	// This assumes that addresses are in the same order as in the script.
+	signers := make([]*zenutil.AddressPubKey, 0, nRequired)
 	for _, addr := range addresses {
+		if len(signers) == nRequired {
+			break
+		}
+
 		sig, ok := addrToSig[addr.EncodeAddress()]
 		if !ok {
 			continue
 		}
 		builder.AddData(sig)
-		doneSigs++
-		if doneSigs == nRequired {
-			break
-		}
+		signers = append(signers, addr.(*zenutil.AddressPubKey))
 	}
+	builder.AddData(redeemScript)
 
-	// padding for missing ones.
-	for i := doneSigs; i < nRequired; i++ {
-		builder.AddOp(OP_0)
+	script, err := builder.Script()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	script, _ := builder.Script()
-	return script
+	return script, signers, nil
 }
 
 // KeyDB is an interface type provided to SignTxOutput, it encapsulates
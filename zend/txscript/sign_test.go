@@ -5,6 +5,7 @@
 package txscript
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"testing"
@@ -1714,3 +1715,111 @@ nexttest:
 		}
 	}
 }
+
+// TestMultiSigSignatureScript ensures signatures are matched to the keys of a
+// multisig redeem script and pushed in key order, and that the resulting
+// script spends both plain and replay protected pay-to-script-hash outputs.
+func TestMultiSigSignatureScript(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	keys := make([]*btcec.PrivateKey, 3)
+	pubKeys := make([]*zenutil.AddressPubKey, 3)
+	for i := range keys {
+		seed := make([]byte, 32)
+		seed[31] = byte(i + 1)
+		keys[i], _ = btcec.PrivKeyFromBytes(btcec.S256(), seed)
+
+		var err error
+		pubKeys[i], err = zenutil.NewAddressPubKey(
+			keys[i].PubKey().SerializeCompressed(), params)
+		if err != nil {
+			t.Fatalf("failed to make pubkey address: %v", err)
+		}
+	}
+
+	redeemScript, err := MultiSigScript(pubKeys, 2)
+	if err != nil {
+		t.Fatalf("failed to make redeem script: %v", err)
+	}
+	scriptAddr, err := zenutil.NewAddressScriptHash(redeemScript, params)
+	if err != nil {
+		t.Fatalf("failed to make script address: %v", err)
+	}
+	pkScript, err := PayToAddrScript(scriptAddr)
+	if err != nil {
+		t.Fatalf("failed to make pkscript: %v", err)
+	}
+	replayPkScript, err := PayToAddrReplayOutScript(scriptAddr,
+		make([]byte, 32), 100)
+	if err != nil {
+		t.Fatalf("failed to make replay pkscript: %v", err)
+	}
+
+	tx := &wire.MsgTx{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{0x01}},
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{Value: 1}},
+	}
+
+	sign := func(key *btcec.PrivateKey) []byte {
+		sig, err := RawTxInSignature(tx, 0, redeemScript, SigHashAll, key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return sig
+	}
+
+	// Signatures are provided out of order along with an invalid one.
+	sigs := [][]byte{sign(keys[2]), {0x30, 0x01}, sign(keys[0])}
+	sigScript, signers, err := MultiSigSignatureScript(params, tx, 0,
+		redeemScript, sigs)
+	if err != nil {
+		t.Fatalf("MultiSigSignatureScript failed: %v", err)
+	}
+	if len(signers) != 2 || signers[0].String() != pubKeys[0].String() ||
+		signers[1].String() != pubKeys[2].String() {
+		t.Fatalf("unexpected signers %v", signers)
+	}
+
+	pushes, err := PushedData(sigScript)
+	if err != nil {
+		t.Fatalf("failed to parse signature script: %v", err)
+	}
+	if len(pushes) != 4 || len(pushes[0]) != 0 ||
+		!bytes.Equal(pushes[1], sigs[2]) ||
+		!bytes.Equal(pushes[2], sigs[0]) ||
+		!bytes.Equal(pushes[3], redeemScript) {
+		t.Fatalf("unexpected signature script %x", sigScript)
+	}
+
+	for _, script := range [][]byte{pkScript, replayPkScript} {
+		if err := checkScripts("multisig", tx, 0, 1, sigScript,
+			script); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// A single signature does not satisfy the redeem script.
+	sigScript, signers, err = MultiSigSignatureScript(params, tx, 0,
+		redeemScript, [][]byte{sign(keys[1])})
+	if err != nil {
+		t.Fatalf("MultiSigSignatureScript failed: %v", err)
+	}
+	if len(signers) != 1 || signers[0].String() != pubKeys[1].String() {
+		t.Fatalf("unexpected signers %v", signers)
+	}
+	if err := checkScripts("incomplete multisig", tx, 0, 1, sigScript,
+		replayPkScript); err == nil {
+		t.Error("incomplete multisig signature script succeeded")
+	}
+
+	// Only multisig redeem scripts are supported.
+	_, _, err = MultiSigSignatureScript(params, tx, 0, pkScript, sigs)
+	if err == nil {
+		t.Error("MultiSigSignatureScript succeeded for a non multisig script")
+	}
+}
@@ -59,6 +59,7 @@ const (
 	MultiSigReplayTy                         // Multi signature with replay protection.
 	NullDataTy                               // Empty data-only (provably prunable).
 	WitnessV0PubKeyHashTy                    // Pay witness pubkey hash.
+	PubKeyReplayOutTy                        // Pay pubkey replay protection.
 )
 
 // scriptClassToName houses the human-readable strings which describe each
@@ -71,15 +72,17 @@ var scriptClassToName = []string{
 	ScriptHashTy:          "scripthash",
 	ScriptHashReplayOutTy: "scripthashreplayout",
 	MultiSigTy:            "multisig",
+	MultiSigReplayTy:      "multisigreplayout",
 	NullDataTy:            "nulldata",
 	WitnessV0PubKeyHashTy: "witness_v0_keyhash",
+	PubKeyReplayOutTy:     "pubkeyreplayout",
 }
 
 // String implements the Stringer interface by returning the name of
 // the enum script class. If the enum is invalid then "Invalid" will be
 // returned.
 func (t ScriptClass) String() string {
-	if int(t) > len(scriptClassToName) || int(t) < 0 {
+	if int(t) >= len(scriptClassToName) || int(t) < 0 {
 		return "Invalid"
 	}
 	return scriptClassToName[t]
@@ -94,6 +97,17 @@ func isPubkey(pops []parsedOpcode) bool {
 		pops[1].opcode.value == OP_CHECKSIG
 }
 
+// isPubkeyReplayOut returns true if the script passed is a pay-to-pubkey
+// transaction with replay protection, false otherwise.
+func isPubkeyReplayOut(pops []parsedOpcode) bool {
+	// Valid pubkeys are either 33 or 65 bytes.
+	return len(pops) == 5 &&
+		(len(pops[0].data) == 33 || len(pops[0].data) == 65) &&
+		pops[1].opcode.value == OP_CHECKSIG &&
+		pops[2].opcode.value == OP_DATA_32 &&
+		pops[4].opcode.value == OP_CHECKBLOCKATHEIGHT
+}
+
 // isPubkeyHash returns true if the script passed is a pay-to-pubkey-hash
 // transaction, false otherwise.
 func isPubkeyHash(pops []parsedOpcode) bool {
@@ -205,6 +219,8 @@ func isNullData(pops []parsedOpcode) bool {
 func typeOfScript(pops []parsedOpcode) ScriptClass {
 	if isPubkey(pops) {
 		return PubKeyTy
+	} else if isPubkeyReplayOut(pops) {
+		return PubKeyReplayOutTy
 	} else if isPubkeyHash(pops) {
 		return PubKeyHashTy
 	} else if isPubkeyHashReplayOut(pops) {
@@ -260,6 +276,9 @@ func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
 	case PubKeyTy:
 		return 1
 
+	case PubKeyReplayOutTy:
+		return 1
+
 	case PubKeyHashTy:
 		return 2
 
@@ -274,7 +293,7 @@ func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
 		// Not including script.  That is handled by the caller.
 		return 1
 
-	case MultiSigTy:
+	case MultiSigTy, MultiSigReplayTy:
 		// Standard multisig has a push a small number for the number
 		// of sigs and number of keys.  Check the first push instruction
 		// to see how many arguments are expected. typeOfScript already
@@ -609,7 +628,7 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 			addrs = append(addrs, addr)
 		}
 
-	case PubKeyTy:
+	case PubKeyTy, PubKeyReplayOutTy:
 		// A pay-to-pubkey script is of the form:
 		//  <pubkey> OP_CHECKSIG
 		// Therefore the pubkey is the first item on the stack.
@@ -651,6 +670,26 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 			}
 		}
 
+	case MultiSigReplayTy:
+		// A multi-signature script with replay protection is of the
+		// form:
+		//  <numsigs> <pubkey>... <numpubkeys> OP_CHECKMULTISIG
+		//  <blockhash> <blockheight> OP_CHECKBLOCKATHEIGHT
+		// Therefore the number of public keys is the 5th to last item
+		// on the stack.
+		requiredSigs = asSmallInt(pops[0].opcode)
+		numPubKeys := asSmallInt(pops[len(pops)-5].opcode)
+
+		// Extract the public keys while skipping any that are invalid.
+		addrs = make([]zenutil.Address, 0, numPubKeys)
+		for i := 0; i < numPubKeys; i++ {
+			addr, err := zenutil.NewAddressPubKey(pops[i+1].data,
+				chainParams)
+			if err == nil {
+				addrs = append(addrs, addr)
+			}
+		}
+
 	case NullDataTy:
 		// Null data transactions have no addresses or required
 		// signatures.
@@ -169,6 +169,20 @@ func TestExtractPkScriptAddrs(t *testing.T) {
 			reqSigs: 1,
 			class:   PubKeyTy,
 		},
+		{
+			name: "p2pk with replay protection",
+			script: hexToBytes("2102192d74d0cb94344c9569c2e779015" +
+				"73d8d7903c3ebec3a957724895dca52c6b4ac20f8" +
+				"16820f24150b5647e662bd9ae393f82f2c6b56ba" +
+				"6e48983cebd720b3ae86070164b4"),
+			addrs: []zenutil.Address{
+				newAddressPubKey(hexToBytes("02192d74d0cb9434" +
+					"4c9569c2e77901573d8d7903c3ebec3a9577" +
+					"24895dca52c6b4")),
+			},
+			reqSigs: 1,
+			class:   PubKeyReplayOutTy,
+		},
 		{
 			name: "standard p2pkh",
 			script: hexToBytes("76a914ad06dd6ddee55cbca9a9e3713bd" +
@@ -823,6 +837,22 @@ var scriptClassTests = []struct {
 			"9ae88 EQUAL",
 		class: ScriptHashTy,
 	},
+	{
+		name: "Pay Pubkey with replay protection",
+		script: "DATA_33 0x0232abdc893e7f0631364d7fd01cb33d24da45329a0" +
+			"0357b3a7886211ab414d55a CHECKSIG DATA_32 0xf816820f24" +
+			"150b5647e662bd9ae393f82f2c6b56ba6e48983cebd720b3ae8607 " +
+			"100 CHECKBLOCKATHEIGHT",
+		class: PubKeyReplayOutTy,
+	},
+	{
+		name: "multisig with replay protection",
+		script: "1 DATA_33 0x0232abdc893e7f0631364d7fd01cb33d24da4" +
+			"5329a00357b3a7886211ab414d55a 1 CHECKMULTISIG DATA_32 " +
+			"0xf816820f24150b5647e662bd9ae393f82f2c6b56ba6e48983ce" +
+			"bd720b3ae8607 100 CHECKBLOCKATHEIGHT",
+		class: MultiSigReplayTy,
+	},
 
 	{
 		// Nulldata with no data at all.
@@ -1004,11 +1034,21 @@ func TestStringifyClass(t *testing.T) {
 			class:    MultiSigTy,
 			stringed: "multisig",
 		},
+		{
+			name:     "multisigreplayty",
+			class:    MultiSigReplayTy,
+			stringed: "multisigreplayout",
+		},
 		{
 			name:     "nulldataty",
 			class:    NullDataTy,
 			stringed: "nulldata",
 		},
+		{
+			name:     "pubkeyreplayout",
+			class:    PubKeyReplayOutTy,
+			stringed: "pubkeyreplayout",
+		},
 		{
 			name:     "broken",
 			class:    ScriptClass(255),
@@ -10,12 +10,18 @@ import (
 	"time"
 
 	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+	"github.com/HorizenOfficial/rosetta-zen/zend/equihash"
 )
 
+// MaxEquihashSolutionSize is the size of the largest supported Equihash
+// solution, the one of the (200, 9) parameters.
+const MaxEquihashSolutionSize = 1344
+
 // MaxBlockHeaderPayload is the maximum number of bytes a block header can be.
-// Version 4 bytes + Timestamp 4 bytes + Bits 4 bytes + Nonce 4 bytes +
-// PrevBlock and MerkleRoot hashes.
-const MaxBlockHeaderPayload = 16 + (chainhash.HashSize * 2)
+// Version 4 bytes + Timestamp 4 bytes + Bits 4 bytes + PrevBlock, MerkleRoot,
+// ScTxsCommitment and Nonce hashes + Solution.
+const MaxBlockHeaderPayload = 12 + (chainhash.HashSize * 4) +
+	MaxVarIntPayload + MaxEquihashSolutionSize
 
 // BlockHeader defines information about a block and is used in the bitcoin
 // block (MsgBlock) and headers (MsgHeaders) messages.
@@ -29,6 +35,10 @@ type BlockHeader struct {
 	// Merkle tree reference to hash of all transactions for the block.
 	MerkleRoot chainhash.Hash
 
+	// Commitment to the sidechain transactions and certificates of the
+	// block.  This field was reserved and all zero before sidechains.
+	ScTxsCommitment chainhash.Hash
+
 	// Time the block was created.  This is, unfortunately, encoded as a
 	// uint32 on the wire and therefore is limited to 2106.
 	Timestamp time.Time
@@ -37,12 +47,15 @@ type BlockHeader struct {
 	Bits uint32
 
 	// Nonce used to generate the block.
-	Nonce uint32
+	Nonce chainhash.Hash
+
+	// Equihash solution of the block.
+	Solution []byte
 }
 
-// blockHeaderLen is a constant that represents the number of bytes for a block
-// header.
-const blockHeaderLen = 80
+// equihashInputLen is a constant that represents the number of bytes of the
+// block header fields preceding the nonce.
+const equihashInputLen = 12 + (chainhash.HashSize * 3)
 
 // BlockHash computes the block identifier hash for the given block header.
 func (h *BlockHeader) BlockHash() chainhash.Hash {
@@ -50,12 +63,36 @@ func (h *BlockHeader) BlockHash() chainhash.Hash {
 	// transactions.  Ignore the error returns since there is no way the
 	// encode could fail except being out of memory which would cause a
 	// run-time panic.
-	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
+	buf := bytes.NewBuffer(make([]byte, 0, h.SerializeSize()))
 	_ = writeBlockHeader(buf, 0, h)
 
 	return chainhash.DoubleHashH(buf.Bytes())
 }
 
+// EquihashInput returns the serialized header fields preceding the nonce,
+// which together with the nonce are the input of the Equihash solution.
+func (h *BlockHeader) EquihashInput() []byte {
+	// Ignore the error returns since there is no way the encode could
+	// fail except being out of memory which would cause a run-time panic.
+	buf := bytes.NewBuffer(make([]byte, 0, equihashInputLen))
+	_ = writeEquihashInput(buf, h)
+
+	return buf.Bytes()
+}
+
+// VerifySolution checks the Equihash solution of the block header against the
+// provided parameters.  It does not check the block hash against the target.
+func (h *BlockHeader) VerifySolution(params equihash.Params) error {
+	return equihash.Verify(params, h.EquihashInput(), h.Nonce[:], h.Solution)
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// block header.
+func (h *BlockHeader) SerializeSize() int {
+	return equihashInputLen + chainhash.HashSize +
+		VarIntSerializeSize(uint64(len(h.Solution))) + len(h.Solution)
+}
+
 // BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
 // This is part of the Message interface implementation.
 // See Deserialize for decoding block headers stored to disk, such as in a
@@ -93,10 +130,10 @@ func (h *BlockHeader) Serialize(w io.Writer) error {
 }
 
 // NewBlockHeader returns a new BlockHeader using the provided version, previous
-// block hash, merkle root hash, difficulty bits, and nonce used to generate the
-// block with defaults for the remaining fields.
+// block hash, merkle root hash, difficulty bits, nonce and Equihash solution
+// used to generate the block with defaults for the remaining fields.
 func NewBlockHeader(version int32, prevHash, merkleRootHash *chainhash.Hash,
-	bits uint32, nonce uint32) *BlockHeader {
+	bits uint32, nonce *chainhash.Hash, solution []byte) *BlockHeader {
 
 	// Limit the timestamp to one second precision since the protocol
 	// doesn't support better.
@@ -106,23 +143,47 @@ func NewBlockHeader(version int32, prevHash, merkleRootHash *chainhash.Hash,
 		MerkleRoot: *merkleRootHash,
 		Timestamp:  time.Unix(time.Now().Unix(), 0),
 		Bits:       bits,
-		Nonce:      nonce,
+		Nonce:      *nonce,
+		Solution:   solution,
 	}
 }
 
-// readBlockHeader reads a bitcoin block header from r.  See Deserialize for
+// readBlockHeader reads a zen block header from r.  See Deserialize for
 // decoding block headers stored to disk, such as in a database, as opposed to
 // decoding from the wire.
 func readBlockHeader(r io.Reader, pver uint32, bh *BlockHeader) error {
-	return readElements(r, &bh.Version, &bh.PrevBlock, &bh.MerkleRoot,
-		(*uint32Time)(&bh.Timestamp), &bh.Bits, &bh.Nonce)
+	err := readElements(r, &bh.Version, &bh.PrevBlock, &bh.MerkleRoot,
+		&bh.ScTxsCommitment, (*uint32Time)(&bh.Timestamp), &bh.Bits,
+		&bh.Nonce)
+	if err != nil {
+		return err
+	}
+
+	bh.Solution, err = ReadVarBytes(r, pver, MaxEquihashSolutionSize,
+		"equihash solution")
+	return err
 }
 
-// writeBlockHeader writes a bitcoin block header to w.  See Serialize for
+// writeBlockHeader writes a zen block header to w.  See Serialize for
 // encoding block headers to be stored to disk, such as in a database, as
 // opposed to encoding for the wire.
 func writeBlockHeader(w io.Writer, pver uint32, bh *BlockHeader) error {
+	err := writeEquihashInput(w, bh)
+	if err != nil {
+		return err
+	}
+
+	err = writeElement(w, &bh.Nonce)
+	if err != nil {
+		return err
+	}
+
+	return WriteVarBytes(w, pver, bh.Solution)
+}
+
+// writeEquihashInput writes the block header fields preceding the nonce to w.
+func writeEquihashInput(w io.Writer, bh *BlockHeader) error {
 	sec := uint32(bh.Timestamp.Unix())
 	return writeElements(w, bh.Version, &bh.PrevBlock, &bh.MerkleRoot,
-		sec, bh.Bits, bh.Nonce)
+		&bh.ScTxsCommitment, sec, bh.Bits)
 }
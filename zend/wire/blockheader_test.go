@@ -6,10 +6,18 @@ package wire
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+	"github.com/HorizenOfficial/rosetta-zen/zend/equihash"
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -19,12 +27,14 @@ func TestBlockHeader(t *testing.T) {
 	if err != nil {
 		t.Errorf("RandomUint64: Error generating nonce: %v", err)
 	}
-	nonce := uint32(nonce64)
+	var nonce chainhash.Hash
+	binary.LittleEndian.PutUint64(nonce[:], nonce64)
 
 	hash := mainNetGenesisHash
 	merkleHash := mainNetGenesisMerkleRoot
 	bits := uint32(0x1d00ffff)
-	bh := NewBlockHeader(1, &hash, &merkleHash, bits, nonce)
+	solution := []byte{0x01, 0x02, 0x03, 0x04}
+	bh := NewBlockHeader(1, &hash, &merkleHash, bits, &nonce, solution)
 
 	// Ensure we get the same data back out.
 	if !bh.PrevBlock.IsEqual(&hash) {
@@ -43,12 +53,16 @@ func TestBlockHeader(t *testing.T) {
 		t.Errorf("NewBlockHeader: wrong nonce - got %v, want %v",
 			bh.Nonce, nonce)
 	}
+	if !bytes.Equal(bh.Solution, solution) {
+		t.Errorf("NewBlockHeader: wrong solution - got %x, want %x",
+			bh.Solution, solution)
+	}
 }
 
 // TestBlockHeaderWire tests the BlockHeader wire encode and decode for various
 // protocol versions.
 func TestBlockHeaderWire(t *testing.T) {
-	nonce := uint32(123123) // 0x1e0f3
+	nonce := chainhash.Hash{0xf3, 0xe0, 0x01} // 0x1e0f3
 	pver := uint32(70001)
 
 	// baseBlockHdr is used in the various tests as a baseline BlockHeader.
@@ -60,6 +74,7 @@ func TestBlockHeaderWire(t *testing.T) {
 		Timestamp:  time.Unix(0x495fab29, 0), // 2009-01-03 12:15:05 -0600 CST
 		Bits:       bits,
 		Nonce:      nonce,
+		Solution:   []byte{0x01, 0x02, 0x03, 0x04},
 	}
 
 	// baseBlockHdrEncoded is the wire encoded bytes of baseBlockHdr.
@@ -73,9 +88,17 @@ func TestBlockHeaderWire(t *testing.T) {
 		0x7a, 0xc7, 0x2c, 0x3e, 0x67, 0x76, 0x8f, 0x61,
 		0x7f, 0xc8, 0x1b, 0xc3, 0x88, 0x8a, 0x51, 0x32,
 		0x3a, 0x9f, 0xb8, 0xaa, 0x4b, 0x1e, 0x5e, 0x4a, // MerkleRoot
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 		0x29, 0xab, 0x5f, 0x49, // Timestamp
 		0xff, 0xff, 0x00, 0x1d, // Bits
-		0xf3, 0xe0, 0x01, 0x00, // Nonce
+		0xf3, 0xe0, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 	}
 
 	tests := []struct {
@@ -188,7 +211,7 @@ func TestBlockHeaderWire(t *testing.T) {
 
 // TestBlockHeaderSerialize tests BlockHeader serialize and deserialize.
 func TestBlockHeaderSerialize(t *testing.T) {
-	nonce := uint32(123123) // 0x1e0f3
+	nonce := chainhash.Hash{0xf3, 0xe0, 0x01} // 0x1e0f3
 
 	// baseBlockHdr is used in the various tests as a baseline BlockHeader.
 	bits := uint32(0x1d00ffff)
@@ -199,6 +222,7 @@ func TestBlockHeaderSerialize(t *testing.T) {
 		Timestamp:  time.Unix(0x495fab29, 0), // 2009-01-03 12:15:05 -0600 CST
 		Bits:       bits,
 		Nonce:      nonce,
+		Solution:   []byte{0x01, 0x02, 0x03, 0x04},
 	}
 
 	// baseBlockHdrEncoded is the wire encoded bytes of baseBlockHdr.
@@ -212,9 +236,17 @@ func TestBlockHeaderSerialize(t *testing.T) {
 		0x7a, 0xc7, 0x2c, 0x3e, 0x67, 0x76, 0x8f, 0x61,
 		0x7f, 0xc8, 0x1b, 0xc3, 0x88, 0x8a, 0x51, 0x32,
 		0x3a, 0x9f, 0xb8, 0xaa, 0x4b, 0x1e, 0x5e, 0x4a, // MerkleRoot
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 		0x29, 0xab, 0x5f, 0x49, // Timestamp
 		0xff, 0xff, 0x00, 0x1d, // Bits
-		0xf3, 0xe0, 0x01, 0x00, // Nonce
+		0xf3, 0xe0, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 	}
 
 	tests := []struct {
@@ -259,3 +291,122 @@ func TestBlockHeaderSerialize(t *testing.T) {
 		}
 	}
 }
+
+// regtestBlockHdrEncoded is a block header mined with the (48, 5) Equihash
+// parameters of the regression test network.
+const regtestBlockHdrEncoded = "04000000" + // Version 4
+	"b9c4f2c332bd1badb52b8a0a2a0a3e8db5e5566f0f8e6d5eab212ae7b13b1d0a" + // PrevBlock
+	"8e00984732c5983c9cd20b8befc34b77b3c01aa26bdb0c3bed67822acf907a3a" + // MerkleRoot
+	"66d4012f66d4758b33d22889956ed49d95c112d891637f53e6f000c46ce9650d" + // ScTxsCommitment
+	"6bc46d61" + // Timestamp
+	"0f0f0f20" + // Bits
+	"0500000000000000000000000000000000000000000000000000000000000000" + // Nonce
+	"24" + "04fb2f1ce482e17cd907a05638e1055998e71a4a0cff53bbd9d31743385f5a57575ecb93" // Solution
+
+// TestBlockHeaderSolution tests hashing a block header and verifying its
+// Equihash solution.
+func TestBlockHeaderSolution(t *testing.T) {
+	encoded, err := hex.DecodeString(regtestBlockHdrEncoded)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+
+	var bh BlockHeader
+	if err := bh.Deserialize(bytes.NewReader(encoded)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+
+	if size := bh.SerializeSize(); size != len(encoded) {
+		t.Errorf("SerializeSize: got %d, want %d", size, len(encoded))
+	}
+
+	wantHash := "598bc6df8f18b6c8eaf71468cff69a4444140b880c73b2f611918d9ed935f321"
+	if hash := bh.BlockHash(); hash.String() != wantHash {
+		t.Errorf("BlockHash: got %s, want %s", hash, wantHash)
+	}
+
+	if input := bh.EquihashInput(); !bytes.Equal(input, encoded[:equihashInputLen]) {
+		t.Errorf("EquihashInput: got %x, want %x", input, encoded[:equihashInputLen])
+	}
+
+	if err := bh.VerifySolution(equihash.Params48_5); err != nil {
+		t.Errorf("VerifySolution: %v", err)
+	}
+
+	// Changing any field covered by the solution must invalidate it.
+	tampered := bh
+	tampered.ScTxsCommitment[0] ^= 0x01
+	err = tampered.VerifySolution(equihash.Params48_5)
+	if !errors.Is(err, equihash.ErrInvalidSolution) {
+		t.Errorf("VerifySolution: got %v, want %v", err,
+			equihash.ErrInvalidSolution)
+	}
+
+	tampered = bh
+	tampered.Nonce[0]++
+	err = tampered.VerifySolution(equihash.Params48_5)
+	if !errors.Is(err, equihash.ErrInvalidSolution) {
+		t.Errorf("VerifySolution: got %v, want %v", err,
+			equihash.ErrInvalidSolution)
+	}
+
+	err = bh.VerifySolution(equihash.Params200_9)
+	if !errors.Is(err, equihash.ErrInvalidSolutionSize) {
+		t.Errorf("VerifySolution: got %v, want %v", err,
+			equihash.ErrInvalidSolutionSize)
+	}
+}
+
+// TestBlockHeaderMainnetGenesis tests decoding, hashing and verifying the
+// Equihash solution of the genesis block header of the main network, read
+// from testdata/header_0007104c.hex.
+func TestBlockHeaderMainnetGenesis(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "header_0007104c.hex"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	encoded, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+
+	var bh BlockHeader
+	if err := bh.Deserialize(bytes.NewReader(encoded)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+
+	wantHash := "0007104ccda289427919efc39dc9e4d499804b7bebc22df55f8b834301260602"
+	if hash := bh.BlockHash(); hash.String() != wantHash {
+		t.Errorf("BlockHash: got %s, want %s", hash, wantHash)
+	}
+
+	wantMerkleRoot := "19612bcf00ea7611d315d7f43554fa983c6e8c30cba17e52c679e0e80abf7d42"
+	if bh.Version != 4 || bh.MerkleRoot.String() != wantMerkleRoot ||
+		bh.Timestamp.Unix() != 1478403829 || bh.Bits != 0x1f07ffff ||
+		len(bh.Solution) != equihash.Params200_9.SolutionSize() {
+		t.Errorf("unexpected genesis header %s", spew.Sdump(bh))
+	}
+
+	var buf bytes.Buffer
+	if err := bh.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Errorf("Serialize: got %x, want %x", buf.Bytes(), encoded)
+	}
+
+	if err := bh.VerifySolution(equihash.Params200_9); err != nil {
+		t.Errorf("VerifySolution: %v", err)
+	}
+
+	// Flipping a single bit of the solution must invalidate it.
+	tampered := bh
+	tampered.Solution = append([]byte{}, bh.Solution...)
+	tampered.Solution[100] ^= 0x01
+	err = tampered.VerifySolution(equihash.Params200_9)
+	if !errors.Is(err, equihash.ErrInvalidSolution) {
+		t.Errorf("VerifySolution: got %v, want %v", err,
+			equihash.ErrInvalidSolution)
+	}
+}
@@ -30,6 +30,10 @@ const MaxBlockPayload = 4000000
 // possibly fit into a block.
 const maxTxPerBlock = (MaxBlockPayload / minTxPayload) + 1
 
+// BlockVersionSidechain is the block version introduced by sidechains.  Blocks
+// of this version carry certificates after their transactions.
+const BlockVersionSidechain = 3
+
 // TxLoc holds locator data for the offset and length of where a transaction is
 // located within a MsgBlock data buffer.
 type TxLoc struct {
@@ -43,6 +47,7 @@ type TxLoc struct {
 type MsgBlock struct {
 	Header       BlockHeader
 	Transactions []*MsgTx
+	Certificates []*MsgCertificate
 }
 
 // AddTransaction adds a transaction to the message.
@@ -91,6 +96,30 @@ func (msg *MsgBlock) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) er
 		msg.Transactions = append(msg.Transactions, &tx)
 	}
 
+	return msg.readCertificates(r, pver)
+}
+
+// readCertificates reads the certificates of sidechain version blocks.
+func (msg *MsgBlock) readCertificates(r io.Reader, pver uint32) error {
+	msg.Certificates = nil
+	if msg.Header.Version != BlockVersionSidechain {
+		return nil
+	}
+
+	count, err := readCount(r, pver, minTxPayload, "block certificates")
+	if err != nil {
+		return err
+	}
+
+	msg.Certificates = make([]*MsgCertificate, 0, count)
+	for i := uint64(0); i < count; i++ {
+		cert := MsgCertificate{}
+		if err := cert.BtcDecode(r, pver, BaseEncoding); err != nil {
+			return err
+		}
+		msg.Certificates = append(msg.Certificates, &cert)
+	}
+
 	return nil
 }
 
@@ -166,6 +195,10 @@ func (msg *MsgBlock) DeserializeTxLoc(r *bytes.Buffer) ([]TxLoc, error) {
 		txLocs[i].TxLen = (fullLen - r.Len()) - txLocs[i].TxStart
 	}
 
+	if err := msg.readCertificates(r, 0); err != nil {
+		return nil, err
+	}
+
 	return txLocs, nil
 }
 
@@ -191,6 +224,22 @@ func (msg *MsgBlock) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) er
 		}
 	}
 
+	if msg.Header.Version != BlockVersionSidechain {
+		return nil
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.Certificates)))
+	if err != nil {
+		return err
+	}
+
+	for _, cert := range msg.Certificates {
+		err = cert.BtcEncode(w, pver, enc)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -228,13 +277,14 @@ func (msg *MsgBlock) SerializeNoWitness(w io.Writer) error {
 func (msg *MsgBlock) SerializeSize() int {
 	// Block header bytes + Serialized varint size for the number of
 	// transactions.
-	n := blockHeaderLen + VarIntSerializeSize(uint64(len(msg.Transactions)))
+	n := msg.Header.SerializeSize() +
+		VarIntSerializeSize(uint64(len(msg.Transactions)))
 
 	for _, tx := range msg.Transactions {
 		n += tx.SerializeSize()
 	}
 
-	return n
+	return n + msg.certificatesSerializeSize()
 }
 
 // SerializeSizeStripped returns the number of bytes it would take to serialize
@@ -242,12 +292,28 @@ func (msg *MsgBlock) SerializeSize() int {
 func (msg *MsgBlock) SerializeSizeStripped() int {
 	// Block header bytes + Serialized varint size for the number of
 	// transactions.
-	n := blockHeaderLen + VarIntSerializeSize(uint64(len(msg.Transactions)))
+	n := msg.Header.SerializeSize() +
+		VarIntSerializeSize(uint64(len(msg.Transactions)))
 
 	for _, tx := range msg.Transactions {
 		n += tx.SerializeSizeStripped()
 	}
 
+	return n + msg.certificatesSerializeSize()
+}
+
+// certificatesSerializeSize returns the number of bytes it would take to
+// serialize the certificates of the block.
+func (msg *MsgBlock) certificatesSerializeSize() int {
+	if msg.Header.Version != BlockVersionSidechain {
+		return 0
+	}
+
+	n := VarIntSerializeSize(uint64(len(msg.Certificates)))
+	for _, cert := range msg.Certificates {
+		n += cert.SerializeSize()
+	}
+
 	return n
 }
 
@@ -260,7 +326,7 @@ func (msg *MsgBlock) Command() string {
 // MaxPayloadLength returns the maximum length the payload can be for the
 // receiver.  This is part of the Message interface implementation.
 func (msg *MsgBlock) MaxPayloadLength(pver uint32) uint32 {
-	// Block header at 80 bytes + transaction count + max transactions
+	// Block header + transaction count + max transactions
 	// which can vary up to the MaxBlockPayload (including the block header
 	// and transaction count).
 	return MaxBlockPayload
@@ -6,8 +6,12 @@ package wire
 
 import (
 	"bytes"
+	"encoding/hex"
 	"io"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,8 +27,9 @@ func TestBlock(t *testing.T) {
 	prevHash := &blockOne.Header.PrevBlock
 	merkleHash := &blockOne.Header.MerkleRoot
 	bits := blockOne.Header.Bits
-	nonce := blockOne.Header.Nonce
-	bh := NewBlockHeader(1, prevHash, merkleHash, bits, nonce)
+	nonce := &blockOne.Header.Nonce
+	solution := blockOne.Header.Solution
+	bh := NewBlockHeader(1, prevHash, merkleHash, bits, nonce, solution)
 
 	// Ensure the command is expected value.
 	wantCmd := "block"
@@ -92,7 +97,7 @@ func TestBlockTxHashes(t *testing.T) {
 // TestBlockHash tests the ability to generate the hash of a block accurately.
 func TestBlockHash(t *testing.T) {
 	// Block 1 hash.
-	hashStr := "839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048"
+	hashStr := "298b4e82ff404d3547443246ccbe18f082cdab2b708d49d7c45dc5a990870671"
 	wantHash, err := chainhash.NewHashFromStr(hashStr)
 	if err != nil {
 		t.Errorf("NewHashFromStr: %v", err)
@@ -223,16 +228,22 @@ func TestBlockWireErrors(t *testing.T) {
 		{&blockOne, blockOneBytes, pver, BaseEncoding, 4, io.ErrShortWrite, io.EOF},
 		// Force error in merkle root.
 		{&blockOne, blockOneBytes, pver, BaseEncoding, 36, io.ErrShortWrite, io.EOF},
-		// Force error in timestamp.
+		// Force error in sidechain transactions commitment.
 		{&blockOne, blockOneBytes, pver, BaseEncoding, 68, io.ErrShortWrite, io.EOF},
+		// Force error in timestamp.
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 100, io.ErrShortWrite, io.EOF},
 		// Force error in difficulty bits.
-		{&blockOne, blockOneBytes, pver, BaseEncoding, 72, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 104, io.ErrShortWrite, io.EOF},
 		// Force error in header nonce.
-		{&blockOne, blockOneBytes, pver, BaseEncoding, 76, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 108, io.ErrShortWrite, io.EOF},
+		// Force error in header solution length.
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 140, io.ErrShortWrite, io.EOF},
+		// Force error in header solution.
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 141, io.ErrShortWrite, io.EOF},
 		// Force error in transaction count.
-		{&blockOne, blockOneBytes, pver, BaseEncoding, 80, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 145, io.ErrShortWrite, io.EOF},
 		// Force error in transactions.
-		{&blockOne, blockOneBytes, pver, BaseEncoding, 81, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, pver, BaseEncoding, 146, io.ErrShortWrite, io.EOF},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -341,16 +352,22 @@ func TestBlockSerializeErrors(t *testing.T) {
 		{&blockOne, blockOneBytes, 4, io.ErrShortWrite, io.EOF},
 		// Force error in merkle root.
 		{&blockOne, blockOneBytes, 36, io.ErrShortWrite, io.EOF},
-		// Force error in timestamp.
+		// Force error in sidechain transactions commitment.
 		{&blockOne, blockOneBytes, 68, io.ErrShortWrite, io.EOF},
+		// Force error in timestamp.
+		{&blockOne, blockOneBytes, 100, io.ErrShortWrite, io.EOF},
 		// Force error in difficulty bits.
-		{&blockOne, blockOneBytes, 72, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, 104, io.ErrShortWrite, io.EOF},
 		// Force error in header nonce.
-		{&blockOne, blockOneBytes, 76, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, 108, io.ErrShortWrite, io.EOF},
+		// Force error in header solution length.
+		{&blockOne, blockOneBytes, 140, io.ErrShortWrite, io.EOF},
+		// Force error in header solution.
+		{&blockOne, blockOneBytes, 141, io.ErrShortWrite, io.EOF},
 		// Force error in transaction count.
-		{&blockOne, blockOneBytes, 80, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, 145, io.ErrShortWrite, io.EOF},
 		// Force error in transactions.
-		{&blockOne, blockOneBytes, 81, io.ErrShortWrite, io.EOF},
+		{&blockOne, blockOneBytes, 146, io.ErrShortWrite, io.EOF},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -413,9 +430,17 @@ func TestBlockOverflowErrors(t *testing.T) {
 				0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
 				0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 				0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 				0x61, 0xbc, 0x66, 0x49, // Timestamp
 				0xff, 0xff, 0x00, 0x1d, // Bits
-				0x01, 0xe3, 0x62, 0x99, // Nonce
+				0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+				0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
 				0xff, // TxnCount
 			}, pver, BaseEncoding, &MessageError{},
@@ -454,6 +479,124 @@ func TestBlockOverflowErrors(t *testing.T) {
 	}
 }
 
+// TestBlockCertificates tests encoding and decoding the certificates carried
+// by sidechain version blocks.
+func TestBlockCertificates(t *testing.T) {
+	var cert MsgCertificate
+	err := cert.Deserialize(bytes.NewReader(certificateEncoded(t)))
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+
+	block := blockOne
+	block.Header.Version = BlockVersionSidechain
+	block.Certificates = []*MsgCertificate{&cert}
+
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if buf.Len() != block.SerializeSize() {
+		t.Errorf("SerializeSize: got %d, want %d", block.SerializeSize(),
+			buf.Len())
+	}
+
+	// The certificates follow the transactions of the block.
+	certOffset := len(blockOneBytes)
+	if got := buf.Bytes()[certOffset]; got != 0x01 {
+		t.Errorf("unexpected certificate count %d", got)
+	}
+
+	var decoded MsgBlock
+	txLocs, err := decoded.DeserializeTxLoc(bytes.NewBuffer(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("DeserializeTxLoc: %v", err)
+	}
+	if !reflect.DeepEqual(txLocs, blockOneTxLocs) {
+		t.Errorf("DeserializeTxLoc: got %v, want %v", txLocs, blockOneTxLocs)
+	}
+	if !reflect.DeepEqual(&decoded, &block) {
+		t.Errorf("DeserializeTxLoc: got %s, want %s", spew.Sdump(&decoded),
+			spew.Sdump(&block))
+	}
+
+	wantHash := "815c88e2bb7a0b083c74bf9643f94db252704f475290c58f6cb123e8793f5376"
+	if len(decoded.Certificates) != 1 ||
+		decoded.Certificates[0].CertHash().String() != wantHash {
+		t.Errorf("unexpected certificates %v", spew.Sdump(decoded.Certificates))
+	}
+
+	// Blocks of other versions do not carry certificates.
+	decoded = MsgBlock{}
+	if err := decoded.Deserialize(bytes.NewReader(blockOneBytes)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if decoded.Certificates != nil {
+		t.Errorf("unexpected certificates in version %d block",
+			decoded.Header.Version)
+	}
+
+	// A truncated certificate list must fail to decode.
+	truncated := buf.Bytes()[:buf.Len()-1]
+	if err := decoded.Deserialize(bytes.NewReader(truncated)); err == nil {
+		t.Errorf("Deserialize: expected error decoding truncated block")
+	}
+}
+
+// TestBlockMainnetGenesis tests decoding, hashing and encoding the genesis
+// block of the main network, read from testdata/block_0007104c.hex.
+func TestBlockMainnetGenesis(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "block_0007104c.hex"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	encoded, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+
+	var block MsgBlock
+	txLocs, err := block.DeserializeTxLoc(bytes.NewBuffer(encoded))
+	if err != nil {
+		t.Fatalf("DeserializeTxLoc: %v", err)
+	}
+
+	wantHash := "0007104ccda289427919efc39dc9e4d499804b7bebc22df55f8b834301260602"
+	if hash := block.BlockHash(); hash.String() != wantHash {
+		t.Errorf("BlockHash: got %s, want %s", hash, wantHash)
+	}
+
+	// The coinbase is the only transaction, so its hash is the
+	// merkle root of the block.
+	wantTxHash := "19612bcf00ea7611d315d7f43554fa983c6e8c30cba17e52c679e0e80abf7d42"
+	if len(block.Transactions) != 1 ||
+		block.Transactions[0].TxHash().String() != wantTxHash ||
+		block.Header.MerkleRoot.String() != wantTxHash {
+		t.Errorf("unexpected transactions %s", spew.Sdump(block.Transactions))
+	}
+	if block.Certificates != nil {
+		t.Errorf("unexpected certificates in version %d block",
+			block.Header.Version)
+	}
+
+	wantTxLocs := []TxLoc{{TxStart: 1488, TxLen: 207}}
+	if !reflect.DeepEqual(txLocs, wantTxLocs) {
+		t.Errorf("DeserializeTxLoc: got %v, want %v", txLocs, wantTxLocs)
+	}
+
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Errorf("Serialize: got %x, want %x", buf.Bytes(), encoded)
+	}
+	if size := block.SerializeSize(); size != len(encoded) {
+		t.Errorf("SerializeSize: got %d, want %d", size, len(encoded))
+	}
+}
+
 // TestBlockSerializeSize performs tests to ensure the serialize size for
 // various blocks is accurate.
 func TestBlockSerializeSize(t *testing.T) {
@@ -465,7 +608,7 @@ func TestBlockSerializeSize(t *testing.T) {
 		size int       // Expected serialized size
 	}{
 		// Block with no transactions.
-		{noTxBlock, 81},
+		{noTxBlock, 146},
 
 		// First block in the mainnet block chain.
 		{&blockOne, len(blockOneBytes)},
@@ -499,9 +642,10 @@ var blockOne = MsgBlock{
 			0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e,
 		}),
 
-		Timestamp: time.Unix(0x4966bc61, 0), // 2009-01-08 20:54:25 -0600 CST
-		Bits:      0x1d00ffff,               // 486604799
-		Nonce:     0x9962e301,               // 2573394689
+		Timestamp: time.Unix(0x4966bc61, 0),               // 2009-01-08 20:54:25 -0600 CST
+		Bits:      0x1d00ffff,                             // 486604799
+		Nonce:     chainhash.Hash{0x01, 0xe3, 0x62, 0x99}, // 2573394689
+		Solution:  []byte{0x01, 0x02, 0x03, 0x04},
 	},
 	Transactions: []*MsgTx{
 		{
@@ -552,9 +696,17 @@ var blockOneBytes = []byte{
 	0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
 	0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 	0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 	0x61, 0xbc, 0x66, 0x49, // Timestamp
 	0xff, 0xff, 0x00, 0x1d, // Bits
-	0x01, 0xe3, 0x62, 0x99, // Nonce
+	0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+	0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 	0x01,                   // TxnCount
 	0x01, 0x00, 0x00, 0x00, // Version
 	0x01, // Varint for number of transaction inputs
@@ -585,5 +737,5 @@ var blockOneBytes = []byte{
 
 // Transaction location information for block one transactions.
 var blockOneTxLocs = []TxLoc{
-	{TxStart: 81, TxLen: 134},
+	{TxStart: 146, TxLen: 134},
 }
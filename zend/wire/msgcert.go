@@ -0,0 +1,353 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+)
+
+const (
+	// CertificateVersion is the version of sidechain certificates.
+	CertificateVersion = -5
+
+	// backwardTransferOutSize is the size of a backward transfer output.
+	// Value 8 bytes + PubKeyHash 20 bytes.
+	backwardTransferOutSize = 8 + pubKeyHashSize
+)
+
+// BackwardTransferOut defines a backward transfer output of a certificate,
+// paying Value to the P2PKH address identified by PubKeyHash on the
+// mainchain.
+type BackwardTransferOut struct {
+	Value      int64
+	PubKeyHash [pubKeyHashSize]byte
+}
+
+// MsgCertificate represents a sidechain certificate. Certificates are
+// submitted once per withdrawal epoch and carry the backward transfers from
+// the sidechain to the mainchain, along with regular inputs and outputs used
+// to pay the certificate fee.
+type MsgCertificate struct {
+	Version                               int32
+	ScID                                  chainhash.Hash
+	EpochNumber                           int32
+	Quality                               int64
+	EndEpochCumScTxCommTreeRoot           []byte
+	ScProof                               []byte
+	FieldElementCertificateFields         [][]byte
+	BitVectorCertificateFields            [][]byte
+	ForwardTransferScFee                  int64
+	MainchainBackwardTransferRequestScFee int64
+	TxIn                                  []*TxIn
+	TxOut                                 []*TxOut
+	BackwardTransferOut                   []*BackwardTransferOut
+}
+
+// CertHash generates the hash for the certificate, matching the
+// certificate id computed by zend.
+func (msg *MsgCertificate) CertHash() chainhash.Hash {
+	// Ignore the error returns since the only way the encode could fail
+	// is being out of memory or due to nil pointers, both of which would
+	// cause a run-time panic.
+	buf := bytes.NewBuffer(make([]byte, 0, msg.SerializeSize()))
+	_ = msg.Serialize(buf)
+	return chainhash.DoubleHashH(buf.Bytes())
+}
+
+// BtcDecode decodes r using the zen protocol encoding into the receiver.
+func (msg *MsgCertificate) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	err := readElement(r, &msg.Version)
+	if err != nil {
+		return err
+	}
+
+	if msg.Version != CertificateVersion {
+		str := fmt.Sprintf("unexpected certificate version %d", msg.Version)
+		return messageError("MsgCertificate.BtcDecode", str)
+	}
+
+	if _, err := io.ReadFull(r, msg.ScID[:]); err != nil {
+		return err
+	}
+
+	err = readElements(r, &msg.EpochNumber, &msg.Quality)
+	if err != nil {
+		return err
+	}
+
+	msg.EndEpochCumScTxCommTreeRoot, err = ReadVarBytes(r, pver,
+		MaxMessagePayload, "certificate end epoch commitment tree root")
+	if err != nil {
+		return err
+	}
+
+	msg.ScProof, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"certificate proof")
+	if err != nil {
+		return err
+	}
+
+	msg.FieldElementCertificateFields, err = readCertificateFields(r, pver,
+		"certificate field element fields")
+	if err != nil {
+		return err
+	}
+
+	msg.BitVectorCertificateFields, err = readCertificateFields(r, pver,
+		"certificate bit vector fields")
+	if err != nil {
+		return err
+	}
+
+	err = readElements(r,
+		&msg.ForwardTransferScFee,
+		&msg.MainchainBackwardTransferRequestScFee,
+	)
+	if err != nil {
+		return err
+	}
+
+	count, err := readCount(r, pver, minTxInPayload, "certificate inputs")
+	if err != nil {
+		return err
+	}
+
+	txIns := make([]TxIn, count)
+	msg.TxIn = make([]*TxIn, count)
+	for i := uint64(0); i < count; i++ {
+		msg.TxIn[i] = &txIns[i]
+		if err := readCertificateTxIn(r, pver, msg.Version, &txIns[i]); err != nil {
+			return err
+		}
+	}
+
+	count, err = readCount(r, pver, MinTxOutPayload, "certificate outputs")
+	if err != nil {
+		return err
+	}
+
+	txOuts := make([]TxOut, count)
+	msg.TxOut = make([]*TxOut, count)
+	for i := uint64(0); i < count; i++ {
+		msg.TxOut[i] = &txOuts[i]
+		if err := readCertificateTxOut(r, pver, msg.Version, &txOuts[i]); err != nil {
+			return err
+		}
+	}
+
+	count, err = readCount(r, pver, backwardTransferOutSize,
+		"certificate backward transfer outputs")
+	if err != nil {
+		return err
+	}
+
+	bwtOuts := make([]BackwardTransferOut, count)
+	msg.BackwardTransferOut = make([]*BackwardTransferOut, count)
+	for i := uint64(0); i < count; i++ {
+		msg.BackwardTransferOut[i] = &bwtOuts[i]
+		if err := readElement(r, &bwtOuts[i].Value); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(r, bwtOuts[i].PubKeyHash[:]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deserialize decodes a certificate from r into the receiver.
+func (msg *MsgCertificate) Deserialize(r io.Reader) error {
+	return msg.BtcDecode(r, 0, BaseEncoding)
+}
+
+// BtcEncode encodes the receiver to w using the zen protocol encoding.
+func (msg *MsgCertificate) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	err := writeElement(w, msg.Version)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg.ScID[:]); err != nil {
+		return err
+	}
+
+	err = writeElements(w, msg.EpochNumber, msg.Quality)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, msg.EndEpochCumScTxCommTreeRoot); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, msg.ScProof); err != nil {
+		return err
+	}
+
+	if err := writeCertificateFields(w, pver, msg.FieldElementCertificateFields); err != nil {
+		return err
+	}
+
+	if err := writeCertificateFields(w, pver, msg.BitVectorCertificateFields); err != nil {
+		return err
+	}
+
+	err = writeElements(w,
+		msg.ForwardTransferScFee,
+		msg.MainchainBackwardTransferRequestScFee,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteVarInt(w, pver, uint64(len(msg.TxIn))); err != nil {
+		return err
+	}
+
+	for _, ti := range msg.TxIn {
+		if err := writeTxIn(w, pver, msg.Version, ti); err != nil {
+			return err
+		}
+	}
+
+	if err := WriteVarInt(w, pver, uint64(len(msg.TxOut))); err != nil {
+		return err
+	}
+
+	for _, to := range msg.TxOut {
+		if err := WriteTxOut(w, pver, msg.Version, to); err != nil {
+			return err
+		}
+	}
+
+	if err := WriteVarInt(w, pver, uint64(len(msg.BackwardTransferOut))); err != nil {
+		return err
+	}
+
+	for _, bwtOut := range msg.BackwardTransferOut {
+		err = binarySerializer.PutUint64(w, littleEndian, uint64(bwtOut.Value))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(bwtOut.PubKeyHash[:]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Serialize encodes the certificate to w using the same format used by
+// zend to compute its hash.
+func (msg *MsgCertificate) Serialize(w io.Writer) error {
+	return msg.BtcEncode(w, 0, BaseEncoding)
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// certificate.
+func (msg *MsgCertificate) SerializeSize() int {
+	// Version 4 bytes + ScID 32 bytes + EpochNumber 4 bytes + Quality
+	// 8 bytes + ForwardTransferScFee 8 bytes +
+	// MainchainBackwardTransferRequestScFee 8 bytes.
+	n := 4 + chainhash.HashSize + 4 + 8 + 8 + 8
+	n += varBytesSerializeSize(msg.EndEpochCumScTxCommTreeRoot)
+	n += varBytesSerializeSize(msg.ScProof)
+	n += certificateFieldsSerializeSize(msg.FieldElementCertificateFields)
+	n += certificateFieldsSerializeSize(msg.BitVectorCertificateFields)
+
+	n += VarIntSerializeSize(uint64(len(msg.TxIn)))
+	for _, txIn := range msg.TxIn {
+		n += txIn.SerializeSize()
+	}
+
+	n += VarIntSerializeSize(uint64(len(msg.TxOut)))
+	for _, txOut := range msg.TxOut {
+		n += txOut.SerializeSize()
+	}
+
+	n += VarIntSerializeSize(uint64(len(msg.BackwardTransferOut))) +
+		len(msg.BackwardTransferOut)*backwardTransferOutSize
+
+	return n
+}
+
+// readCertificateTxIn reads a certificate input. Unlike readTxIn, the
+// signature script is not borrowed from the script pool since certificates
+// are decoded far less often than transactions.
+func readCertificateTxIn(r io.Reader, pver uint32, version int32, ti *TxIn) error {
+	err := readOutPoint(r, pver, version, &ti.PreviousOutPoint)
+	if err != nil {
+		return err
+	}
+
+	ti.SignatureScript, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"certificate input signature script")
+	if err != nil {
+		return err
+	}
+
+	return readElement(r, &ti.Sequence)
+}
+
+// readCertificateTxOut reads a certificate output.
+func readCertificateTxOut(r io.Reader, pver uint32, version int32, to *TxOut) error {
+	err := readElement(r, &to.Value)
+	if err != nil {
+		return err
+	}
+
+	to.PkScript, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"certificate output public key script")
+	return err
+}
+
+// readCertificateFields reads a vector of custom certificate fields.
+func readCertificateFields(r io.Reader, pver uint32, fieldName string) ([][]byte, error) {
+	count, err := readCount(r, pver, 1, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([][]byte, count)
+	for i := uint64(0); i < count; i++ {
+		fields[i], err = ReadVarBytes(r, pver, MaxMessagePayload, fieldName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+// writeCertificateFields encodes a vector of custom certificate fields to w.
+func writeCertificateFields(w io.Writer, pver uint32, fields [][]byte) error {
+	if err := WriteVarInt(w, pver, uint64(len(fields))); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if err := WriteVarBytes(w, pver, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// certificateFieldsSerializeSize returns the number of bytes it would take
+// to serialize a vector of custom certificate fields.
+func certificateFieldsSerializeSize(fields [][]byte) int {
+	n := VarIntSerializeSize(uint64(len(fields)))
+	for _, field := range fields {
+		n += varBytesSerializeSize(field)
+	}
+
+	return n
+}
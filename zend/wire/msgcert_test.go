@@ -0,0 +1,129 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+)
+
+// certificateEncoded loads the certificate
+// 815c88e2bb7a0b083c74bf9643f94db252704f475290c58f6cb123e8793f5376,
+// the mature certificate in
+// zen/client_fixtures/get_block_response_with_mature_certificate.json.
+func certificateEncoded(t *testing.T) []byte {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "certificate_815c88e2.hex"))
+	if err != nil {
+		t.Fatalf("unable to read certificate: %v", err)
+	}
+
+	encoded, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatalf("unable to decode certificate: %v", err)
+	}
+
+	return encoded
+}
+
+// TestCertificate tests decoding, encoding and hashing a certificate.
+func TestCertificate(t *testing.T) {
+	encoded := certificateEncoded(t)
+
+	var cert MsgCertificate
+	if err := cert.Deserialize(bytes.NewReader(encoded)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+
+	wantHash := "815c88e2bb7a0b083c74bf9643f94db252704f475290c58f6cb123e8793f5376"
+	if hash := cert.CertHash(); hash.String() != wantHash {
+		t.Errorf("CertHash: got %s, want %s", hash, wantHash)
+	}
+
+	if size := cert.SerializeSize(); size != 3288 {
+		t.Errorf("SerializeSize: got %d, want %d", size, 3288)
+	}
+
+	wantScID := "03be44ad626a288d2c7e05c7d40f2ba72b8b40749c96fa2cb2201fa0f3b01d6e"
+	if cert.ScID.String() != wantScID {
+		t.Errorf("ScID: got %s, want %s", cert.ScID, wantScID)
+	}
+
+	if cert.Version != CertificateVersion || cert.EpochNumber != 0 || cert.Quality != 5 {
+		t.Errorf("unexpected version %d, epoch %d or quality %d",
+			cert.Version, cert.EpochNumber, cert.Quality)
+	}
+
+	if cert.ForwardTransferScFee != 100000000 ||
+		cert.MainchainBackwardTransferRequestScFee != 200000000 {
+		t.Errorf("unexpected fees %d and %d", cert.ForwardTransferScFee,
+			cert.MainchainBackwardTransferRequestScFee)
+	}
+
+	wantFields := []string{"ab000100", "ccccdddd0000", "0100"}
+	if len(cert.FieldElementCertificateFields) != len(wantFields) {
+		t.Fatalf("unexpected field element fields %x", cert.FieldElementCertificateFields)
+	}
+	for i, field := range cert.FieldElementCertificateFields {
+		if hex.EncodeToString(field) != wantFields[i] {
+			t.Errorf("field element field %d: got %x, want %s", i, field, wantFields[i])
+		}
+	}
+	if len(cert.BitVectorCertificateFields) != 1 {
+		t.Errorf("unexpected bit vector fields %x", cert.BitVectorCertificateFields)
+	}
+
+	prevHash, _ := chainhash.NewHashFromStr(
+		"2143020b085a539ce6cc498e644c49fba54fcbca8cfa79a1139a9a1235a86439",
+	)
+	if len(cert.TxIn) != 1 || cert.TxIn[0].PreviousOutPoint.Hash != *prevHash {
+		t.Errorf("unexpected inputs")
+	}
+	if len(cert.TxOut) != 1 || cert.TxOut[0].Value != 249896353 {
+		t.Errorf("unexpected outputs")
+	}
+
+	wantBwt := &BackwardTransferOut{
+		Value:      200000000,
+		PubKeyHash: pubKeyHashFromHex("80271800053d996d0ebd51ee357e37bfedafc6a6"),
+	}
+	if len(cert.BackwardTransferOut) != 1 || *cert.BackwardTransferOut[0] != *wantBwt {
+		t.Errorf("unexpected backward transfer outputs")
+	}
+
+	var buf bytes.Buffer
+	if err := cert.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Errorf("Serialize: re-encoded certificate differs")
+	}
+}
+
+// TestCertificateErrors ensures decoding a truncated or otherwise invalid
+// certificate fails.
+func TestCertificateErrors(t *testing.T) {
+	encoded := certificateEncoded(t)
+
+	for i := 0; i < len(encoded); i++ {
+		var cert MsgCertificate
+		if err := cert.Deserialize(bytes.NewReader(encoded[:i])); err == nil {
+			t.Errorf("Deserialize: expected error decoding %d of %d bytes",
+				i, len(encoded))
+		}
+	}
+
+	// A transaction is not a certificate.
+	var cert MsgCertificate
+	err := cert.Deserialize(bytes.NewReader(multiTxEncoded))
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("Deserialize: expected MessageError, got %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -28,7 +29,7 @@ func TestHeaders(t *testing.T) {
 	// Ensure max payload is expected value for latest protocol version.
 	// Num headers (varInt) + max allowed headers (header length + 1 byte
 	// for the number of transactions which is always 0).
-	wantPayload := uint32(162009)
+	wantPayload := uint32(2988009)
 	maxPayload := msg.MaxPayloadLength(pver)
 	if maxPayload != wantPayload {
 		t.Errorf("MaxPayloadLength: wrong max payload length for "+
@@ -63,8 +64,9 @@ func TestHeadersWire(t *testing.T) {
 	hash := mainNetGenesisHash
 	merkleHash := blockOne.Header.MerkleRoot
 	bits := uint32(0x1d00ffff)
-	nonce := uint32(0x9962e301)
-	bh := NewBlockHeader(1, &hash, &merkleHash, bits, nonce)
+	nonce := &chainhash.Hash{0x01, 0xe3, 0x62, 0x99}
+	solution := []byte{0x01, 0x02, 0x03, 0x04}
+	bh := NewBlockHeader(1, &hash, &merkleHash, bits, nonce, solution)
 	bh.Version = blockOne.Header.Version
 	bh.Timestamp = blockOne.Header.Timestamp
 
@@ -88,9 +90,17 @@ func TestHeadersWire(t *testing.T) {
 		0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
 		0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 		0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 		0x61, 0xbc, 0x66, 0x49, // Timestamp
 		0xff, 0xff, 0x00, 0x1d, // Bits
-		0x01, 0xe3, 0x62, 0x99, // Nonce
+		0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 		0x00, // TxnCount (0 for headers message)
 	}
 
@@ -231,8 +241,9 @@ func TestHeadersWireErrors(t *testing.T) {
 	hash := mainNetGenesisHash
 	merkleHash := blockOne.Header.MerkleRoot
 	bits := uint32(0x1d00ffff)
-	nonce := uint32(0x9962e301)
-	bh := NewBlockHeader(1, &hash, &merkleHash, bits, nonce)
+	nonce := &chainhash.Hash{0x01, 0xe3, 0x62, 0x99}
+	solution := []byte{0x01, 0x02, 0x03, 0x04}
+	bh := NewBlockHeader(1, &hash, &merkleHash, bits, nonce, solution)
 	bh.Version = blockOne.Header.Version
 	bh.Timestamp = blockOne.Header.Timestamp
 
@@ -250,9 +261,17 @@ func TestHeadersWireErrors(t *testing.T) {
 		0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
 		0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 		0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 		0x61, 0xbc, 0x66, 0x49, // Timestamp
 		0xff, 0xff, 0x00, 0x1d, // Bits
-		0x01, 0xe3, 0x62, 0x99, // Nonce
+		0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 		0x00, // TxnCount (0 for headers message)
 	}
 
@@ -269,7 +288,7 @@ func TestHeadersWireErrors(t *testing.T) {
 
 	// Intentionally invalid block header that has a transaction count used
 	// to force errors.
-	bhTrans := NewBlockHeader(1, &hash, &merkleHash, bits, nonce)
+	bhTrans := NewBlockHeader(1, &hash, &merkleHash, bits, nonce, solution)
 	bhTrans.Version = blockOne.Header.Version
 	bhTrans.Timestamp = blockOne.Header.Timestamp
 
@@ -286,9 +305,17 @@ func TestHeadersWireErrors(t *testing.T) {
 		0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
 		0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 		0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 		0x61, 0xbc, 0x66, 0x49, // Timestamp
 		0xff, 0xff, 0x00, 0x1d, // Bits
-		0x01, 0xe3, 0x62, 0x99, // Nonce
+		0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 		0x01, // TxnCount (should be 0 for headers message, but 1 to force error)
 	}
 
@@ -309,7 +336,7 @@ func TestHeadersWireErrors(t *testing.T) {
 		// Force error with greater than max headers.
 		{maxHeaders, maxHeadersEncoded, pver, BaseEncoding, 3, wireErr, wireErr},
 		// Force error with number of transactions.
-		{transHeader, transHeaderEncoded, pver, BaseEncoding, 81, io.ErrShortWrite, io.EOF},
+		{transHeader, transHeaderEncoded, pver, BaseEncoding, 146, io.ErrShortWrite, io.EOF},
 		// Force error with included transactions.
 		{transHeader, transHeaderEncoded, pver, BaseEncoding, len(transHeaderEncoded), nil, wireErr},
 	}
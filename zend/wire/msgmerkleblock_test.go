@@ -25,8 +25,9 @@ func TestMerkleBlock(t *testing.T) {
 	prevHash := &blockOne.Header.PrevBlock
 	merkleHash := &blockOne.Header.MerkleRoot
 	bits := blockOne.Header.Bits
-	nonce := blockOne.Header.Nonce
-	bh := NewBlockHeader(1, prevHash, merkleHash, bits, nonce)
+	nonce := &blockOne.Header.Nonce
+	solution := blockOne.Header.Solution
+	bh := NewBlockHeader(1, prevHash, merkleHash, bits, nonce, solution)
 
 	// Ensure the command is expected value.
 	wantCmd := "merkleblock"
@@ -117,8 +118,9 @@ func TestMerkleBlockCrossProtocol(t *testing.T) {
 	prevHash := &blockOne.Header.PrevBlock
 	merkleHash := &blockOne.Header.MerkleRoot
 	bits := blockOne.Header.Bits
-	nonce := blockOne.Header.Nonce
-	bh := NewBlockHeader(1, prevHash, merkleHash, bits, nonce)
+	nonce := &blockOne.Header.Nonce
+	solution := blockOne.Header.Solution
+	bh := NewBlockHeader(1, prevHash, merkleHash, bits, nonce, solution)
 
 	msg := NewMsgMerkleBlock(bh)
 
@@ -227,50 +229,65 @@ func TestMerkleBlockWireErrors(t *testing.T) {
 			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 36,
 			io.ErrShortWrite, io.EOF,
 		},
-		// Force error in timestamp.
+		// Force error in sidechain transactions commitment.
 		{
 			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 68,
 			io.ErrShortWrite, io.EOF,
 		},
+		// Force error in timestamp.
+		{
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 100,
+			io.ErrShortWrite, io.EOF,
+		},
 		// Force error in difficulty bits.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 72,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 104,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error in header nonce.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 76,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 108,
+			io.ErrShortWrite, io.EOF,
+		},
+		// Force error in header solution length.
+		{
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 140,
+			io.ErrShortWrite, io.EOF,
+		},
+		// Force error in header solution.
+		{
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 141,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error in transaction count.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 80,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 145,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error in num hashes.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 84,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 149,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error in hashes.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 85,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 150,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error in num flag bytes.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 117,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 182,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error in flag bytes.
 		{
-			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 118,
+			&merkleBlockOne, merkleBlockOneBytes, pver, BaseEncoding, 183,
 			io.ErrShortWrite, io.EOF,
 		},
 		// Force error due to unsupported protocol version.
 		{
 			&merkleBlockOne, merkleBlockOneBytes, pverNoMerkleBlock,
-			BaseEncoding, 119, wireErr, wireErr,
+			BaseEncoding, 184, wireErr, wireErr,
 		},
 	}
 
@@ -331,7 +348,7 @@ func TestMerkleBlockOverflowErrors(t *testing.T) {
 	// allowed tx hashes.
 	var buf bytes.Buffer
 	WriteVarInt(&buf, pver, maxTxPerBlock+1)
-	numHashesOffset := 84
+	numHashesOffset := 149
 	exceedMaxHashes := make([]byte, numHashesOffset)
 	copy(exceedMaxHashes, merkleBlockOneBytes[:numHashesOffset])
 	exceedMaxHashes = append(exceedMaxHashes, buf.Bytes()...)
@@ -340,7 +357,7 @@ func TestMerkleBlockOverflowErrors(t *testing.T) {
 	// allowed flag bytes.
 	buf.Reset()
 	WriteVarInt(&buf, pver, maxFlagsPerMerkleBlock+1)
-	numFlagBytesOffset := 117
+	numFlagBytesOffset := 182
 	exceedMaxFlagBytes := make([]byte, numFlagBytesOffset)
 	copy(exceedMaxFlagBytes, merkleBlockOneBytes[:numFlagBytesOffset])
 	exceedMaxFlagBytes = append(exceedMaxFlagBytes, buf.Bytes()...)
@@ -388,9 +405,10 @@ var merkleBlockOne = MsgMerkleBlock{
 			0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 			0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e,
 		}),
-		Timestamp: time.Unix(0x4966bc61, 0), // 2009-01-08 20:54:25 -0600 CST
-		Bits:      0x1d00ffff,               // 486604799
-		Nonce:     0x9962e301,               // 2573394689
+		Timestamp: time.Unix(0x4966bc61, 0),               // 2009-01-08 20:54:25 -0600 CST
+		Bits:      0x1d00ffff,                             // 486604799
+		Nonce:     chainhash.Hash{0x01, 0xe3, 0x62, 0x99}, // 2573394689
+		Solution:  []byte{0x01, 0x02, 0x03, 0x04},
 	},
 	Transactions: 1,
 	Hashes: []*chainhash.Hash{
@@ -416,9 +434,17 @@ var merkleBlockOneBytes = []byte{
 	0xbb, 0xbe, 0x68, 0x0e, 0x1f, 0xee, 0x14, 0x67,
 	0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 	0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e, // MerkleRoot
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ScTxsCommitment
 	0x61, 0xbc, 0x66, 0x49, // Timestamp
 	0xff, 0xff, 0x00, 0x1d, // Bits
-	0x01, 0xe3, 0x62, 0x99, // Nonce
+	0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+	0x04, 0x01, 0x02, 0x03, 0x04, // Solution
 	0x01, 0x00, 0x00, 0x00, // TxnCount
 	0x01, // Num hashes
 	0x98, 0x20, 0x51, 0xfd, 0x1e, 0x4b, 0xa7, 0x44,
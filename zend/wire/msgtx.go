@@ -308,6 +308,13 @@ type MsgTx struct {
 	TxOut    []*TxOut
 	LockTime uint32
 	TxJoinsplit []*Joinsplit
+
+	// The following are only serialized for sidechain transactions
+	// (SidechainTxVersion).
+	TxCswIn              []*TxCswIn
+	TxScCreationOut      []*TxScCreationOut
+	TxForwardTransferOut []*TxForwardTransferOut
+	TxBwtRequestOut      []*TxBwtRequestOut
 }
 
 // AddTxIn adds a transaction input to the message.
@@ -395,6 +402,9 @@ func (msg *MsgTx) Copy() *MsgTx {
 		newTx.TxOut = append(newTx.TxOut, &newTxOut)
 	}
 
+	// Deep copy the sidechain inputs and outputs.
+	msg.copySidechain(&newTx)
+
 	return &newTx
 }
 
@@ -465,6 +475,14 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 		totalScriptSize += uint64(len(ti.SignatureScript))
 	}
 
+	if IsSidechainVersion(msg.Version) {
+		err = msg.readSidechainInputs(r, pver)
+		if err != nil {
+			returnScriptBuffers()
+			return err
+		}
+	}
+
 	count, err = ReadVarInt(r, pver)
 	if err != nil {
 		returnScriptBuffers()
@@ -498,6 +516,14 @@ func (msg *MsgTx) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error
 		totalScriptSize += uint64(len(to.PkScript))
 	}
 
+	if IsSidechainVersion(msg.Version) {
+		err = msg.readSidechainOutputs(r, pver)
+		if err != nil {
+			returnScriptBuffers()
+			return err
+		}
+	}
+
 	msg.LockTime, err = binarySerializer.Uint32(r, littleEndian)
 	if err != nil {
 		returnScriptBuffers()
@@ -619,6 +645,13 @@ func (msg *MsgTx) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error
 		}
 	}
 
+	if IsSidechainVersion(msg.Version) {
+		err = msg.writeSidechainInputs(w, pver)
+		if err != nil {
+			return err
+		}
+	}
+
 	count = uint64(len(msg.TxOut))
 	err = WriteVarInt(w, pver, count)
 	if err != nil {
@@ -632,6 +665,13 @@ func (msg *MsgTx) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error
 		}
 	}
 
+	if IsSidechainVersion(msg.Version) {
+		err = msg.writeSidechainOutputs(w, pver)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = binarySerializer.PutUint32(w, littleEndian, msg.LockTime)
 
 	if (msg.Version >= 2 || msg.Version == -3) {
@@ -696,6 +736,9 @@ func (msg *MsgTx)  baseSize() int {
 		n += txOut.SerializeSize()
 	}
 
+	n += msg.sidechainInputsSerializeSize()
+	n += msg.sidechainOutputsSerializeSize()
+
 	return n
 }
 
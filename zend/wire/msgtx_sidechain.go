@@ -0,0 +1,751 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg/chainhash"
+)
+
+const (
+	// SidechainTxVersion is the version of transactions that can carry
+	// ceased sidechain withdrawal inputs and sidechain creation, forward
+	// transfer and backward transfer request outputs.
+	SidechainTxVersion = -4
+
+	// pubKeyHashSize is the size of the uint160 public key hashes used
+	// by cross-chain inputs and outputs.
+	pubKeyHashSize = 20
+
+	// minTxCswInPayload is the minimum payload size for a ceased sidechain
+	// withdrawal input. Value 8 bytes + ScID 32 bytes + PubKeyHash 20
+	// bytes + Varint for each of the 5 variable length fields.
+	minTxCswInPayload = 8 + chainhash.HashSize + pubKeyHashSize + 5
+
+	// minTxScCreationOutPayload is the minimum payload size for a
+	// sidechain creation output.
+	minTxScCreationOutPayload = 4 + 8 + chainhash.HashSize + 1 + 1 + 1 + 1 +
+		1 + 1 + 8 + 8 + 4
+
+	// minTxForwardTransferOutPayload is the size of a forward transfer
+	// output. Value 8 bytes + Address 32 bytes + ScID 32 bytes +
+	// MCReturnAddress 20 bytes.
+	minTxForwardTransferOutPayload = 8 + 2*chainhash.HashSize + pubKeyHashSize
+
+	// minTxBwtRequestOutPayload is the minimum payload size for a backward
+	// transfer request output. ScID 32 bytes + Varint for the number of
+	// request data + MCDestinationAddress 20 bytes + ScFee 8 bytes.
+	minTxBwtRequestOutPayload = chainhash.HashSize + 1 + pubKeyHashSize + 8
+
+	// bitVectorFieldConfigSize is the size of a bit vector certificate
+	// field config. BitVectorSizeBits 4 bytes + MaxCompressedSizeBytes
+	// 4 bytes.
+	bitVectorFieldConfigSize = 8
+)
+
+// TxCswIn defines a ceased sidechain withdrawal input (vcsw_ccin).
+type TxCswIn struct {
+	Value                  int64
+	ScID                   chainhash.Hash
+	Nullifier              []byte
+	PubKeyHash             [pubKeyHashSize]byte
+	ScProof                []byte
+	ActCertDataHash        []byte
+	CeasingCumScTxCommTree []byte
+	RedeemScript           []byte
+}
+
+// BitVectorCertificateFieldConfig defines the configuration of a
+// compressed bit vector certificate field.
+type BitVectorCertificateFieldConfig struct {
+	BitVectorSizeBits      int32
+	MaxCompressedSizeBytes int32
+}
+
+// TxScCreationOut defines a sidechain creation output (vsc_ccout).
+// Constant and WCeasedVk are optional and nil when absent.
+type TxScCreationOut struct {
+	WithdrawalEpochLength                      int32
+	Value                                      int64
+	Address                                    chainhash.Hash
+	CustomData                                 []byte
+	Constant                                   []byte
+	WCertVk                                    []byte
+	WCeasedVk                                  []byte
+	FieldElementCertificateFieldConfig         []uint8
+	BitVectorCertificateFieldConfig            []BitVectorCertificateFieldConfig
+	ForwardTransferScFee                       int64
+	MainchainBackwardTransferRequestScFee      int64
+	MainchainBackwardTransferRequestDataLength int32
+}
+
+// TxForwardTransferOut defines a forward transfer output (vft_ccout).
+type TxForwardTransferOut struct {
+	Value           int64
+	Address         chainhash.Hash
+	ScID            chainhash.Hash
+	MCReturnAddress [pubKeyHashSize]byte
+}
+
+// TxBwtRequestOut defines a mainchain backward transfer request
+// output (vmbtr_out).
+type TxBwtRequestOut struct {
+	ScID                 chainhash.Hash
+	ScRequestData        [][]byte
+	MCDestinationAddress [pubKeyHashSize]byte
+	ScFee                int64
+}
+
+// IsSidechainVersion returns whether transactions with the given
+// version carry sidechain inputs and outputs.
+func IsSidechainVersion(version int32) bool {
+	return version == SidechainTxVersion
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// ceased sidechain withdrawal input.
+func (t *TxCswIn) SerializeSize() int {
+	return 8 + chainhash.HashSize + pubKeyHashSize +
+		varBytesSerializeSize(t.Nullifier) +
+		varBytesSerializeSize(t.ScProof) +
+		varBytesSerializeSize(t.ActCertDataHash) +
+		varBytesSerializeSize(t.CeasingCumScTxCommTree) +
+		varBytesSerializeSize(t.RedeemScript)
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// sidechain creation output.
+func (t *TxScCreationOut) SerializeSize() int {
+	// WithdrawalEpochLength 4 bytes + Value 8 bytes + Address 32 bytes +
+	// ForwardTransferScFee 8 bytes + MainchainBackwardTransferRequestScFee
+	// 8 bytes + MainchainBackwardTransferRequestDataLength 4 bytes +
+	// optional flags 1 byte each.
+	n := 4 + 8 + chainhash.HashSize + 8 + 8 + 4 + 2
+	n += varBytesSerializeSize(t.CustomData)
+	n += varBytesSerializeSize(t.WCertVk)
+	if t.Constant != nil {
+		n += varBytesSerializeSize(t.Constant)
+	}
+	if t.WCeasedVk != nil {
+		n += varBytesSerializeSize(t.WCeasedVk)
+	}
+	n += varBytesSerializeSize(t.FieldElementCertificateFieldConfig)
+	n += VarIntSerializeSize(uint64(len(t.BitVectorCertificateFieldConfig))) +
+		len(t.BitVectorCertificateFieldConfig)*bitVectorFieldConfigSize
+
+	return n
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// forward transfer output.
+func (t *TxForwardTransferOut) SerializeSize() int {
+	return minTxForwardTransferOutPayload
+}
+
+// SerializeSize returns the number of bytes it would take to serialize the
+// backward transfer request output.
+func (t *TxBwtRequestOut) SerializeSize() int {
+	n := chainhash.HashSize + pubKeyHashSize + 8 +
+		VarIntSerializeSize(uint64(len(t.ScRequestData)))
+	for _, data := range t.ScRequestData {
+		n += varBytesSerializeSize(data)
+	}
+
+	return n
+}
+
+// varBytesSerializeSize returns the number of bytes it would take to
+// serialize b as a variable length byte array.
+func varBytesSerializeSize(b []byte) int {
+	return VarIntSerializeSize(uint64(len(b))) + len(b)
+}
+
+// sidechainInputsSerializeSize returns the number of bytes it would take to
+// serialize the ceased sidechain withdrawal inputs of the transaction.
+func (msg *MsgTx) sidechainInputsSerializeSize() int {
+	if !IsSidechainVersion(msg.Version) {
+		return 0
+	}
+
+	n := VarIntSerializeSize(uint64(len(msg.TxCswIn)))
+	for _, cswIn := range msg.TxCswIn {
+		n += cswIn.SerializeSize()
+	}
+
+	return n
+}
+
+// sidechainOutputsSerializeSize returns the number of bytes it would take to
+// serialize the cross-chain outputs of the transaction.
+func (msg *MsgTx) sidechainOutputsSerializeSize() int {
+	if !IsSidechainVersion(msg.Version) {
+		return 0
+	}
+
+	n := VarIntSerializeSize(uint64(len(msg.TxScCreationOut))) +
+		VarIntSerializeSize(uint64(len(msg.TxForwardTransferOut))) +
+		VarIntSerializeSize(uint64(len(msg.TxBwtRequestOut)))
+	for _, scOut := range msg.TxScCreationOut {
+		n += scOut.SerializeSize()
+	}
+	for _, ftOut := range msg.TxForwardTransferOut {
+		n += ftOut.SerializeSize()
+	}
+	for _, bwtOut := range msg.TxBwtRequestOut {
+		n += bwtOut.SerializeSize()
+	}
+
+	return n
+}
+
+// readCount reads a varint item count from r and makes sure that at least
+// count items of minSize bytes could fit into a message.
+func readCount(r io.Reader, pver uint32, minSize int, fieldName string) (uint64, error) {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prevent more items than could possibly fit into a message.  It
+	// would be possible to cause memory exhaustion and panics without a
+	// sane upper bound on this count.
+	max := uint64(MaxMessagePayload/minSize) + 1
+	if count > max {
+		str := fmt.Sprintf("too many %s to fit into max message size "+
+			"[count %d, max %d]", fieldName, count, max)
+		return 0, messageError("MsgTx.BtcDecode", str)
+	}
+
+	return count, nil
+}
+
+// readSidechainInputs reads the ceased sidechain withdrawal inputs of a
+// sidechain transaction from r.
+func (msg *MsgTx) readSidechainInputs(r io.Reader, pver uint32) error {
+	count, err := readCount(r, pver, minTxCswInPayload,
+		"ceased sidechain withdrawal inputs")
+	if err != nil {
+		return err
+	}
+
+	cswIns := make([]TxCswIn, count)
+	msg.TxCswIn = make([]*TxCswIn, count)
+	for i := uint64(0); i < count; i++ {
+		msg.TxCswIn[i] = &cswIns[i]
+		if err := readTxCswIn(r, pver, &cswIns[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readSidechainOutputs reads the sidechain creation, forward transfer and
+// backward transfer request outputs of a sidechain transaction from r.
+func (msg *MsgTx) readSidechainOutputs(r io.Reader, pver uint32) error {
+	count, err := readCount(r, pver, minTxScCreationOutPayload,
+		"sidechain creation outputs")
+	if err != nil {
+		return err
+	}
+
+	scOuts := make([]TxScCreationOut, count)
+	msg.TxScCreationOut = make([]*TxScCreationOut, count)
+	for i := uint64(0); i < count; i++ {
+		msg.TxScCreationOut[i] = &scOuts[i]
+		if err := readTxScCreationOut(r, pver, &scOuts[i]); err != nil {
+			return err
+		}
+	}
+
+	count, err = readCount(r, pver, minTxForwardTransferOutPayload,
+		"forward transfer outputs")
+	if err != nil {
+		return err
+	}
+
+	ftOuts := make([]TxForwardTransferOut, count)
+	msg.TxForwardTransferOut = make([]*TxForwardTransferOut, count)
+	for i := uint64(0); i < count; i++ {
+		msg.TxForwardTransferOut[i] = &ftOuts[i]
+		if err := readTxForwardTransferOut(r, &ftOuts[i]); err != nil {
+			return err
+		}
+	}
+
+	count, err = readCount(r, pver, minTxBwtRequestOutPayload,
+		"backward transfer request outputs")
+	if err != nil {
+		return err
+	}
+
+	bwtOuts := make([]TxBwtRequestOut, count)
+	msg.TxBwtRequestOut = make([]*TxBwtRequestOut, count)
+	for i := uint64(0); i < count; i++ {
+		msg.TxBwtRequestOut[i] = &bwtOuts[i]
+		if err := readTxBwtRequestOut(r, pver, &bwtOuts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSidechainInputs encodes the ceased sidechain withdrawal inputs of a
+// sidechain transaction to w.
+func (msg *MsgTx) writeSidechainInputs(w io.Writer, pver uint32) error {
+	err := WriteVarInt(w, pver, uint64(len(msg.TxCswIn)))
+	if err != nil {
+		return err
+	}
+
+	for _, cswIn := range msg.TxCswIn {
+		if err := writeTxCswIn(w, pver, cswIn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSidechainOutputs encodes the sidechain creation, forward transfer and
+// backward transfer request outputs of a sidechain transaction to w.
+func (msg *MsgTx) writeSidechainOutputs(w io.Writer, pver uint32) error {
+	err := WriteVarInt(w, pver, uint64(len(msg.TxScCreationOut)))
+	if err != nil {
+		return err
+	}
+
+	for _, scOut := range msg.TxScCreationOut {
+		if err := writeTxScCreationOut(w, pver, scOut); err != nil {
+			return err
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.TxForwardTransferOut)))
+	if err != nil {
+		return err
+	}
+
+	for _, ftOut := range msg.TxForwardTransferOut {
+		if err := writeTxForwardTransferOut(w, ftOut); err != nil {
+			return err
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.TxBwtRequestOut)))
+	if err != nil {
+		return err
+	}
+
+	for _, bwtOut := range msg.TxBwtRequestOut {
+		if err := writeTxBwtRequestOut(w, pver, bwtOut); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readOptionalVarBytes reads an optional variable length byte array, encoded
+// as a one byte presence flag followed by the array when the flag is set. It
+// returns nil when the array is absent.
+func readOptionalVarBytes(r io.Reader, pver uint32, fieldName string) ([]byte, error) {
+	flag, err := binarySerializer.Uint8(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch flag {
+	case 0:
+		return nil, nil
+	case 1:
+		return ReadVarBytes(r, pver, MaxMessagePayload, fieldName)
+	default:
+		str := fmt.Sprintf("invalid optional flag %d for %s", flag, fieldName)
+		return nil, messageError("readOptionalVarBytes", str)
+	}
+}
+
+// writeOptionalVarBytes encodes b as an optional variable length byte array.
+func writeOptionalVarBytes(w io.Writer, pver uint32, b []byte) error {
+	if b == nil {
+		return binarySerializer.PutUint8(w, 0)
+	}
+
+	if err := binarySerializer.PutUint8(w, 1); err != nil {
+		return err
+	}
+
+	return WriteVarBytes(w, pver, b)
+}
+
+// readTxCswIn reads the next sequence of bytes from r as a ceased sidechain
+// withdrawal input.
+func readTxCswIn(r io.Reader, pver uint32, ti *TxCswIn) error {
+	err := readElement(r, &ti.Value)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, ti.ScID[:]); err != nil {
+		return err
+	}
+
+	ti.Nullifier, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"csw input nullifier")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, ti.PubKeyHash[:]); err != nil {
+		return err
+	}
+
+	ti.ScProof, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"csw input proof")
+	if err != nil {
+		return err
+	}
+
+	ti.ActCertDataHash, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"csw input active certificate data hash")
+	if err != nil {
+		return err
+	}
+
+	ti.CeasingCumScTxCommTree, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"csw input ceasing cumulative commitment tree")
+	if err != nil {
+		return err
+	}
+
+	ti.RedeemScript, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"csw input redeem script")
+	return err
+}
+
+// writeTxCswIn encodes ti to the zen protocol encoding for a ceased sidechain
+// withdrawal input to w.
+func writeTxCswIn(w io.Writer, pver uint32, ti *TxCswIn) error {
+	err := binarySerializer.PutUint64(w, littleEndian, uint64(ti.Value))
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(ti.ScID[:]); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, ti.Nullifier); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(ti.PubKeyHash[:]); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, ti.ScProof); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, ti.ActCertDataHash); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, ti.CeasingCumScTxCommTree); err != nil {
+		return err
+	}
+
+	return WriteVarBytes(w, pver, ti.RedeemScript)
+}
+
+// readTxScCreationOut reads the next sequence of bytes from r as a sidechain
+// creation output.
+func readTxScCreationOut(r io.Reader, pver uint32, to *TxScCreationOut) error {
+	err := readElements(r, &to.WithdrawalEpochLength, &to.Value)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, to.Address[:]); err != nil {
+		return err
+	}
+
+	to.CustomData, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"sidechain creation custom data")
+	if err != nil {
+		return err
+	}
+
+	to.Constant, err = readOptionalVarBytes(r, pver,
+		"sidechain creation constant")
+	if err != nil {
+		return err
+	}
+
+	to.WCertVk, err = ReadVarBytes(r, pver, MaxMessagePayload,
+		"sidechain creation certificate verification key")
+	if err != nil {
+		return err
+	}
+
+	to.WCeasedVk, err = readOptionalVarBytes(r, pver,
+		"sidechain creation ceased verification key")
+	if err != nil {
+		return err
+	}
+
+	to.FieldElementCertificateFieldConfig, err = ReadVarBytes(r, pver,
+		MaxMessagePayload, "sidechain creation field element config")
+	if err != nil {
+		return err
+	}
+
+	count, err := readCount(r, pver, bitVectorFieldConfigSize,
+		"sidechain creation bit vector configs")
+	if err != nil {
+		return err
+	}
+
+	to.BitVectorCertificateFieldConfig = make([]BitVectorCertificateFieldConfig, count)
+	for i := uint64(0); i < count; i++ {
+		config := &to.BitVectorCertificateFieldConfig[i]
+		err = readElements(r, &config.BitVectorSizeBits, &config.MaxCompressedSizeBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return readElements(r,
+		&to.ForwardTransferScFee,
+		&to.MainchainBackwardTransferRequestScFee,
+		&to.MainchainBackwardTransferRequestDataLength,
+	)
+}
+
+// writeTxScCreationOut encodes to to the zen protocol encoding for a
+// sidechain creation output to w.
+func writeTxScCreationOut(w io.Writer, pver uint32, to *TxScCreationOut) error {
+	err := writeElements(w, to.WithdrawalEpochLength, to.Value)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(to.Address[:]); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, to.CustomData); err != nil {
+		return err
+	}
+
+	if err := writeOptionalVarBytes(w, pver, to.Constant); err != nil {
+		return err
+	}
+
+	if err := WriteVarBytes(w, pver, to.WCertVk); err != nil {
+		return err
+	}
+
+	if err := writeOptionalVarBytes(w, pver, to.WCeasedVk); err != nil {
+		return err
+	}
+
+	err = WriteVarBytes(w, pver, to.FieldElementCertificateFieldConfig)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(to.BitVectorCertificateFieldConfig)))
+	if err != nil {
+		return err
+	}
+
+	for _, config := range to.BitVectorCertificateFieldConfig {
+		err = writeElements(w, config.BitVectorSizeBits, config.MaxCompressedSizeBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeElements(w,
+		to.ForwardTransferScFee,
+		to.MainchainBackwardTransferRequestScFee,
+		to.MainchainBackwardTransferRequestDataLength,
+	)
+}
+
+// readTxForwardTransferOut reads the next sequence of bytes from r as a
+// forward transfer output.
+func readTxForwardTransferOut(r io.Reader, to *TxForwardTransferOut) error {
+	err := readElement(r, &to.Value)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, to.Address[:]); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, to.ScID[:]); err != nil {
+		return err
+	}
+
+	_, err = io.ReadFull(r, to.MCReturnAddress[:])
+	return err
+}
+
+// writeTxForwardTransferOut encodes to to the zen protocol encoding for a
+// forward transfer output to w.
+func writeTxForwardTransferOut(w io.Writer, to *TxForwardTransferOut) error {
+	err := binarySerializer.PutUint64(w, littleEndian, uint64(to.Value))
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(to.Address[:]); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(to.ScID[:]); err != nil {
+		return err
+	}
+
+	_, err = w.Write(to.MCReturnAddress[:])
+	return err
+}
+
+// readTxBwtRequestOut reads the next sequence of bytes from r as a backward
+// transfer request output.
+func readTxBwtRequestOut(r io.Reader, pver uint32, to *TxBwtRequestOut) error {
+	if _, err := io.ReadFull(r, to.ScID[:]); err != nil {
+		return err
+	}
+
+	count, err := readCount(r, pver, 1, "backward transfer request data")
+	if err != nil {
+		return err
+	}
+
+	to.ScRequestData = make([][]byte, count)
+	for i := uint64(0); i < count; i++ {
+		to.ScRequestData[i], err = ReadVarBytes(r, pver, MaxMessagePayload,
+			"backward transfer request data")
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.ReadFull(r, to.MCDestinationAddress[:]); err != nil {
+		return err
+	}
+
+	return readElement(r, &to.ScFee)
+}
+
+// writeTxBwtRequestOut encodes to to the zen protocol encoding for a
+// backward transfer request output to w.
+func writeTxBwtRequestOut(w io.Writer, pver uint32, to *TxBwtRequestOut) error {
+	if _, err := w.Write(to.ScID[:]); err != nil {
+		return err
+	}
+
+	err := WriteVarInt(w, pver, uint64(len(to.ScRequestData)))
+	if err != nil {
+		return err
+	}
+
+	for _, data := range to.ScRequestData {
+		if err := WriteVarBytes(w, pver, data); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Write(to.MCDestinationAddress[:]); err != nil {
+		return err
+	}
+
+	return binarySerializer.PutUint64(w, littleEndian, uint64(to.ScFee))
+}
+
+// copyBytes returns a copy of b, preserving the difference between
+// nil and empty slices.
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	newBytes := make([]byte, len(b))
+	copy(newBytes, b)
+	return newBytes
+}
+
+// copySidechain deep copies the sidechain inputs and outputs of msg
+// into newTx.
+func (msg *MsgTx) copySidechain(newTx *MsgTx) {
+	if msg.TxCswIn != nil {
+		newTx.TxCswIn = make([]*TxCswIn, 0, len(msg.TxCswIn))
+	}
+	if msg.TxScCreationOut != nil {
+		newTx.TxScCreationOut = make([]*TxScCreationOut, 0, len(msg.TxScCreationOut))
+	}
+	if msg.TxForwardTransferOut != nil {
+		newTx.TxForwardTransferOut = make([]*TxForwardTransferOut, 0, len(msg.TxForwardTransferOut))
+	}
+	if msg.TxBwtRequestOut != nil {
+		newTx.TxBwtRequestOut = make([]*TxBwtRequestOut, 0, len(msg.TxBwtRequestOut))
+	}
+
+	for _, old := range msg.TxCswIn {
+		newTx.TxCswIn = append(newTx.TxCswIn, &TxCswIn{
+			Value:                  old.Value,
+			ScID:                   old.ScID,
+			Nullifier:              copyBytes(old.Nullifier),
+			PubKeyHash:             old.PubKeyHash,
+			ScProof:                copyBytes(old.ScProof),
+			ActCertDataHash:        copyBytes(old.ActCertDataHash),
+			CeasingCumScTxCommTree: copyBytes(old.CeasingCumScTxCommTree),
+			RedeemScript:           copyBytes(old.RedeemScript),
+		})
+	}
+
+	for _, old := range msg.TxScCreationOut {
+		newOut := *old
+		newOut.CustomData = copyBytes(old.CustomData)
+		newOut.Constant = copyBytes(old.Constant)
+		newOut.WCertVk = copyBytes(old.WCertVk)
+		newOut.WCeasedVk = copyBytes(old.WCeasedVk)
+		newOut.FieldElementCertificateFieldConfig = copyBytes(
+			old.FieldElementCertificateFieldConfig,
+		)
+		if old.BitVectorCertificateFieldConfig != nil {
+			newOut.BitVectorCertificateFieldConfig = make(
+				[]BitVectorCertificateFieldConfig,
+				len(old.BitVectorCertificateFieldConfig),
+			)
+			copy(newOut.BitVectorCertificateFieldConfig, old.BitVectorCertificateFieldConfig)
+		}
+		newTx.TxScCreationOut = append(newTx.TxScCreationOut, &newOut)
+	}
+
+	for _, old := range msg.TxForwardTransferOut {
+		newOut := *old
+		newTx.TxForwardTransferOut = append(newTx.TxForwardTransferOut, &newOut)
+	}
+
+	for _, old := range msg.TxBwtRequestOut {
+		newOut := *old
+		if old.ScRequestData != nil {
+			newOut.ScRequestData = make([][]byte, 0, len(old.ScRequestData))
+			for _, data := range old.ScRequestData {
+				newOut.ScRequestData = append(newOut.ScRequestData, copyBytes(data))
+			}
+		}
+		newTx.TxBwtRequestOut = append(newTx.TxBwtRequestOut, &newOut)
+	}
+}
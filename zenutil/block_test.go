@@ -6,8 +6,12 @@ package zenutil_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"io"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,7 +40,7 @@ func TestBlock(t *testing.T) {
 	}
 
 	// Hash for block 100,000.
-	wantHashStr := "3ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	wantHashStr := "cd656b29e2266a7ca84c7d8ea3be486e8db3f3f32a30a4b8f3b5477f71a6beed"
 	wantHash, err := chainhash.NewHashFromStr(wantHashStr)
 	if err != nil {
 		t.Errorf("NewHashFromStr: %v", err)
@@ -145,10 +149,10 @@ func TestBlock(t *testing.T) {
 
 	// Transaction offsets and length for the transaction in Block100000.
 	wantTxLocs := []wire.TxLoc{
-		{TxStart: 81, TxLen: 135},
-		{TxStart: 216, TxLen: 259},
-		{TxStart: 475, TxLen: 257},
-		{TxStart: 732, TxLen: 225},
+		{TxStart: 146, TxLen: 135},
+		{TxStart: 281, TxLen: 259},
+		{TxStart: 540, TxLen: 257},
+		{TxStart: 797, TxLen: 225},
 	}
 
 	// Ensure the transaction location information is accurate.
@@ -164,6 +168,60 @@ func TestBlock(t *testing.T) {
 	}
 }
 
+// TestBlockMainnetGenesis tests the API for Block using the genesis block
+// of the main network.
+func TestBlockMainnetGenesis(t *testing.T) {
+	encodedHex, err := ioutil.ReadFile(filepath.Join("..", "zend", "wire",
+		"testdata", "block_0007104c.hex"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	encoded, err := hex.DecodeString(strings.TrimSpace(string(encodedHex)))
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+
+	b, err := zenutil.NewBlockFromBytes(encoded)
+	if err != nil {
+		t.Fatalf("NewBlockFromBytes: %v", err)
+	}
+
+	wantHash := "0007104ccda289427919efc39dc9e4d499804b7bebc22df55f8b834301260602"
+	if hash := b.Hash(); hash.String() != wantHash {
+		t.Errorf("Hash: got %s, want %s", hash, wantHash)
+	}
+
+	wantTxHash := "19612bcf00ea7611d315d7f43554fa983c6e8c30cba17e52c679e0e80abf7d42"
+	txHash, err := b.TxHash(0)
+	if err != nil {
+		t.Fatalf("TxHash: %v", err)
+	}
+	if txHash.String() != wantTxHash {
+		t.Errorf("TxHash: got %s, want %s", txHash, wantTxHash)
+	}
+	if len(b.Transactions()) != 1 {
+		t.Errorf("Transactions: got %d transactions, want 1",
+			len(b.Transactions()))
+	}
+
+	wantTxLocs := []wire.TxLoc{{TxStart: 1488, TxLen: 207}}
+	txLocs, err := b.TxLoc()
+	if err != nil {
+		t.Fatalf("TxLoc: %v", err)
+	}
+	if !reflect.DeepEqual(txLocs, wantTxLocs) {
+		t.Errorf("TxLoc: got %v, want %v", txLocs, wantTxLocs)
+	}
+
+	serialized, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if !bytes.Equal(serialized, encoded) {
+		t.Errorf("Bytes: got %x, want %x", serialized, encoded)
+	}
+}
+
 // TestNewBlockFromBytes tests creation of a Block from serialized bytes.
 func TestNewBlockFromBytes(t *testing.T) {
 	// Serialize the test block.
@@ -257,7 +315,7 @@ func TestBlockErrors(t *testing.T) {
 	}
 
 	// Truncate the block byte buffer to force errors.
-	shortBytes := block100000Bytes[:80]
+	shortBytes := block100000Bytes[:145]
 	_, err = zenutil.NewBlockFromBytes(shortBytes)
 	if err != io.EOF {
 		t.Errorf("NewBlockFromBytes: did not get expected error - "+
@@ -316,9 +374,10 @@ var Block100000 = wire.MsgBlock{
 			0x28, 0xc3, 0x06, 0x7c, 0xc3, 0x8d, 0x48, 0x85,
 			0xef, 0xb5, 0xa4, 0xac, 0x42, 0x47, 0xe9, 0xf3,
 		}), // f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766
-		Timestamp: time.Unix(1293623863, 0), // 2010-12-29 11:57:43 +0000 UTC
-		Bits:      0x1b04864c,               // 453281356
-		Nonce:     0x10572b0f,               // 274148111
+		Timestamp: time.Unix(1293623863, 0),               // 2010-12-29 11:57:43 +0000 UTC
+		Bits:      0x1b04864c,                             // 453281356
+		Nonce:     chainhash.Hash{0x0f, 0x2b, 0x57, 0x10}, // 274148111
+		Solution:  []byte{0x01, 0x02, 0x03, 0x04},
 	},
 	Transactions: []*wire.MsgTx{
 		{
@@ -0,0 +1,364 @@
+// Copyright (c) 2014-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+// References:
+//   [BIP32]: BIP0032 - Hierarchical Deterministic Wallets
+//   https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg"
+)
+
+// TestBIP0032Vectors tests the vectors provided by [BIP32] to ensure the
+// derivation works as intended.
+func TestBIP0032Vectors(t *testing.T) {
+	// The master seeds for each of the two test vectors in [BIP32].
+	testVec1MasterHex := "000102030405060708090a0b0c0d0e0f"
+	testVec2MasterHex := "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542"
+
+	tests := []struct {
+		name     string
+		master   string
+		path     string
+		wantPub  string
+		wantPriv string
+	}{
+		// Test vector 1
+		{
+			name:     "test vector 1 chain m",
+			master:   testVec1MasterHex,
+			path:     "m",
+			wantPub:  "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+			wantPriv: "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		},
+		{
+			name:     "test vector 1 chain m/0H",
+			master:   testVec1MasterHex,
+			path:     "m/0'",
+			wantPub:  "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			wantPriv: "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+		},
+		{
+			name:     "test vector 1 chain m/0H/1",
+			master:   testVec1MasterHex,
+			path:     "m/0'/1",
+			wantPub:  "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+			wantPriv: "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+		},
+		{
+			name:     "test vector 1 chain m/0H/1/2H",
+			master:   testVec1MasterHex,
+			path:     "m/0'/1/2'",
+			wantPub:  "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			wantPriv: "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM",
+		},
+		{
+			name:     "test vector 1 chain m/0H/1/2H/2",
+			master:   testVec1MasterHex,
+			path:     "m/0'/1/2'/2",
+			wantPub:  "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+			wantPriv: "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334",
+		},
+		{
+			name:     "test vector 1 chain m/0H/1/2H/2/1000000000",
+			master:   testVec1MasterHex,
+			path:     "m/0'/1/2'/2/1000000000",
+			wantPub:  "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+			wantPriv: "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+		},
+
+		// Test vector 2
+		{
+			name:     "test vector 2 chain m",
+			master:   testVec2MasterHex,
+			path:     "m",
+			wantPub:  "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+			wantPriv: "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U",
+		},
+		{
+			name:     "test vector 2 chain m/0",
+			master:   testVec2MasterHex,
+			path:     "m/0",
+			wantPub:  "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+			wantPriv: "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt",
+		},
+		{
+			name:     "test vector 2 chain m/0/2147483647H",
+			master:   testVec2MasterHex,
+			path:     "m/0/2147483647'",
+			wantPub:  "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+			wantPriv: "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9",
+		},
+		{
+			name:     "test vector 2 chain m/0/2147483647H/1",
+			master:   testVec2MasterHex,
+			path:     "m/0/2147483647'/1",
+			wantPub:  "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon",
+			wantPriv: "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef",
+		},
+	}
+
+	for _, test := range tests {
+		masterSeed, err := hex.DecodeString(test.master)
+		if err != nil {
+			t.Errorf("DecodeString #%s: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+
+		extKey, err := NewMaster(masterSeed, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Errorf("NewMaster #%s: unexpected error when "+
+				"creating new master key: %v", test.name, err)
+			continue
+		}
+
+		path, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath #%s: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+
+		extKey, err = extKey.DerivePath(path)
+		if err != nil {
+			t.Errorf("DerivePath #%s: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+
+		if extKey.Depth() != uint8(len(path)) {
+			t.Errorf("Depth of key %d should match fixture path: %v",
+				extKey.Depth(), len(path))
+			continue
+		}
+
+		privStr := extKey.String()
+		if privStr != test.wantPriv {
+			t.Errorf("Serialize #%s: mismatched serialized "+
+				"private extended key -- got: %s, want: %s",
+				test.name, privStr, test.wantPriv)
+			continue
+		}
+
+		pubKey, err := extKey.Neuter()
+		if err != nil {
+			t.Errorf("Neuter #%s: unexpected error: %v ", test.name,
+				err)
+			continue
+		}
+
+		// Neutering a second time should have no effect.
+		pubKey, err = pubKey.Neuter()
+		if err != nil {
+			t.Errorf("Neuter #%s: unexpected error: %v", test.name,
+				err)
+			return
+		}
+
+		pubStr := pubKey.String()
+		if pubStr != test.wantPub {
+			t.Errorf("Neuter #%s: mismatched serialized "+
+				"public extended key -- got: %s, want: %s",
+				test.name, pubStr, test.wantPub)
+			continue
+		}
+	}
+}
+
+// TestPublicDerivation tests several vectors which derive public keys from
+// other public keys works as intended.
+func TestPublicDerivation(t *testing.T) {
+	// The public extended keys for test vectors in [BIP32].
+	testVec1MasterPubKey := "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+	testVec2MasterPubKey := "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB"
+
+	tests := []struct {
+		name    string
+		master  string
+		path    string
+		wantPub string
+	}{
+		{
+			name:    "test vector 1 chain m",
+			master:  testVec1MasterPubKey,
+			path:    "m",
+			wantPub: testVec1MasterPubKey,
+		},
+		{
+			name:    "test vector 2 chain m/0",
+			master:  testVec2MasterPubKey,
+			path:    "m/0",
+			wantPub: "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+		},
+		{
+			name:    "test vector 1 chain m/0H/1/2H/2 from m/0H/1/2H",
+			master:  "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			path:    "2",
+			wantPub: "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		},
+		{
+			name:    "test vector 1 chain m/0H/1/2H/2/1000000000 from m/0H/1/2H",
+			master:  "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			path:    "2/1000000000",
+			wantPub: "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		},
+	}
+
+	for _, test := range tests {
+		extKey, err := NewKeyFromString(test.master)
+		if err != nil {
+			t.Errorf("NewKeyFromString #%s: unexpected error "+
+				"creating extended key: %v", test.name, err)
+			continue
+		}
+
+		path, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath #%s: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+
+		extKey, err = extKey.DerivePath(path)
+		if err != nil {
+			t.Errorf("DerivePath #%s: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+
+		pubStr := extKey.String()
+		if pubStr != test.wantPub {
+			t.Errorf("DerivePath #%s: mismatched serialized "+
+				"public extended key -- got: %s, want: %s",
+				test.name, pubStr, test.wantPub)
+			continue
+		}
+	}
+}
+
+// TestErrors performs some negative tests for various invalid cases to ensure
+// the errors are handled properly.
+func TestErrors(t *testing.T) {
+	// Should get an error when seed has too few bytes.
+	net := &chaincfg.MainNetParams
+	_, err := NewMaster(make([]byte, MinSeedBytes-1), net)
+	if err != ErrInvalidSeedLen {
+		t.Fatalf("NewMaster: mismatched error -- got: %v, want: %v",
+			err, ErrInvalidSeedLen)
+	}
+
+	// Should get an error when seed has too many bytes.
+	_, err = NewMaster(make([]byte, MaxSeedBytes+1), net)
+	if err != ErrInvalidSeedLen {
+		t.Fatalf("NewMaster: mismatched error -- got: %v, want: %v",
+			err, ErrInvalidSeedLen)
+	}
+
+	// Generate a new key and neuter it to a public extended key.
+	seed, err := GenerateSeed(RecommendedSeedLen)
+	if err != nil {
+		t.Fatalf("GenerateSeed: unexpected error: %v", err)
+	}
+	extKey, err := NewMaster(seed, net)
+	if err != nil {
+		t.Fatalf("NewMaster: unexpected error: %v", err)
+	}
+	pubKey, err := extKey.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: unexpected error: %v", err)
+	}
+
+	// Deriving a hardened child extended key should fail from a public key.
+	_, err = pubKey.Derive(HardenedKeyStart)
+	if err != ErrDeriveHardFromPublic {
+		t.Fatalf("Derive: mismatched error -- got: %v, want: %v",
+			err, ErrDeriveHardFromPublic)
+	}
+
+	// NewKeyFromString failure tests.
+	tests := []struct {
+		name string
+		key  string
+		err  error
+	}{
+		{
+			name: "invalid key length",
+			key:  "xpub1234",
+			err:  ErrInvalidKeyLen,
+		},
+		{
+			name: "bad checksum",
+			key:  "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EBygr15",
+			err:  ErrBadChecksum,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := NewKeyFromString(test.key)
+		if !reflect.DeepEqual(err, test.err) {
+			t.Errorf("NewKeyFromString #%d (%s): mismatched error "+
+				"-- got: %v, want: %v", i, test.name, err,
+				test.err)
+			continue
+		}
+	}
+}
+
+// TestBIP44Path ensures BIP44 paths use the HD coin type of each network and
+// that paths round trip through ParsePath and FormatPath.
+func TestBIP44Path(t *testing.T) {
+	path := BIP44Path(&chaincfg.MainNetParams, 0, ExternalBranch, 5)
+	if got := FormatPath(path); got != "m/44'/121'/0'/0/5" {
+		t.Errorf("FormatPath: got %s, want m/44'/121'/0'/0/5", got)
+	}
+
+	parsed, err := ParsePath("m/44h/121h/0h/0/5")
+	if err != nil {
+		t.Fatalf("ParsePath: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, path) {
+		t.Errorf("ParsePath: got %v, want %v", parsed, path)
+	}
+
+	testPath := BIP44Path(&chaincfg.RegressionNetParams, 2, InternalBranch, 7)
+	if got := FormatPath(testPath); got != "m/44'/1'/2'/1/7" {
+		t.Errorf("FormatPath: got %s, want m/44'/1'/2'/1/7", got)
+	}
+
+	for _, invalid := range []string{"m/", "m/a", "m/0//1", "m/2147483648", "/0", "m/-1"} {
+		if _, err := ParsePath(invalid); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ParsePath(%q): got %v, want %v", invalid, err, ErrInvalidPath)
+		}
+	}
+}
+
+// TestZero ensures that zeroing an extended key works as intended.
+func TestZero(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatalf("DecodeString: unexpected error: %v", err)
+	}
+
+	key, err := NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: unexpected error: %v", err)
+	}
+
+	key.Zero()
+	if key.String() != "zeroed extended key" {
+		t.Errorf("String: got %s, want zeroed extended key", key.String())
+	}
+	if _, err := key.ECPrivKey(); err != ErrNotPrivExtKey {
+		t.Errorf("ECPrivKey: mismatched error -- got: %v, want: %v",
+			err, ErrNotPrivExtKey)
+	}
+}
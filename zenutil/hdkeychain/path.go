@@ -0,0 +1,114 @@
+// Copyright (c) 2014-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+// References:
+//   [BIP44]: BIP0044 - Multi-Account Hierarchy for Deterministic Wallets
+//   https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/HorizenOfficial/rosetta-zen/zend/chaincfg"
+)
+
+const (
+	// BIP44Purpose is the purpose level of [BIP44] derivation paths.
+	BIP44Purpose = 44
+
+	// ExternalBranch is the [BIP44] change level of the chain used for
+	// receiving addresses.
+	ExternalBranch = 0
+
+	// InternalBranch is the [BIP44] change level of the chain used for
+	// change addresses.
+	InternalBranch = 1
+)
+
+// ErrInvalidPath describes an error in which a derivation path could not
+// be parsed.
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// ParsePath parses a derivation path of the form m/44'/121'/0'/0/5 into the
+// child indexes to derive.  Hardened indexes are marked with a trailing '
+// or h.  The leading m/ is optional, so relative paths such as 0/5 are
+// accepted as well, and m on its own is the empty path.
+func ParsePath(path string) ([]uint32, error) {
+	path = strings.TrimSpace(path)
+	if path == "m" || path == "" {
+		return []uint32{}, nil
+	}
+	path = strings.TrimPrefix(path, "m/")
+
+	elements := strings.Split(path, "/")
+	indexes := make([]uint32, len(elements))
+	for i, element := range elements {
+		hardened := strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h")
+		if hardened {
+			element = element[:len(element)-1]
+		}
+
+		index, err := strconv.ParseUint(element, 10, 32)
+		if err != nil || index >= HardenedKeyStart {
+			return nil, fmt.Errorf("%w: %q is not a valid index", ErrInvalidPath, elements[i])
+		}
+
+		if hardened {
+			index += HardenedKeyStart
+		}
+		indexes[i] = uint32(index)
+	}
+
+	return indexes, nil
+}
+
+// FormatPath returns the m/ prefixed string form of path, using ' to
+// mark hardened indexes.
+func FormatPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range path {
+		if index >= HardenedKeyStart {
+			fmt.Fprintf(&b, "/%d'", index-HardenedKeyStart)
+			continue
+		}
+		fmt.Fprintf(&b, "/%d", index)
+	}
+
+	return b.String()
+}
+
+// BIP44Path returns the [BIP44] path
+// m/44'/coin_type'/account'/change/index using the HD coin type of the
+// passed network, which is 121 for the Horizen main network.
+func BIP44Path(net *chaincfg.Params, account, change, index uint32) []uint32 {
+	return []uint32{
+		HardenedKeyStart + BIP44Purpose,
+		HardenedKeyStart + net.HDCoinType,
+		HardenedKeyStart + account,
+		change,
+		index,
+	}
+}
+
+// DerivePath returns the extended key derived from k by deriving each of
+// the child indexes of path in turn.  Paths are relative to k, so the
+// path of an account extended public key should not include the
+// hardened purpose, coin type and account levels.
+func (k *ExtendedKey) DerivePath(path []uint32) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		child, err := key.Derive(index)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+
+	return key, nil
+}